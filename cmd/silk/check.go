@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/matryer/silk/runner"
+)
+
+func init() {
+	commands["check"] = command{
+		run:   checkCmd,
+		usage: "check silk files for problems without running them",
+	}
+}
+
+func checkCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk check [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	files, err := findFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	errs := runner.New(&cliT{log: stderr}, "").Check(files...)
+	for _, err := range errs {
+		fmt.Fprintln(stdout, err)
+	}
+	if len(errs) > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestCheckCmd(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	is.Equal(run([]string{"check", "../../testfiles/success/datafile.silk.md"}, &stdout, &stderr), exitOK)
+	is.Equal(stdout.String(), "")
+
+	is.Equal(run([]string{"check", "../../testfiles/check/check.silk.md"}, &stdout, &stderr), exitFailed)
+	is.True(strings.Contains(stdout.String(), "../../testfiles/check/check.silk.md:8: unknown directive Repaet (did you mean Repeat?)\n"))
+
+	is.Equal(run([]string{"check", "-nope"}, &stdout, &stderr), exitUsage)
+	is.True(strings.Contains(stderr.String(), "usage: silk check"))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/matryer/silk/parse"
+)
+
+func init() {
+	commands["fmt"] = command{
+		run:   fmtCmd,
+		usage: "format silk files",
+	}
+}
+
+func fmtCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to (source) file instead of stdout")
+	list := flags.Bool("l", false, "list files whose formatting differs")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk fmt [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "With no files, silk fmt formats stdin.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitFailed
+		}
+		formatted, err := parse.Format(src)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk: <stdin>:", err)
+			return exitFailed
+		}
+		stdout.Write(formatted)
+		return exitOK
+	}
+	files, err := findFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	code := exitOK
+	for _, file := range files {
+		if err := formatFile(stdout, file, *write, *list); err != nil {
+			fmt.Fprintln(stderr, "silk:", file+":", err)
+			code = exitFailed
+		}
+	}
+	return code
+}
+
+func formatFile(stdout io.Writer, file string, write, list bool) error {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	formatted, err := parse.Format(src)
+	if err != nil {
+		return err
+	}
+	changed := !bytes.Equal(src, formatted)
+	if list && changed {
+		fmt.Fprintln(stdout, file)
+	}
+	if write {
+		if !changed {
+			return nil
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, formatted, info.Mode())
+	}
+	if !list {
+		stdout.Write(formatted)
+	}
+	return nil
+}
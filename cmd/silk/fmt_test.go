@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestFmtCmd(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "comments.silk.md")
+	is.NoErr(ioutil.WriteFile(file, []byte("#   Comments\n## GET    /comments\n===\n*   Status:  200\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	is.Equal(run([]string{"fmt", "-l", dir}, &stdout, &stderr), exitOK)
+	is.Equal(stdout.String(), file+"\n")
+
+	stdout.Reset()
+	is.Equal(run([]string{"fmt", "-w", file}, &stdout, &stderr), exitOK)
+	is.Equal(stdout.String(), "")
+	src, err := ioutil.ReadFile(file)
+	is.NoErr(err)
+	is.Equal(string(src), "# Comments\n\n## GET /comments\n\n===\n\n* Status: 200\n")
+
+	is.Equal(run([]string{"fmt", "-l", dir}, &stdout, &stderr), exitOK)
+	is.Equal(stdout.String(), "")
+
+	is.Equal(run([]string{"fmt", "-nope"}, &stdout, &stderr), exitUsage)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/matryer/silk/config"
+	"github.com/matryer/silk/fuzz"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func init() {
+	commands["fuzz"] = command{
+		run:   fuzzCmd,
+		usage: "check that mutated requests never get server errors",
+	}
+}
+
+func fuzzCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fuzz", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	url := flags.String("url", "", "target url (required unless it is in the config file)")
+	configFile := flags.String("config", "", "config file (default "+config.DefaultFilename+" if it exists)")
+	envName := flags.String("env", "", "environment in the config file to run against")
+	tags := flags.String("tags", "", "only run requests with these tags (e.g. \"smoke !slow\")")
+	rateLimit := flags.Float64("rate-limit", 0, "most requests to make per second (0 for no limit)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk fuzz -url=http://localhost:8080 [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "By default silk will fuzz the files in the config file, or ./*.silk.md")
+		fmt.Fprintln(stderr, "Fuzzing changes data, so only fuzz disposable environments.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	configured, _, patterns, err := configure(*configFile, *envName, url, flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	if len(*url) == 0 {
+		fmt.Fprintln(stderr, "must provide -url")
+		flags.Usage()
+		return exitUsage
+	}
+	files, err := findFiles(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	newRunner := func(t runner.T) *runner.Runner {
+		r := runner.New(t, *url)
+		r.Header = configured.Header
+		for k, v := range configured.Vars {
+			r.Vars[k] = v
+		}
+		r.RoundTripper = configured.RoundTripper
+		r.Secrets = configured.Secrets
+		r.Databases = configured.Databases
+		r.Proxy = configured.Proxy
+		r.ConnectTo = configured.ConnectTo
+		r.Tags = *tags
+		r.RateLimit = configured.RateLimit
+		r.Timeout = configured.Timeout
+		if *rateLimit > 0 {
+			r.RateLimit = *rateLimit
+		}
+		return r
+	}
+	// stop early (and still report) on Ctrl+C
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+	fmt.Fprintln(stdout, "fuzzing", len(files), "file(s)")
+	report := fuzz.Run(ctx, newRunner, groups...)
+	if err := report.Write(stdout); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	if len(report.Failures) > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/matryer/silk/export/gotest"
+	"github.com/matryer/silk/parse"
+)
+
+func init() {
+	commands["gen"] = command{
+		run:   genCmd,
+		usage: "generate Go tests from silk files",
+	}
+}
+
+func genCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pkg := flags.String("package", "main_test", "package name of the generated file")
+	out := flags.String("o", "", "file to write to (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk gen [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Every file is generated into a single Go file.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	files, err := findFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	w := stdout
+	if len(*out) > 0 {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitFailed
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := gotest.Generate(w, *pkg, groups...); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/convert/har"
+	"github.com/matryer/silk/convert/openapi"
+	"github.com/matryer/silk/convert/postman"
+	"github.com/matryer/silk/write"
+)
+
+// converters are the formats that can be imported, keyed by name.
+var converters = map[string]func(io.Reader) ([]*write.Group, error){
+	"har":     har.Convert,
+	"openapi": openapi.Convert,
+	"postman": postman.Convert,
+}
+
+func init() {
+	commands["import"] = command{
+		run:   importCmd,
+		usage: "convert other formats into silk files",
+	}
+}
+
+func importCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	out := flags.String("o", "", "file to write to (default stdout)")
+	flags.Usage = func() {
+		var formats []string
+		for format := range converters {
+			formats = append(formats, format)
+		}
+		sort.Strings(formats)
+		fmt.Fprintln(stderr, "usage: silk import [flags] format file")
+		fmt.Fprintln(stderr, "formats:", strings.Join(formats, ", "))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitUsage
+	}
+	convert, ok := converters[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintln(stderr, "silk: unknown format:", flags.Arg(0))
+		flags.Usage()
+		return exitUsage
+	}
+	in, err := os.Open(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	defer in.Close()
+	groups, err := convert(in)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", flags.Arg(1)+":", err)
+		return exitFailed
+	}
+	w := stdout
+	if len(*out) > 0 {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitFailed
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write.Write(w, groups...); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestImportCmd(t *testing.T) {
+	is := is.New(t)
+	for format, file := range map[string]string{
+		"har":     "../../testfiles/convert/session.har",
+		"openapi": "../../testfiles/convert/openapi.yaml",
+		"postman": "../../testfiles/convert/collection.postman.json",
+	} {
+		var stdout, stderr bytes.Buffer
+		is.Equal(run([]string{"import", format, file}, &stdout, &stderr), exitOK)
+		is.True(strings.HasPrefix(stdout.String(), "# "))
+		is.True(strings.Contains(stdout.String(), "\n## "))
+	}
+}
+
+func TestImportCmdErrors(t *testing.T) {
+	is := is.New(t)
+	for _, test := range []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{args: []string{"import"}, code: exitUsage, stderr: "usage: silk import"},
+		{args: []string{"import", "nope", "file"}, code: exitUsage, stderr: "silk: unknown format: nope"},
+		{args: []string{"import", "har", "missing.har"}, code: exitFailed, stderr: "missing.har"},
+		{args: []string{"import", "postman", "../../testfiles/convert/openapi.yaml"}, code: exitFailed, stderr: "openapi.yaml:"},
+	} {
+		var stdout, stderr bytes.Buffer
+		is.Equal(run(test.args, &stdout, &stderr), test.code)
+		is.True(strings.Contains(stderr.String(), test.stderr))
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+func init() {
+	commands["init"] = command{
+		run:   initCmd,
+		usage: "create a starter silk project",
+	}
+}
+
+// scaffold are the files created by silk init.
+var scaffold = map[string]string{
+	"example.silk.md": `# Example
+
+Requests are made up of a heading, headers and an optional body.
+
+## GET /hello
+
+* Accept: "text/plain"
+* ?name=Silk
+
+===
+
+Following the === separator are the assertions about the response.
+
+* Status: 200
+`,
+	"silk_test.go": `package {{.Package}}_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/silk/config"
+)
+
+// TestSilk runs the silk files in silk.yaml against the url it
+// configures. Set SILK_ENV to run against one of its environments.
+// To test a handler without a running server, start an
+// httptest.Server and give its URL to runner.New instead.
+func TestSilk(t *testing.T) {
+	cfg, err := config.Load("silk.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := cfg.Runner(t, os.Getenv("SILK_ENV"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pattern := range cfg.FilePatterns() {
+		r.RunGlob(filepath.Glob(pattern))
+	}
+}
+`,
+	"silk.yaml": `# Silk configuration.
+
+# url is the root URL requests are made to.
+url: http://localhost:8080
+
+# files are the silk files to run.
+files: "*.silk.md"
+
+# headers are sent with every request.
+# headers:
+#   Accept: application/json
+
+# vars are variables available to every request.
+# vars:
+#   userID: 1
+
+# secrets are the values of ${secret:name} references, from
+# env, dir, vault or aws.
+# secrets:
+#   provider: env
+#   prefix: SILK_SECRET_
+
+# environments override the settings above, and are selected
+# with silk run -env=staging
+# environments:
+#   staging:
+#     url: https://staging.example.com
+#     tls:
+#       ca_file: staging-ca.pem
+`,
+}
+
+var nonIdentRegex = regexp.MustCompile(`[^a-z0-9_]`)
+
+func initCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pkg := flags.String("package", "", "package name for the Go test (default: directory name)")
+	force := flags.Bool("f", false, "overwrite existing files")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk init [flags] [directory]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	if *pkg == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitFailed
+		}
+		*pkg = nonIdentRegex.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "_")
+		if *pkg == "" || (*pkg)[0] >= '0' && (*pkg)[0] <= '9' {
+			*pkg = "silk" + *pkg
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	var names []string
+	for name := range scaffold {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !*force {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				fmt.Fprintln(stderr, "silk:", filepath.Join(dir, name), "already exists (use -f to overwrite)")
+				return exitFailed
+			}
+		}
+	}
+	data := struct{ Package string }{Package: *pkg}
+	for _, name := range names {
+		if err := writeTemplate(filepath.Join(dir, name), scaffold[name], data); err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitFailed
+		}
+		fmt.Fprintln(stdout, "created", filepath.Join(dir, name))
+	}
+	return exitOK
+}
+
+func writeTemplate(filename, src string, data interface{}) error {
+	tpl, err := template.New(filename).Parse(src)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := tpl.Execute(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/testutil"
+)
+
+// TestInitCmd checks that the scaffolded test compiles, and runs
+// the example against a server.
+func TestInitCmd(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of the scaffolding in short mode")
+	}
+	is := is.New(t)
+	is.NoErr(os.MkdirAll("testdata", 0755))
+	defer os.Remove("testdata")
+	dir, err := ioutil.TempDir("testdata", "init")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	var stdout, stderr bytes.Buffer
+	is.Equal(run([]string{"init", "-package=example", dir}, &stdout, &stderr), exitOK)
+	is.True(strings.Contains(stdout.String(), "created "+filepath.Join(dir, "silk_test.go")))
+	is.Equal(run([]string{"init", dir}, &stdout, &stderr), exitFailed)
+	is.True(strings.Contains(stderr.String(), "already exists"))
+
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	config, err := ioutil.ReadFile(filepath.Join(dir, "silk.yaml"))
+	is.NoErr(err)
+	config = bytes.Replace(config, []byte("http://localhost:8080"), []byte(s.URL), 1)
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "silk.yaml"), config, 0644))
+	out, err := exec.Command("go", "test", "-v", "./"+dir).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+	is.True(strings.Contains(string(out), "--- PASS: TestSilk"))
+}
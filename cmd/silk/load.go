@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/matryer/silk/config"
+	"github.com/matryer/silk/load"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func init() {
+	commands["load"] = command{
+		run:   loadCmd,
+		usage: "load test a URL with the requests in silk files",
+	}
+}
+
+func loadCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("load", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	url := flags.String("url", "", "target url (required unless it is in the config file)")
+	configFile := flags.String("config", "", "config file (default "+config.DefaultFilename+" if it exists)")
+	envName := flags.String("env", "", "environment in the config file to run against")
+	tags := flags.String("tags", "", "only run requests with these tags (e.g. \"smoke !slow\")")
+	concurrency := flags.Int("c", 0, "number of workers making requests at the same time (default the config file's concurrency, or 1)")
+	iterations := flags.Int("n", 1, "number of times each worker runs the files")
+	duration := flags.Duration("d", 0, "stop after this long, even if the iterations aren't finished (e.g. 30s)")
+	rateLimit := flags.Float64("rate-limit", 0, "most requests each worker makes per second (0 for no limit)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk load -url=http://localhost:8080 [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "By default silk will load test with the files in the config file, or ./*.silk.md")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	configured, cfg, patterns, err := configure(*configFile, *envName, url, flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	if len(*url) == 0 {
+		fmt.Fprintln(stderr, "must provide -url")
+		flags.Usage()
+		return exitUsage
+	}
+	files, err := findFiles(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	newRunner := func(t runner.T) *runner.Runner {
+		r := runner.New(t, *url)
+		r.Header = configured.Header
+		for k, v := range configured.Vars {
+			r.Vars[k] = v
+		}
+		r.RoundTripper = configured.RoundTripper
+		r.Secrets = configured.Secrets
+		r.Databases = configured.Databases
+		r.Proxy = configured.Proxy
+		r.ConnectTo = configured.ConnectTo
+		r.Tags = *tags
+		r.RateLimit = configured.RateLimit
+		r.Timeout = configured.Timeout
+		if *rateLimit > 0 {
+			r.RateLimit = *rateLimit
+		}
+		return r
+	}
+	// stop early (and still report) on Ctrl+C
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+	if *concurrency == 0 {
+		*concurrency = cfg.Concurrency
+	}
+	if *concurrency == 0 {
+		*concurrency = 1
+	}
+	opts := load.Options{
+		Concurrency: *concurrency,
+		Iterations:  *iterations,
+		Duration:    *duration,
+	}
+	fmt.Fprintln(stdout, "load testing", len(files), "file(s) with", opts.Concurrency, "worker(s)")
+	report := load.Run(ctx, newRunner, opts, groups...)
+	if err := report.Write(stdout); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	return exitOK
+}
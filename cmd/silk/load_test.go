@@ -0,0 +1,38 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/testutil"
+)
+
+// TestLoadCmd runs silk load as a built binary, outside of go test.
+func TestLoadCmd(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building silk in short mode")
+	}
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	bin := filepath.Join(dir, "silk")
+	out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	out, err = exec.Command(bin, "load", "-url="+s.URL, "-c=2", "-n=2", "../../testfiles/success/bodysize.silk.md").CombinedOutput()
+	if err != nil {
+		t.Fatalf("silk load: %v\n%s", err, out)
+	}
+	is.True(strings.Contains(string(out), "GET /echo (../../testfiles/success/bodysize.silk.md:3)"))
+	is.True(strings.Contains(string(out), "4 request(s) in "))
+}
@@ -0,0 +1,70 @@
+// Command silk runs Silk tests outside of go test.
+//
+//	silk run -url=http://localhost:8080 ./testfiles/*.silk.md
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a silk subcommand. It is called with the arguments
+// following the command name and where to write its output, and
+// returns the exit code.
+type command struct {
+	run   func(args []string, stdout, stderr io.Writer) int
+	usage string
+}
+
+var commands = map[string]command{}
+
+// Exit codes.
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+	// exitParse is for silk run when the files can't be parsed.
+	exitParse = 3
+	// exitUnreachable is for silk run when requests fail because
+	// the server can't be reached, and no assertions fail, so CI
+	// can tell an outage from a broken contract.
+	exitUnreachable = 4
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first argument, and gets the
+// exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 1 {
+		printusage(stderr)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+			fmt.Fprintln(stderr, "silk: unknown command:", args[0])
+		}
+		printusage(stderr)
+		return exitUsage
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func printusage(stderr io.Writer) {
+	fmt.Fprintln(stderr, "usage: silk <command> [arguments]")
+	fmt.Fprintln(stderr)
+	fmt.Fprintln(stderr, "commands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/matryer/silk/mock"
+	"github.com/matryer/silk/parse"
+)
+
+func init() {
+	commands["mock"] = command{
+		run:   mockCmd,
+		usage: "serve the responses described in silk files",
+	}
+}
+
+func mockCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("mock", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	listen := flags.String("listen", ":8080", "address to listen on")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk mock [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "By default silk will serve ./*.silk.md")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	files, err := findFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	handler := mock.New(groups...)
+	handler.Log = func(s string) {
+		fmt.Fprint(stdout, s)
+	}
+	fmt.Fprintln(stdout, "serving", len(files), "file(s) on", *listen)
+	if err := http.ListenAndServe(*listen, handler); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestMockCmd(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	is.Equal(run([]string{"mock", "-nope"}, &stdout, &stderr), exitUsage)
+	is.True(strings.Contains(stderr.String(), "usage: silk mock"))
+
+	stderr.Reset()
+	is.Equal(run([]string{"mock", "../../testfiles/check/malformed.silk.md"}, &stdout, &stderr), exitFailed)
+	is.True(strings.Contains(stderr.String(), "missing group header"))
+
+	stderr.Reset()
+	is.Equal(run([]string{"mock", "-listen=not an address", "../../testfiles/mock/mock.silk.md"}, &stdout, &stderr), exitFailed)
+	is.Equal(stdout.String(), "serving 1 file(s) on not an address\n")
+	is.True(strings.HasPrefix(stderr.String(), "silk: "))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+
+	"github.com/matryer/silk/record"
+	"github.com/matryer/silk/write"
+)
+
+func init() {
+	commands["record"] = command{
+		run:   recordCmd,
+		usage: "record traffic to a service as a silk file",
+	}
+}
+
+func recordCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("record", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	target := flags.String("target", "", "(required) url of the service to record")
+	listen := flags.String("listen", ":8081", "address for the recording proxy to listen on")
+	out := flags.String("o", "recorded.silk.md", "file to write the recording to")
+	title := flags.String("title", "Recorded", "title of the recorded group")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk record -target=http://localhost:8080 [flags]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Send requests to the proxy, then press Ctrl+C to write the silk file.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*target) == 0 {
+		fmt.Fprintln(stderr, "must provide -target")
+		flags.Usage()
+		return exitUsage
+	}
+	targetURL, err := url.Parse(*target)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	recorder := record.New(nil)
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.Transport = recorder
+	errs := make(chan error, 1)
+	go func() {
+		errs <- http.ListenAndServe(*listen, proxy)
+	}()
+	fmt.Fprintln(stdout, "recording", *target, "on", *listen, "(press Ctrl+C to stop)")
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	select {
+	case err := <-errs:
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	case <-interrupt:
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	defer f.Close()
+	if err := write.Write(f, recorder.Group(*title)); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitFailed
+	}
+	fmt.Fprintln(stdout, "recorded", len(recorder.Requests()), "request(s) to", *out)
+	return exitOK
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/config"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func init() {
+	commands["run"] = command{
+		run:   runCmd,
+		usage: "run silk files against a URL",
+	}
+}
+
+func runCmd(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	url := flags.String("url", "", "target url (required unless it is in the config file)")
+	configFile := flags.String("config", "", "config file (default "+config.DefaultFilename+" if it exists)")
+	envName := flags.String("env", "", "environment in the config file to run against")
+	varsFile := flags.String("vars", "", "JSON or YAML file of variables available to every request")
+	tags := flags.String("tags", "", "only run requests with these tags (e.g. \"smoke !slow\")")
+	runPattern := flags.String("run", "", "only run groups and requests (like \"GET /users\") matching this regexp")
+	verbose := flags.Bool("v", false, "verbose output")
+	step := flags.Bool("step", false, "show each request and ask whether to run it, skip it, or abort")
+	dryRun := flags.Bool("dry-run", false, "print the requests without making them")
+	requestID := flags.String("request-id", "", "send a new request ID in this header (like X-Request-ID) with every request")
+	trace := flags.Bool("trace", false, "start a trace for every request, with a traceparent header")
+	escapePath := flags.Bool("escape-path", false, "escape {name} values in request paths")
+	printCurl := flags.Bool("print-curl", false, "print the curl command for each request")
+	proxy := flags.String("proxy", "", "proxy URL to make requests through (e.g. socks5://localhost:1080), or \"direct\"")
+	connectTo := flags.String("connect-to", "", "address (IP:port) to connect to instead of the host of the url")
+	redact := flags.String("redact", "", "comma separated names of headers and JSON fields to redact from the output (e.g. \"Authorization,password\")")
+	rateLimit := flags.Float64("rate-limit", 0, "most requests to make per second (0 for no limit)")
+	timeout := flags.Duration("timeout", 0, "how long each request can take (e.g. 30s, 0 for the config file's, or no timeout)")
+	session := flags.String("session", "group", "share captured values and cookies between the groups in each file (file) or in every file (run)")
+	shuffle := flags.Bool("shuffle", false, "run the groups in a random order (from the seed)")
+	seed := flags.Int64("seed", 0, "seed of random values like uuid and fake, to reproduce a run (0 for a new seed)")
+	onFailure := flags.String("on-failure", "run-all", "what to do when a request fails: fail-fast, finish-group or run-all")
+	exactBody := flags.Bool("exact-body", false, "compare bodies byte for byte, whatever their Content-Type")
+	htmlReport := flags.String("html", "", "write an HTML report to this file")
+	harReport := flags.String("har", "", "write the requests and responses to this HAR file")
+	metricsFile := flags.String("metrics", "", "write Prometheus metrics to this file (for the node_exporter textfile collector)")
+	pushgateway := flags.String("pushgateway", "", "push Prometheus metrics to the Pushgateway at this URL")
+	metricsJob := flags.String("metrics.job", "silk", "job of the metrics pushed to the Pushgateway")
+	github := flags.Bool("github", false, "write GitHub Actions annotations for failures")
+	webhook := flags.String("webhook", "", "post the summary of each run, and the failures, as JSON to this URL")
+	slack := flags.String("slack", "", "post the summary of each run, and the failures, to this Slack incoming webhook URL")
+	notify := flags.String("notify", "always", "when to post to the webhooks: always or failure")
+	transcriptFile := flags.String("transcript", "", "write every request and response to this file")
+	count := flags.Int("count", 1, "run the files this many times")
+	untilFailure := flags.Bool("until-failure", false, "run the files again and again until they fail")
+	watch := flags.Bool("watch", false, "re-run files when they change")
+	watchDir := flags.String("watch.dir", "", "also re-run all files when anything in this directory changes")
+	watchInterval := flags.Duration("watch.interval", 500*time.Millisecond, "how often to check for changes")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: silk run -url=http://localhost:8080 [flags] [path/to/files/[pattern]...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "By default silk will run the files in the config file, or ./*.silk.md")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	configured, cfg, patterns, err := configure(*configFile, *envName, url, flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	reports := cfg.ReportFiles()
+	for _, report := range []struct {
+		flag *string
+		file string
+	}{
+		{htmlReport, reports.HTML},
+		{harReport, reports.HAR},
+		{metricsFile, reports.Metrics},
+		{transcriptFile, reports.Transcript},
+	} {
+		if len(*report.flag) == 0 {
+			*report.flag = report.file
+		}
+	}
+	*github = *github || reports.GitHub
+	if len(*url) == 0 {
+		fmt.Fprintln(stderr, "must provide -url")
+		flags.Usage()
+		return exitUsage
+	}
+	files, err := findFiles(patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	if *count < 1 {
+		fmt.Fprintln(stderr, "silk: -count must be at least 1")
+		return exitUsage
+	}
+	sessionScope, err := runner.ParseSession(*session)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	policy, err := runner.ParsePolicy(*onFailure)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitUsage
+	}
+	if len(*varsFile) > 0 {
+		vars, err := config.LoadVars(*varsFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitUsage
+		}
+		for k, v := range vars {
+			configured.Vars[k] = v
+		}
+	}
+	var transcript io.Writer
+	if len(*transcriptFile) > 0 {
+		f, err := os.Create(*transcriptFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitUsage
+		}
+		defer f.Close()
+		transcript = f
+	}
+	var html *runner.HTMLReporter
+	var archive *runner.HARReporter
+	var metrics *runner.MetricsReporter
+	if *notify != "always" && *notify != "failure" {
+		fmt.Fprintln(stderr, "silk: unknown -notify", *notify, "(expected always or failure)")
+		return exitUsage
+	}
+	var webhooks []*runner.WebhookReporter
+	if len(*webhook) > 0 {
+		webhooks = append(webhooks, runner.NewWebhookReporter(*webhook))
+	}
+	if len(*slack) > 0 {
+		webhooks = append(webhooks, runner.NewSlackReporter(*slack))
+	}
+	for _, w := range webhooks {
+		w.OnlyFailures = *notify == "failure"
+	}
+	if len(*metricsFile) > 0 || len(*pushgateway) > 0 {
+		// counted across every run
+		metrics = runner.NewMetricsReporter()
+	}
+	newRunner := func(t runner.T) *runner.Runner {
+		r := runner.New(t, *url)
+		r.Log = func(s string) {
+			fmt.Fprintln(stdout, s)
+		}
+		r.Header = configured.Header
+		for k, v := range configured.Vars {
+			r.Vars[k] = v
+		}
+		r.RoundTripper = configured.RoundTripper
+		r.Secrets = configured.Secrets
+		r.Databases = configured.Databases
+		r.Proxy = configured.Proxy
+		if len(*proxy) > 0 {
+			r.Proxy = *proxy
+		}
+		r.ConnectTo = configured.ConnectTo
+		if len(*connectTo) > 0 {
+			r.ConnectTo = *connectTo
+		}
+		r.Redact = append([]string(nil), configured.Redact...)
+		for _, name := range strings.Split(*redact, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				r.Redact = append(r.Redact, name)
+			}
+		}
+		r.Tags = *tags
+		r.Run = *runPattern
+		r.ExactBody = *exactBody
+		r.Session = sessionScope
+		r.Seed = *seed
+		r.Shuffle = *shuffle
+		r.OnFailure = policy
+		r.RateLimit = configured.RateLimit
+		if *rateLimit > 0 {
+			r.RateLimit = *rateLimit
+		}
+		r.Timeout = configured.Timeout
+		if *timeout > 0 {
+			r.Timeout = *timeout
+		}
+		r.EscapePathVars = *escapePath
+		r.RequestIDHeader = *requestID
+		r.Trace = *trace
+		r.PrintCurl = *printCurl
+		r.DryRun = *dryRun
+		if *step {
+			r.Step = stepper(stdin, stdout)
+		}
+		if len(*htmlReport) > 0 {
+			html = runner.NewHTMLReporter()
+			r.Reporter = runner.MultiReporter(r.Reporter, html)
+		}
+		if len(*harReport) > 0 {
+			archive = runner.NewHARReporter()
+			r.Reporter = runner.MultiReporter(r.Reporter, archive)
+		}
+		if metrics != nil {
+			r.Reporter = runner.MultiReporter(r.Reporter, metrics)
+		}
+		for _, w := range webhooks {
+			r.Reporter = runner.MultiReporter(r.Reporter, w)
+		}
+		if *github {
+			r.Reporter = runner.MultiReporter(r.Reporter, runner.NewGitHubReporter(stdout))
+		}
+		r.Transcript = transcript
+		r.Verbose = func(args ...interface{}) {
+			if *verbose {
+				fmt.Fprintln(stdout, args...)
+			}
+		}
+		return r
+	}
+	if *watch {
+		w := &watcher{
+			patterns: patterns,
+			dir:      *watchDir,
+			interval: *watchInterval,
+			stdout:   stdout,
+			run: func(files []string) bool {
+				passed := runFiles(stdout, stderr, newRunner, files) == exitOK
+				writeReport(stdout, stderr, html, *htmlReport)
+				writeReport(stdout, stderr, archive, *harReport)
+				writeMetrics(stdout, stderr, metrics, *metricsFile, *pushgateway, *metricsJob)
+				webhookErrors(stderr, webhooks)
+				return passed
+			},
+		}
+		if err := w.watch(); err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitUsage
+		}
+		return exitOK
+	}
+	code := runCount(stdout, stderr, newRunner, files, *count, *untilFailure)
+	writeReport(stdout, stderr, html, *htmlReport)
+	writeReport(stdout, stderr, archive, *harReport)
+	writeMetrics(stdout, stderr, metrics, *metricsFile, *pushgateway, *metricsJob)
+	webhookErrors(stderr, webhooks)
+	return code
+}
+
+// writeReport writes the report to the file, if there is one.
+func writeReport(stdout, stderr io.Writer, report interface {
+	WriteFile(filename string) error
+}, filename string) {
+	if filename == "" {
+		return
+	}
+	if err := report.WriteFile(filename); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return
+	}
+	fmt.Fprintln(stdout, "wrote report to", filename)
+}
+
+// writeMetrics writes the metrics to the file, and pushes them to
+// the Pushgateway, if either is set.
+func writeMetrics(stdout, stderr io.Writer, metrics *runner.MetricsReporter, filename, gateway, job string) {
+	writeReport(stdout, stderr, metrics, filename)
+	if gateway == "" {
+		return
+	}
+	if err := metrics.Push(gateway, job); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return
+	}
+	fmt.Fprintln(stdout, "pushed metrics to", gateway)
+}
+
+// webhookErrors prints the errors of the webhooks that couldn't
+// be posted to.
+func webhookErrors(stderr io.Writer, webhooks []*runner.WebhookReporter) {
+	for _, w := range webhooks {
+		if err := w.Err(); err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+		}
+	}
+}
+
+// runCount runs the files count times, or until they fail if
+// untilFailure is set, to chase flaky failures. Gets the exit code:
+// exitFailed if an assertion failed in any run, or else the code
+// of the runs that failed.
+func runCount(stdout, stderr io.Writer, newRunner func(runner.T) *runner.Runner, files []string, count int, untilFailure bool) int {
+	if count == 1 && !untilFailure {
+		return runFiles(stdout, stderr, newRunner, files)
+	}
+	failures := 0
+	code := exitOK
+	i := 1
+	for ; untilFailure || i <= count; i++ {
+		fmt.Fprintln(stdout, "=== run", i)
+		c := runFiles(stdout, stderr, newRunner, files)
+		if c == exitParse {
+			// every run would fail the same way
+			return c
+		}
+		if c != exitOK {
+			failures++
+			if code != exitFailed {
+				code = c
+			}
+			if untilFailure {
+				break
+			}
+		}
+	}
+	if untilFailure {
+		fmt.Fprintln(stdout, "failed on run", i)
+		return code
+	}
+	fmt.Fprintf(stdout, "%d of %d run(s) failed\n", failures, count)
+	return code
+}
+
+// runFiles runs the files with a new Runner, and gets the exit
+// code: exitOK if they passed, exitParse if they can't be parsed,
+// exitUnreachable if the only failures are requests that couldn't
+// reach the server, or else exitFailed.
+func runFiles(stdout, stderr io.Writer, newRunner func(runner.T) *runner.Runner, files []string) int {
+	t := &cliT{log: stderr, stop: true}
+	r := newRunner(t)
+	fmt.Fprintln(stdout, "running", len(files), "file(s)")
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		fmt.Fprintln(stdout, "FAIL")
+		return exitParse
+	}
+	// like go test, run in a goroutine that failures can stop
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.RunGroup(groups...)
+	}()
+	<-done
+	if t.failed {
+		fmt.Fprintln(stdout, "FAIL")
+		return failureCode(r.Summary())
+	}
+	fmt.Fprintln(stdout, "PASS")
+	return exitOK
+}
+
+// failureCode gets the exit code of a run that failed:
+// exitUnreachable if every failure is a request that couldn't reach
+// the server, or else exitFailed.
+func failureCode(summary *runner.Summary) int {
+	unreachable := false
+	for _, result := range summary.Results {
+		if result.Outcome != runner.Failed {
+			continue
+		}
+		if !result.Unreachable {
+			return exitFailed
+		}
+		unreachable = true
+	}
+	if unreachable {
+		return exitUnreachable
+	}
+	return exitFailed
+}
+
+// configure loads the config file (if there is one) into a Runner
+// to copy settings from, and gets the config for the settings that
+// aren't the Runner's, which is empty if there is no config file.
+// The url and patterns are filled in from the environment if they
+// are empty.
+func configure(configFile, envName string, url *string, patterns []string) (*runner.Runner, *config.Config, []string, error) {
+	configured := runner.New(nil, *url)
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cfg == nil && envName != "" {
+		return nil, nil, nil, errors.New("-env needs a config file")
+	}
+	if cfg == nil {
+		return configured, &config.Config{}, patterns, nil
+	}
+	env, err := cfg.Env(envName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(*url) == 0 {
+		*url = strings.TrimSuffix(env.URL, "/")
+	}
+	if err := cfg.Apply(configured, env); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(patterns) == 0 {
+		patterns = cfg.FilePatterns()
+	}
+	return configured, cfg, patterns, nil
+}
+
+// loadConfig loads the config file, or the default one if
+// filename is empty. Gets nil if there is no default config file.
+func loadConfig(filename string) (*config.Config, error) {
+	if filename == "" {
+		if _, err := os.Stat(config.DefaultFilename); os.IsNotExist(err) {
+			return nil, nil
+		}
+		filename = config.DefaultFilename
+	}
+	return config.Load(filename)
+}
+
+// findFiles gets the silk files matching the patterns.
+// Directories match the *.silk.md files inside them, and no
+// patterns matches ./*.silk.md.
+func findFiles(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	var files []string
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			pattern = filepath.Join(pattern, "*.silk.md")
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// cliT is a runner.T that records failures.
+type cliT struct {
+	failed bool
+	// log is where messages are written.
+	log io.Writer
+	// stop makes FailNow stop the goroutine, like testing.T does,
+	// so the Runner stops at the first failure unless its policy
+	// says otherwise.
+	stop bool
+}
+
+func (t *cliT) FailNow() {
+	t.failed = true
+	if t.stop {
+		runtime.Goexit()
+	}
+}
+
+func (t *cliT) Log(args ...interface{}) {
+	fmt.Fprintln(t.log, args...)
+}
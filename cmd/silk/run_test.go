@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestRunCmd(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-url=" + s.URL, "../../testfiles/success/bodysize.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitOK)
+	is.True(strings.Contains(stdout.String(), "running 1 file(s)"))
+	is.True(strings.Contains(stdout.String(), "PASS"))
+
+	stdout.Reset()
+	code = run([]string{"run", "-url=" + s.URL, "../../testfiles/failure/echo.failure.bodysize.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	is.True(strings.Contains(stdout.String(), "FAIL"))
+}
+
+func TestRunCmdExitCodes(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-url=" + s.URL, "../../testfiles/check/malformed.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitParse)
+	is.True(strings.Contains(stderr.String(), "silk: "))
+
+	// nothing is listening once the server is closed
+	s.Close()
+	stdout.Reset()
+	code = run([]string{"run", "-url=" + s.URL, "../../testfiles/success/bodysize.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitUnreachable)
+	is.True(strings.Contains(stdout.String(), "FAIL"))
+
+	stdout.Reset()
+	code = run([]string{"run", "-count=2", "-url=" + s.URL, "../../testfiles/check/malformed.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitParse)
+}
+
+func TestRunCmdUsage(t *testing.T) {
+	is := is.New(t)
+	for _, test := range []struct {
+		args   []string
+		stderr string
+	}{
+		{args: nil, stderr: "usage: silk <command>"},
+		{args: []string{"nope"}, stderr: "silk: unknown command: nope"},
+		{args: []string{"run"}, stderr: "must provide -url"},
+		{args: []string{"run", "-nope"}, stderr: "flag provided but not defined: -nope"},
+		{args: []string{"run", "-url=http://localhost", "-env=staging"}, stderr: "-env needs a config file"},
+		{args: []string{"run", "-url=http://localhost", "-config=missing.yaml"}, stderr: "missing.yaml"},
+	} {
+		var stdout, stderr bytes.Buffer
+		is.Equal(run(test.args, &stdout, &stderr), exitUsage)
+		is.True(strings.Contains(stderr.String(), test.stderr))
+	}
+}
+
+func TestRunCmdStep(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer s.Close()
+	defer func(in io.Reader) { stdin = in }(stdin)
+	// skip the first request, and abort at the second
+	stdin = strings.NewReader("x\ns\na\n")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-step", "-url=" + s.URL, "../../testfiles/dryrun/dryrun.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitOK)
+	is.Equal(len(paths), 0)
+	is.True(strings.Contains(stdout.String(), "POST "+s.URL+"/users\nContent-Type: application/json"))
+	is.True(strings.Contains(stdout.String(), "run, skip or abort? [R/s/a] run, skip or abort? [R/s/a] "))
+	is.True(strings.Contains(stdout.String(), "aborted"))
+
+	paths = nil
+	stdin = strings.NewReader("\n")
+	stdout.Reset()
+	code = run([]string{"run", "-step", "-url=" + s.URL, "../../testfiles/dryrun/dryrun.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	is.Equal(paths, []string{"/users"})
+}
+
+func TestRunCmdOnFailure(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+	for _, test := range []struct {
+		policy string
+		paths  []string
+	}{
+		{policy: "fail-fast", paths: []string{"/fail"}},
+		{policy: "finish-group", paths: []string{"/fail", "/first"}},
+		{policy: "run-all", paths: []string{"/fail", "/first", "/second"}},
+	} {
+		paths = nil
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"run", "-on-failure=" + test.policy, "-url=" + s.URL, "../../testfiles/policy/policy.silk.md"}, &stdout, &stderr)
+		is.Equal(code, exitFailed)
+		is.Equal(paths, test.paths)
+		is.True(strings.Contains(stdout.String(), "FAIL: 1 file(s)"))
+	}
+}
+
+func TestRunCmdCount(t *testing.T) {
+	is := is.New(t)
+	// the request that fails, to be flaky
+	requests, failAt := 0, 5
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-count=4", "-url=" + s.URL, "../../testfiles/policy/policy.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	is.Equal(requests, 12)
+	is.True(strings.Contains(stdout.String(), "=== run 4"))
+	is.True(strings.Contains(stdout.String(), "1 of 4 run(s) failed"))
+
+	requests, failAt = 0, 8
+	stdout.Reset()
+	code = run([]string{"run", "-until-failure", "-url=" + s.URL, "../../testfiles/policy/policy.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	is.Equal(requests, 9)
+	is.True(strings.Contains(stdout.String(), "failed on run 3"))
+
+	stderr.Reset()
+	code = run([]string{"run", "-count=0", "-url=" + s.URL}, &stdout, &stderr)
+	is.Equal(code, exitUsage)
+	is.True(strings.Contains(stderr.String(), "-count must be at least 1"))
+}
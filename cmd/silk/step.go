@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/matryer/silk/runner"
+)
+
+// stdin is where -step reads answers from.
+var stdin io.Reader = os.Stdin
+
+// stepper gets a runner.Runner Step function that prints each
+// request, and asks whether to run it, skip it, or abort the run.
+// The end of the input aborts the run.
+func stepper(in io.Reader, out io.Writer) func(string) runner.StepAction {
+	scanner := bufio.NewScanner(in)
+	return func(request string) runner.StepAction {
+		fmt.Fprintln(out, request)
+		for {
+			fmt.Fprint(out, "run, skip or abort? [R/s/a] ")
+			if !scanner.Scan() {
+				fmt.Fprintln(out)
+				return runner.StepAbort
+			}
+			switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+			case "", "r", "run":
+				return runner.StepRun
+			case "s", "skip":
+				return runner.StepSkip
+			case "a", "abort":
+				return runner.StepAbort
+			}
+		}
+	}
+}
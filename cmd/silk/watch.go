@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watcher re-runs silk files when they change.
+// Changes are detected by polling file modification times.
+type watcher struct {
+	// patterns match the silk files to watch.
+	patterns []string
+	// dir is an optional directory, where any change causes
+	// all files to be run.
+	dir      string
+	interval time.Duration
+	// run runs the files.
+	run func(files []string) bool
+	// stdout is where changes are logged.
+	stdout io.Writer
+}
+
+// watch runs all files, then runs them again as they change.
+// It only returns if there is an error.
+func (w *watcher) watch() error {
+	files, err := findFiles(w.patterns)
+	if err != nil {
+		return err
+	}
+	w.run(files)
+	modtimes := w.modtimes(files)
+	dirtimes := w.dirModtimes()
+	fmt.Fprintln(w.stdout, "watching for changes...")
+	for {
+		time.Sleep(w.interval)
+		files, err := findFiles(w.patterns)
+		if err != nil {
+			return err
+		}
+		latest := w.modtimes(files)
+		latestDir := w.dirModtimes()
+		changed := files
+		if !sameModtimes(dirtimes, latestDir) {
+			fmt.Fprintln(w.stdout, "---", w.dir, "changed")
+		} else {
+			changed = nil
+			for _, file := range files {
+				if previous, ok := modtimes[file]; !ok || !previous.Equal(latest[file]) {
+					fmt.Fprintln(w.stdout, "---", file, "changed")
+					changed = append(changed, file)
+				}
+			}
+		}
+		modtimes, dirtimes = latest, latestDir
+		if len(changed) == 0 {
+			continue
+		}
+		w.run(changed)
+		fmt.Fprintln(w.stdout, "watching for changes...")
+	}
+}
+
+func (w *watcher) modtimes(files []string) map[string]time.Time {
+	modtimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modtimes[file] = info.ModTime()
+		}
+	}
+	return modtimes
+}
+
+func (w *watcher) dirModtimes() map[string]time.Time {
+	modtimes := make(map[string]time.Time)
+	if w.dir == "" {
+		return modtimes
+	}
+	filepath.Walk(w.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		modtimes[path] = info.ModTime()
+		return nil
+	})
+	return modtimes
+}
+
+func sameModtimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, modtime := range a {
+		if other, ok := b[path]; !ok || !other.Equal(modtime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,453 @@
+// Package config loads Silk configuration files (silk.yaml), which
+// describe the environments that tests run against.
+//
+//	url: http://localhost:8080
+//	files: "*.silk.md"
+//	headers:
+//	  Accept: application/json
+//	timeout: 30s
+//	concurrency: 10
+//	reports:
+//	  html: report.html
+//	environments:
+//	  staging:
+//	    url: https://staging.example.com
+//	    timeout: 1m
+//	    vars:
+//	      userID: 123
+//	    vars_file: staging-vars.yaml
+//	    tls:
+//	      ca_file: staging-ca.pem
+//	    secrets:
+//	      provider: vault
+//	    databases:
+//	      main:
+//	        driver: postgres
+//	        dsn: postgres://localhost/staging
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/secrets"
+)
+
+// DefaultFilename is the name of the configuration file that is
+// used when none is specified.
+const DefaultFilename = "silk.yaml"
+
+// Config is a silk configuration.
+// The top level settings are the defaults for every environment.
+type Config struct {
+	Environment `yaml:",inline"`
+	// Files are glob patterns of the silk files to run.
+	Files Patterns `yaml:"files"`
+	// Concurrency is the number of workers silk load uses.
+	Concurrency int `yaml:"concurrency"`
+	// Reports are the reports silk run writes.
+	Reports Reports `yaml:"reports"`
+	// Environments are the named environments.
+	Environments map[string]*Environment `yaml:"environments"`
+
+	// dir is the directory of the config file, which relative
+	// paths are relative to.
+	dir string
+}
+
+// Environment is the settings for an environment that silk files
+// are run against.
+type Environment struct {
+	// URL is the root URL requests are made to.
+	URL string `yaml:"url"`
+	// Headers are sent with every request.
+	Headers map[string]string `yaml:"headers"`
+	// Vars are variables available to every request.
+	Vars map[string]interface{} `yaml:"vars"`
+	// VarsFile is a JSON or YAML file of variables available to
+	// every request (see LoadVars). Vars take precedence.
+	VarsFile string `yaml:"vars_file"`
+	TLS      *TLS   `yaml:"tls"`
+	// Proxy is the URL of the proxy to make requests through, or
+	// "direct". See runner.Runner.Proxy.
+	Proxy string `yaml:"proxy"`
+	// ConnectTo is the address to connect to instead of the host
+	// of the URL. See runner.Runner.ConnectTo.
+	ConnectTo string `yaml:"connect_to"`
+	// Secrets is where ${secret:name} references get their values.
+	Secrets *Secrets `yaml:"secrets"`
+	// Redact are the names of headers and JSON fields whose values
+	// are redacted from the output. Environments add to the
+	// defaults. See runner.Runner.Redact.
+	Redact []string `yaml:"redact"`
+	// RateLimit is the most requests to make per second. See
+	// runner.Runner.RateLimit.
+	RateLimit float64 `yaml:"rate_limit"`
+	// Timeout is how long each request can take, like 30s. See
+	// runner.Runner.Timeout.
+	Timeout Duration `yaml:"timeout"`
+	// Databases are the databases that SQL steps query, by name.
+	// Environments add to (or replace) the defaults.
+	Databases map[string]*Database `yaml:"databases"`
+}
+
+// Reports are the files that silk run writes reports to, relative
+// to the config file. The flags of silk run take precedence.
+type Reports struct {
+	// HTML is the HTML report.
+	HTML string `yaml:"html"`
+	// HAR is the HAR file of the requests and responses.
+	HAR string `yaml:"har"`
+	// Metrics is the Prometheus metrics file.
+	Metrics string `yaml:"metrics"`
+	// Transcript is the transcript of every request and response.
+	Transcript string `yaml:"transcript"`
+	// GitHub writes GitHub Actions annotations for failures.
+	GitHub bool `yaml:"github"`
+}
+
+// Database is a database that SQL steps query. The driver must be
+// imported by the program, since silk doesn't import any.
+type Database struct {
+	// Driver is the name of the database/sql driver, like postgres.
+	Driver string `yaml:"driver"`
+	// DSN is the data source name given to the driver.
+	DSN string `yaml:"dsn"`
+}
+
+// TLS is the TLS configuration of an environment.
+type TLS struct {
+	// InsecureSkipVerify skips verification of server
+	// certificates. Only use it for testing.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+	// CAFile is a PEM file of the certificate authorities to
+	// trust, instead of the system ones.
+	CAFile string `yaml:"ca_file"`
+	// CertFile and KeyFile are a client certificate.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ServerName overrides the server name that is verified.
+	ServerName string `yaml:"server_name"`
+}
+
+// Secrets configures the provider of secrets.
+type Secrets struct {
+	// Provider is one of env, dir, vault or aws.
+	Provider string `yaml:"provider"`
+	// Prefix is the prefix of environment variables (env).
+	Prefix string `yaml:"prefix"`
+	// Dir is the directory of secret files (dir).
+	Dir string `yaml:"dir"`
+	// Address is the address of the Vault server, Mount is where
+	// the KV engine is mounted and KV1 is true for version 1 of
+	// the engine (vault).
+	// The token is taken from the VAULT_TOKEN environment variable.
+	Address string `yaml:"address"`
+	Mount   string `yaml:"mount"`
+	KV1     bool   `yaml:"kv1"`
+	// Region is the AWS region (aws).
+	// Credentials are taken from the AWS environment variables.
+	Region string `yaml:"region"`
+}
+
+// Patterns are glob patterns, which may be given in YAML as a
+// single string, or a list.
+type Patterns []string
+
+// UnmarshalYAML decodes a string or list of strings.
+func (p *Patterns) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*p = Patterns{s}
+		return nil
+	}
+	return unmarshal((*[]string)(p))
+}
+
+// Duration is a duration, given in YAML like 30s or 1m30s.
+type Duration time.Duration
+
+// UnmarshalYAML decodes a duration.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func init() {
+	runner.RegisterConfigLoader(func(t runner.T, env string) (*runner.Runner, error) {
+		c, err := Load(DefaultFilename)
+		if err != nil {
+			return nil, err
+		}
+		return c.Runner(t, env)
+	})
+}
+
+// Load loads the configuration file.
+func Load(filename string) (*Config, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	c.dir = filepath.Dir(filename)
+	return &c, nil
+}
+
+// Env gets the settings of the named environment, on top of the
+// defaults. An empty name gets the defaults.
+func (c *Config) Env(name string) (*Environment, error) {
+	env := &Environment{
+		URL:       c.URL,
+		Headers:   make(map[string]string),
+		Vars:      make(map[string]interface{}),
+		VarsFile:  c.VarsFile,
+		TLS:       c.TLS,
+		Secrets:   c.Secrets,
+		Proxy:     c.Proxy,
+		ConnectTo: c.ConnectTo,
+		Redact:    append([]string(nil), c.Redact...),
+		RateLimit: c.RateLimit,
+		Timeout:   c.Timeout,
+		Databases: make(map[string]*Database),
+	}
+	for k, v := range c.Databases {
+		env.Databases[k] = v
+	}
+	for k, v := range c.Headers {
+		env.Headers[k] = v
+	}
+	for k, v := range c.Vars {
+		env.Vars[k] = v
+	}
+	if name == "" {
+		return env, nil
+	}
+	named, ok := c.Environments[name]
+	if !ok || named == nil {
+		var names []string
+		for name := range c.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown environment %q (environments: %s)", name, strings.Join(names, ", "))
+	}
+	if named.URL != "" {
+		env.URL = named.URL
+	}
+	for k, v := range named.Headers {
+		env.Headers[k] = v
+	}
+	for k, v := range named.Vars {
+		env.Vars[k] = v
+	}
+	if named.VarsFile != "" {
+		env.VarsFile = named.VarsFile
+	}
+	if named.TLS != nil {
+		env.TLS = named.TLS
+	}
+	if named.Secrets != nil {
+		env.Secrets = named.Secrets
+	}
+	if named.Proxy != "" {
+		env.Proxy = named.Proxy
+	}
+	if named.ConnectTo != "" {
+		env.ConnectTo = named.ConnectTo
+	}
+	env.Redact = append(env.Redact, named.Redact...)
+	if named.RateLimit != 0 {
+		env.RateLimit = named.RateLimit
+	}
+	if named.Timeout != 0 {
+		env.Timeout = named.Timeout
+	}
+	for k, v := range named.Databases {
+		env.Databases[k] = v
+	}
+	return env, nil
+}
+
+// FilePatterns gets the Files patterns, relative to the current
+// directory rather than the config file.
+func (c *Config) FilePatterns() []string {
+	var patterns []string
+	for _, pattern := range c.Files {
+		patterns = append(patterns, c.path(pattern))
+	}
+	return patterns
+}
+
+// ReportFiles gets the Reports, with their files relative to the
+// current directory rather than the config file.
+func (c *Config) ReportFiles() Reports {
+	reports := c.Reports
+	for _, file := range []*string{&reports.HTML, &reports.HAR, &reports.Metrics, &reports.Transcript} {
+		if *file != "" {
+			*file = c.path(*file)
+		}
+	}
+	return reports
+}
+
+var errNoURL = errors.New("no url")
+
+// Runner makes a new Runner for the named environment (or the
+// defaults if env is empty).
+func (c *Config) Runner(t runner.T, env string) (*runner.Runner, error) {
+	e, err := c.Env(env)
+	if err != nil {
+		return nil, err
+	}
+	if e.URL == "" {
+		return nil, errNoURL
+	}
+	r := runner.New(t, strings.TrimSuffix(e.URL, "/"))
+	if err := c.Apply(r, e); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Apply applies the headers, variables (including those in the
+// VarsFile), databases, secrets, timeout, connection and TLS
+// settings of the environment to the Runner. The URL is not applied, since it is
+// given to runner.New.
+func (c *Config) Apply(r *runner.Runner, e *Environment) error {
+	if len(e.Headers) > 0 && r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	for k, v := range e.Headers {
+		r.Header.Set(k, v)
+	}
+	if r.Vars == nil {
+		r.Vars = make(map[string]interface{})
+	}
+	if e.VarsFile != "" {
+		vars, err := LoadVars(c.path(e.VarsFile))
+		if err != nil {
+			return err
+		}
+		for k, v := range vars {
+			r.Vars[k] = v
+		}
+	}
+	for k, v := range e.Vars {
+		r.Vars[k] = v
+	}
+	if e.Proxy != "" {
+		r.Proxy = e.Proxy
+	}
+	if e.ConnectTo != "" {
+		r.ConnectTo = e.ConnectTo
+	}
+	r.Redact = append(r.Redact, e.Redact...)
+	if e.RateLimit != 0 {
+		r.RateLimit = e.RateLimit
+	}
+	if e.Timeout != 0 {
+		r.Timeout = time.Duration(e.Timeout)
+	}
+	for name, d := range e.Databases {
+		db, err := sql.Open(d.Driver, d.DSN)
+		if err != nil {
+			return fmt.Errorf("database %s: %v", name, err)
+		}
+		if r.Databases == nil {
+			r.Databases = make(map[string]*sql.DB)
+		}
+		r.Databases[name] = db
+	}
+	if e.Secrets != nil {
+		provider, err := c.secretProvider(e.Secrets)
+		if err != nil {
+			return err
+		}
+		r.Secrets = provider
+	}
+	if e.TLS == nil {
+		return nil
+	}
+	tlsConfig, err := c.tlsConfig(e.TLS)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	r.RoundTripper = transport
+	return nil
+}
+
+func (c *Config) secretProvider(s *Secrets) (runner.SecretProvider, error) {
+	switch s.Provider {
+	case "env":
+		return secrets.Env{Prefix: s.Prefix}, nil
+	case "dir":
+		if s.Dir == "" {
+			return nil, errors.New("secrets: dir provider needs a dir")
+		}
+		return secrets.Dir(c.path(s.Dir)), nil
+	case "vault":
+		return &secrets.Vault{Address: s.Address, Mount: s.Mount, KV1: s.KV1}, nil
+	case "aws":
+		return &secrets.AWSSecretsManager{Region: s.Region}, nil
+	}
+	return nil, fmt.Errorf("secrets: unknown provider %q (providers: env, dir, vault, aws)", s.Provider)
+}
+
+func (c *Config) tlsConfig(t *TLS) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(c.path(t.CAFile))
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.path(t.CertFile), c.path(t.KeyFile))
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// path gets the path, relative to the directory of the config file.
+func (c *Config) path(path string) string {
+	if filepath.IsAbs(path) || c.dir == "" {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
@@ -0,0 +1,145 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/config"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/secrets"
+)
+
+func TestLoad(t *testing.T) {
+	is := is.New(t)
+	c, err := config.Load("../testfiles/config/silk.yaml")
+	is.NoErr(err)
+	is.Equal(c.FilePatterns(), []string{filepath.Join("..", "testfiles", "config", "*.silk.md")})
+
+	env, err := c.Env("")
+	is.NoErr(err)
+	is.Equal(env.URL, "http://localhost:8080")
+	is.Equal(env.Headers, map[string]string{"Accept": "application/json"})
+	is.Equal(env.Vars["userID"], 1)
+	is.Nil(env.TLS)
+	is.Equal(env.Timeout, config.Duration(30*time.Second))
+	is.Equal(c.Concurrency, 4)
+	is.Equal(c.ReportFiles(), config.Reports{HTML: filepath.Join("..", "testfiles", "config", "report.html"), GitHub: true})
+
+	env, err = c.Env("staging")
+	is.NoErr(err)
+	is.Equal(env.URL, "https://staging.example.com/")
+	is.Equal(env.Headers, map[string]string{"Accept": "application/json", "X-Env": "staging"})
+	is.Equal(env.Vars["userID"], 123)
+	is.True(env.TLS.InsecureSkipVerify)
+	is.Equal(env.Timeout, config.Duration(time.Minute))
+
+	_, err = c.Env("dev")
+	is.Equal(err.Error(), `unknown environment "dev" (environments: production, staging)`)
+}
+
+func TestRunner(t *testing.T) {
+	is := is.New(t)
+	c, err := config.Load("../testfiles/config/silk.yaml")
+	is.NoErr(err)
+	r, err := c.Runner(t, "staging")
+	is.NoErr(err)
+	is.Equal(r.Header.Get("Accept"), "application/json")
+	is.Equal(r.Header.Get("X-Env"), "staging")
+	is.Equal(r.Vars["userID"], 123)
+	transport, ok := r.RoundTripper.(*http.Transport)
+	is.True(ok)
+	is.True(transport.TLSClientConfig.InsecureSkipVerify)
+	is.Equal(r.Secrets, secrets.Env{Prefix: "STAGING_"})
+	is.Equal(r.ConnectTo, "10.0.0.5:443")
+	is.Equal(r.Redact, []string{"Authorization", "password"})
+	is.Equal(r.RateLimit, 10.0)
+	is.Equal(r.Timeout, time.Minute)
+
+	r, err = c.Runner(t, "production")
+	is.NoErr(err)
+	is.Equal(r.RoundTripper, http.DefaultTransport)
+	is.Nil(r.Secrets)
+	is.Equal(r.Proxy, "http://proxy.example.com:3128")
+	is.Equal(r.Redact, []string{"Authorization"})
+	// vars take precedence over the vars file
+	is.Equal(r.Vars["userID"], 1)
+	is.Equal(r.Vars["tenant"], "acme")
+	is.Equal(r.Timeout, 30*time.Second)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	is := is.New(t)
+	wd, err := os.Getwd()
+	is.NoErr(err)
+	defer os.Chdir(wd)
+	is.NoErr(os.Chdir("../testfiles/config"))
+	r, err := runner.NewFromConfig(t, "staging")
+	is.NoErr(err)
+	is.Equal(r.Header.Get("X-Env"), "staging")
+	is.Equal(r.Timeout, time.Minute)
+
+	_, err = runner.NewFromConfig(t, "dev")
+	is.Err(err)
+}
+
+func TestLoadVars(t *testing.T) {
+	is := is.New(t)
+	vars, err := config.LoadVars("../testfiles/config/vars.yaml")
+	is.NoErr(err)
+	is.Equal(vars["userID"], 42)
+	is.Equal(vars["users"], map[string]interface{}{"admin": map[string]interface{}{"id": 7}})
+
+	vars, err = config.LoadVars("../testfiles/config/vars.json")
+	is.NoErr(err)
+	is.Equal(vars["tenant"], "acme")
+	is.Equal(vars["users"], map[string]interface{}{"admin": map[string]interface{}{"id": 7.0}})
+
+	_, err = config.LoadVars("../testfiles/config/silk.yaml.missing")
+	is.Err(err)
+}
+
+func TestLoadErrors(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "silk.yaml")
+
+	// files may be a list
+	is.NoErr(ioutil.WriteFile(filename, []byte("files:\n  - a/*.silk.md\n  - /b/*.silk.md\n"), 0644))
+	c, err := config.Load(filename)
+	is.NoErr(err)
+	is.Equal(c.FilePatterns(), []string{filepath.Join(dir, "a/*.silk.md"), "/b/*.silk.md"})
+	_, err = c.Runner(t, "")
+	is.Equal(err.Error(), "no url")
+
+	// timeouts are durations
+	is.NoErr(ioutil.WriteFile(filename, []byte("url: http://localhost\ntimeout: 30\n"), 0644))
+	_, err = config.Load(filename)
+	is.Err(err)
+
+	// unknown settings are errors
+	is.NoErr(ioutil.WriteFile(filename, []byte("url: http://localhost\nheader:\n  Accept: text/plain\n"), 0644))
+	_, err = config.Load(filename)
+	is.Err(err)
+
+	// unknown secret providers are errors
+	is.NoErr(ioutil.WriteFile(filename, []byte("url: http://localhost\nsecrets:\n  provider: keychain\n"), 0644))
+	c, err = config.Load(filename)
+	is.NoErr(err)
+	_, err = c.Runner(t, "")
+	is.Err(err)
+
+	// databases need their drivers imported
+	is.NoErr(ioutil.WriteFile(filename, []byte("url: http://localhost\ndatabases:\n  main:\n    driver: nope\n    dsn: nope://\n"), 0644))
+	c, err = config.Load(filename)
+	is.NoErr(err)
+	_, err = c.Runner(t, "")
+	is.True(strings.HasPrefix(err.Error(), "database main: sql: unknown driver"))
+}
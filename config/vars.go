@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadVars loads a JSON or YAML file of variables, like IDs and
+// endpoints shared by many silk files. Files ending in .json are
+// JSON, and others are YAML. Nested objects are available to
+// templates, like {{.users.admin.id}}.
+func LoadVars(filename string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]interface{}
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = json.Unmarshal(b, &vars)
+	} else {
+		err = yaml.Unmarshal(b, &vars)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filename, err)
+	}
+	for k, v := range vars {
+		vars[k] = stringKeys(v)
+	}
+	return vars, nil
+}
+
+// stringKeys converts the map[interface{}]interface{} maps that
+// YAML decodes objects into to map[string]interface{}, like JSON
+// objects, so that they work the same way.
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = stringKeys(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = stringKeys(val)
+		}
+	}
+	return v
+}
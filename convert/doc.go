@@ -0,0 +1,3 @@
+// Package convert contains packages that convert other formats
+// into Silk files.
+package convert
@@ -0,0 +1,152 @@
+// Package har converts HAR (HTTP Archive) files, as exported by
+// browsers, into Silk files, and records the requests silk makes
+// as HAR files.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/matryer/silk/record"
+	"github.com/matryer/silk/write"
+)
+
+// IgnoredHeaders are request headers that are not converted,
+// in addition to record.IgnoredHeaders.
+// Headers starting with : (HTTP/2 pseudo headers) or Sec- are
+// always ignored.
+var IgnoredHeaders = map[string]bool{
+	"Accept-Language": true,
+	"Cache-Control":   true,
+	"Cookie":          true,
+	"Host":            true,
+	"Origin":          true,
+	"Pragma":          true,
+	"Referer":         true,
+}
+
+type archive struct {
+	Log struct {
+		Pages []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"pages"`
+		Entries []struct {
+			Pageref string `json:"pageref"`
+			Request struct {
+				Method      string   `json:"method"`
+				URL         string   `json:"url"`
+				Headers     []header `json:"headers"`
+				QueryString []header `json:"queryString"`
+				PostData    *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int      `json:"status"`
+				Headers []header `json:"headers"`
+				Content struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Convert converts a HAR file into silk groups.
+// There is a group for each page, and a group for each host
+// for entries that are not part of a page.
+func Convert(r io.Reader) ([]*write.Group, error) {
+	var a archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, err
+	}
+	var groups []*write.Group
+	groupsByKey := make(map[string]*write.Group)
+	for _, page := range a.Log.Pages {
+		group := &write.Group{Title: page.Title}
+		if group.Title == "" {
+			group.Title = page.ID
+		}
+		groupsByKey["page:"+page.ID] = group
+		groups = append(groups, group)
+	}
+	for _, entry := range a.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return nil, err
+		}
+		key := "page:" + entry.Pageref
+		if entry.Pageref == "" || groupsByKey[key] == nil {
+			key = "host:" + u.Host
+		}
+		group := groupsByKey[key]
+		if group == nil {
+			group = &write.Group{Title: u.Host}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+		req := &write.Request{
+			Method:         entry.Request.Method,
+			Path:           u.EscapedPath(),
+			Params:         url.Values{},
+			ExpectedStatus: entry.Response.Status,
+		}
+		if req.Path == "" {
+			req.Path = "/"
+		}
+		for _, q := range entry.Request.QueryString {
+			req.Params.Add(q.Name, q.Value)
+		}
+		if len(entry.Request.QueryString) == 0 {
+			req.Params = u.Query()
+		}
+		for _, h := range entry.Request.Headers {
+			name := http.CanonicalHeaderKey(h.Name)
+			if ignored(name) {
+				continue
+			}
+			req.Details = append(req.Details, write.Detail{Key: name, Value: h.Value})
+		}
+		if entry.Request.PostData != nil && write.Printable([]byte(entry.Request.PostData.Text)) {
+			req.Body = []byte(entry.Request.PostData.Text)
+		}
+		resHeader := make(http.Header)
+		for _, h := range entry.Response.Headers {
+			resHeader.Add(h.Name, h.Value)
+		}
+		for _, k := range record.AssertedHeaders {
+			if v := resHeader.Get(k); v != "" {
+				req.ExpectedDetails = append(req.ExpectedDetails, write.Detail{Key: k, Value: v})
+			}
+		}
+		body := []byte(entry.Response.Content.Text)
+		if entry.Response.Content.Encoding == "base64" {
+			if body, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text); err != nil {
+				return nil, err
+			}
+		}
+		if write.Printable(body) {
+			req.ExpectedBody = body
+		}
+		group.Requests = append(group.Requests, req)
+	}
+	return groups, nil
+}
+
+func ignored(name string) bool {
+	return strings.HasPrefix(name, ":") ||
+		strings.HasPrefix(name, "Sec-") ||
+		IgnoredHeaders[name] ||
+		record.IgnoredHeaders[name]
+}
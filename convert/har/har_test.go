@@ -0,0 +1,51 @@
+package har_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/convert/har"
+	"github.com/matryer/silk/write"
+)
+
+func TestConvert(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../../testfiles/convert/session.har")
+	is.NoErr(err)
+	defer f.Close()
+	groups, err := har.Convert(f)
+	is.NoErr(err)
+	is.Equal(len(groups), 2)
+	var buf bytes.Buffer
+	is.NoErr(write.Write(&buf, groups...))
+	is.Equal(buf.String(), "# Comments\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* ?pretty=\"true\"\n"+
+		"\n"+
+		"```\n"+
+		"{\"name\":\"Mat\"}\n"+
+		"```\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"\n"+
+		"```\n"+
+		"{\"id\":1}\n"+
+		"```\n"+
+		"\n"+
+		"# cdn.example.com\n"+
+		"\n"+
+		"## GET /logo.png\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 200\n"+
+		"* Content-Type: \"image/png\"\n")
+}
@@ -0,0 +1,298 @@
+// Package openapi generates skeleton Silk files from OpenAPI (v3)
+// documents.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/matryer/silk/write"
+)
+
+type document struct {
+	Info struct {
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+	} `yaml:"info"`
+	Paths map[string]*pathItem `yaml:"paths"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Put        *operation   `yaml:"put"`
+	Post       *operation   `yaml:"post"`
+	Delete     *operation   `yaml:"delete"`
+	Options    *operation   `yaml:"options"`
+	Head       *operation   `yaml:"head"`
+	Patch      *operation   `yaml:"patch"`
+}
+
+type operation struct {
+	Summary     string       `yaml:"summary"`
+	Description string       `yaml:"description"`
+	Tags        []string     `yaml:"tags"`
+	Parameters  []*parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]*mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]*response `yaml:"responses"`
+}
+
+type response struct {
+	Content map[string]*mediaType `yaml:"content"`
+}
+
+type parameter struct {
+	Name     string      `yaml:"name"`
+	In       string      `yaml:"in"`
+	Required bool        `yaml:"required"`
+	Example  interface{} `yaml:"example"`
+	Schema   *struct {
+		Example interface{} `yaml:"example"`
+	} `yaml:"schema"`
+}
+
+func (p *parameter) example() interface{} {
+	if p.Example == nil && p.Schema != nil {
+		return p.Schema.Example
+	}
+	return p.Example
+}
+
+type mediaType struct {
+	Example  interface{} `yaml:"example"`
+	Examples map[string]*struct {
+		Value interface{} `yaml:"value"`
+	} `yaml:"examples"`
+	Schema *struct {
+		Example interface{} `yaml:"example"`
+	} `yaml:"schema"`
+}
+
+// example gets the example, or the first of the named
+// examples if there is no single example.
+func (m *mediaType) example() interface{} {
+	if m.Example != nil {
+		return m.Example
+	}
+	if len(m.Examples) > 0 {
+		var names []string
+		for name := range m.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if example := m.Examples[names[0]]; example != nil {
+			return example.Value
+		}
+	}
+	if m.Schema != nil {
+		return m.Schema.Example
+	}
+	return nil
+}
+
+// Convert generates silk groups from an OpenAPI document, in
+// YAML or JSON.
+// There is a group for each tag (operations without tags are
+// grouped under the document title), and a request for each
+// operation, using the examples from the document where they are
+// given. Path parameters without examples are left as {variable}
+// references.
+// References ($ref) are not followed.
+func Convert(r io.Reader) ([]*write.Group, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var doc document
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	var groups []*write.Group
+	groupsByTag := make(map[string]*write.Group)
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, op := range []struct {
+			method string
+			op     *operation
+		}{
+			{"GET", item.Get},
+			{"POST", item.Post},
+			{"PUT", item.Put},
+			{"PATCH", item.Patch},
+			{"DELETE", item.Delete},
+			{"HEAD", item.Head},
+			{"OPTIONS", item.Options},
+		} {
+			if op.op == nil {
+				continue
+			}
+			title := doc.Info.Title
+			if len(op.op.Tags) > 0 {
+				title = op.op.Tags[0]
+			}
+			group := groupsByTag[title]
+			if group == nil {
+				group = &write.Group{Title: title}
+				if title == doc.Info.Title {
+					group.Description = strings.TrimSpace(doc.Info.Description)
+				}
+				groupsByTag[title] = group
+				groups = append(groups, group)
+			}
+			req, err := convertOperation(op.method, path, item.Parameters, op.op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %s", op.method, path, err)
+			}
+			group.Requests = append(group.Requests, req)
+		}
+	}
+	return groups, nil
+}
+
+func convertOperation(method, path string, pathParams []*parameter, op *operation) (*write.Request, error) {
+	req := &write.Request{
+		Method:      method,
+		Path:        path,
+		Description: strings.TrimSpace(op.Summary + "\n\n" + strings.TrimSpace(op.Description)),
+		Params:      url.Values{},
+	}
+	// operation parameters override path item parameters
+	params := make(map[string]*parameter)
+	var names []string
+	for _, param := range append(pathParams, op.Parameters...) {
+		key := param.In + ":" + param.Name
+		if _, ok := params[key]; !ok {
+			names = append(names, key)
+		}
+		params[key] = param
+	}
+	for _, key := range names {
+		param := params[key]
+		example := param.example()
+		if example == nil {
+			continue
+		}
+		value := fmt.Sprint(cleanYAML(example))
+		switch param.In {
+		case "path":
+			req.Path = strings.Replace(req.Path, "{"+param.Name+"}", url.PathEscape(value), -1)
+		case "query":
+			if param.Required {
+				req.Params.Add(param.Name, value)
+			}
+		case "header":
+			req.Details = append(req.Details, write.Detail{Key: param.Name, Value: value})
+		}
+	}
+	if op.RequestBody != nil {
+		contentType, media := jsonMedia(op.RequestBody.Content)
+		if media != nil {
+			req.Details = append(req.Details, write.Detail{Key: "Content-Type", Value: contentType})
+			if example := media.example(); example != nil {
+				body, err := json.MarshalIndent(cleanYAML(example), "", "  ")
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		}
+	}
+	status, res := successResponse(op.Responses)
+	if status == 0 {
+		return req, nil
+	}
+	req.ExpectedStatus = status
+	if res == nil {
+		return req, nil
+	}
+	contentType, media := jsonMedia(res.Content)
+	if media == nil {
+		return req, nil
+	}
+	req.ExpectedDetails = append(req.ExpectedDetails, write.Detail{Key: "Content-Type", Value: contentType})
+	// assert the top level fields of the example response
+	example, ok := cleanYAML(media.example()).(map[string]interface{})
+	if !ok {
+		return req, nil
+	}
+	var fields []string
+	for field := range example {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		switch example[field].(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		req.ExpectedDetails = append(req.ExpectedDetails, write.Detail{Key: "Data." + field, Value: example[field]})
+	}
+	return req, nil
+}
+
+// successResponse gets the lowest 2xx response.
+func successResponse(responses map[string]*response) (int, *response) {
+	lowest := 0
+	for code := range responses {
+		status, err := strconv.Atoi(code)
+		if err != nil || status < 200 || status > 299 {
+			continue
+		}
+		if lowest == 0 || status < lowest {
+			lowest = status
+		}
+	}
+	if lowest == 0 {
+		return 0, nil
+	}
+	return lowest, responses[strconv.Itoa(lowest)]
+}
+
+// jsonMedia gets the JSON media type from the content, or
+// nil if there isn't one.
+func jsonMedia(content map[string]*mediaType) (string, *mediaType) {
+	for contentType, media := range content {
+		if media != nil && strings.Contains(contentType, "json") {
+			return contentType, media
+		}
+	}
+	return "", nil
+}
+
+// cleanYAML turns the map[interface{}]interface{} values made
+// by the YAML decoder into map[string]interface{}, so they can
+// be encoded as JSON.
+func cleanYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = cleanYAML(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = cleanYAML(v[i])
+		}
+		return v
+	}
+	return v
+}
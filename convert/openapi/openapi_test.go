@@ -0,0 +1,87 @@
+package openapi_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/convert/openapi"
+	"github.com/matryer/silk/write"
+)
+
+func TestConvert(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../../testfiles/convert/openapi.yaml")
+	is.NoErr(err)
+	defer f.Close()
+	groups, err := openapi.Convert(f)
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(write.Write(&buf, groups...))
+	is.Equal(buf.String(), "# Comments\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"Create a comment\n"+
+		"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* ?pretty=\"true\"\n"+
+		"\n"+
+		"```\n"+
+		"{\n"+
+		"  \"comment\": \"Good work\",\n"+
+		"  \"name\": \"Mat\"\n"+
+		"}\n"+
+		"```\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* Data.id: 123\n"+
+		"* Data.name: \"Mat\"\n"+
+		"\n"+
+		"## GET /comments/{id}\n"+
+		"\n"+
+		"Read a comment\n"+
+		"\n"+
+		"* X-Request-ID: \"abc\"\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 200\n"+
+		"\n"+
+		"## DELETE /comments/123\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 204\n"+
+		"\n"+
+		"# Comments API\n"+
+		"\n"+
+		"An API for comments.\n"+
+		"\n"+
+		"## GET /health\n"+
+		"\n"+
+		"Check the service is up\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 200\n")
+}
+
+func TestConvertJSON(t *testing.T) {
+	is := is.New(t)
+	groups, err := openapi.Convert(strings.NewReader(`{
+		"info": {"title": "API"},
+		"paths": {"/things": {"get": {"responses": {"200": {"description": "OK"}}}}}
+	}`))
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	is.Equal(groups[0].Title, "API")
+	is.Equal(len(groups[0].Requests), 1)
+	is.Equal(groups[0].Requests[0].Path, "/things")
+	is.Equal(groups[0].Requests[0].ExpectedStatus, 200)
+}
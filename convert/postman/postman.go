@@ -0,0 +1,217 @@
+// Package postman converts Postman collections into Silk files.
+package postman
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/write"
+)
+
+type collection struct {
+	Info struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Item []*item `json:"item"`
+}
+
+type item struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Item        []*item `json:"item"`
+	Request     *struct {
+		Method string `json:"method"`
+		Header []struct {
+			Key      string `json:"key"`
+			Value    string `json:"value"`
+			Disabled bool   `json:"disabled"`
+		} `json:"header"`
+		URL  urlValue `json:"url"`
+		Body *struct {
+			Mode string `json:"mode"`
+			Raw  string `json:"raw"`
+		} `json:"body"`
+	} `json:"request"`
+	Event []struct {
+		Listen string `json:"listen"`
+		Script struct {
+			Exec execValue `json:"exec"`
+		} `json:"script"`
+	} `json:"event"`
+}
+
+// urlValue is a Postman URL, which may be a string or an object.
+type urlValue struct {
+	Raw   string   `json:"raw"`
+	Path  []string `json:"path"`
+	Query []struct {
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled"`
+	} `json:"query"`
+}
+
+func (u *urlValue) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &u.Raw)
+	}
+	type plain urlValue
+	return json.Unmarshal(b, (*plain)(u))
+}
+
+// execValue is a script, which may be a string or an array of lines.
+type execValue []string
+
+func (e *execValue) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*e = strings.Split(s, "\n")
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(e))
+}
+
+var (
+	// postmanVarRegex matches {{variables}}.
+	postmanVarRegex = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+	identRegex      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// statusTestRegexes match tests asserting the status code.
+	statusTestRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`pm\.response\.to\.have\.status\(\s*(\d{3})\s*\)`),
+		regexp.MustCompile(`pm\.response\.code\)\.to\.(?:eql|equal)\(\s*(\d{3})\s*\)`),
+		regexp.MustCompile(`responseCode\.code\s*===?\s*(\d{3})`),
+	}
+)
+
+// Convert converts a Postman (v2) collection into silk groups.
+// Top level folders become groups, and requests outside of any
+// folder are put in a group named after the collection.
+// Postman {{variables}} become template variables, and basic
+// status code tests become assertions.
+func Convert(r io.Reader) ([]*write.Group, error) {
+	var c collection
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	root := &write.Group{
+		Title:       c.Info.Name,
+		Description: c.Info.Description,
+	}
+	groups := []*write.Group{root}
+	for _, it := range c.Item {
+		if it.Request != nil {
+			root.Requests = append(root.Requests, convertRequest(it))
+			continue
+		}
+		group := &write.Group{
+			Title:       it.Name,
+			Description: it.Description,
+		}
+		addRequests(group, it.Item)
+		groups = append(groups, group)
+	}
+	if len(root.Requests) == 0 {
+		groups = groups[1:]
+	}
+	return groups, nil
+}
+
+// addRequests adds the requests in the items, and in any
+// nested folders.
+func addRequests(group *write.Group, items []*item) {
+	for _, it := range items {
+		if it.Request == nil {
+			addRequests(group, it.Item)
+			continue
+		}
+		group.Requests = append(group.Requests, convertRequest(it))
+	}
+}
+
+func convertRequest(it *item) *write.Request {
+	req := &write.Request{
+		Method:      it.Request.Method,
+		Description: strings.TrimSpace(it.Name + "\n\n" + it.Description),
+		Params:      url.Values{},
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	req.Path = convertPath(it.Request.URL)
+	for _, q := range it.Request.URL.Query {
+		if q.Disabled {
+			continue
+		}
+		req.Params.Add(q.Key, convertVars(q.Value))
+	}
+	for _, h := range it.Request.Header {
+		if h.Disabled {
+			continue
+		}
+		req.Details = append(req.Details, write.Detail{Key: h.Key, Value: convertVars(h.Value)})
+	}
+	if it.Request.Body != nil && it.Request.Body.Mode == "raw" {
+		req.Body = []byte(convertVars(it.Request.Body.Raw))
+	}
+	for _, event := range it.Event {
+		if event.Listen != "test" {
+			continue
+		}
+		script := strings.Join(event.Script.Exec, "\n")
+		for _, regex := range statusTestRegexes {
+			if match := regex.FindStringSubmatch(script); match != nil {
+				req.ExpectedStatus, _ = strconv.Atoi(match[1])
+				break
+			}
+		}
+	}
+	return req
+}
+
+// convertPath gets the path of a URL, without the scheme, host
+// (usually a {{baseUrl}} variable) or query.
+// Path variables (:id) become {id} variable references.
+func convertPath(u urlValue) string {
+	var segments []string
+	if len(u.Path) > 0 {
+		segments = u.Path
+	} else {
+		raw := u.Raw
+		if i := strings.Index(raw, "?"); i != -1 {
+			raw = raw[:i]
+		}
+		if i := strings.Index(raw, "://"); i != -1 {
+			raw = raw[i+3:]
+		}
+		segments = strings.Split(raw, "/")
+		// the first segment is the host
+		segments = segments[1:]
+	}
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+			continue
+		}
+		segments[i] = convertVars(segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// convertVars turns Postman {{variables}} into template variables.
+func convertVars(s string) string {
+	return postmanVarRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := postmanVarRegex.FindStringSubmatch(ref)[1]
+		if identRegex.MatchString(name) {
+			return "{{." + name + "}}"
+		}
+		return "{{index . " + strconv.Quote(name) + "}}"
+	})
+}
@@ -0,0 +1,57 @@
+package postman_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/convert/postman"
+	"github.com/matryer/silk/write"
+)
+
+func TestConvert(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../../testfiles/convert/collection.postman.json")
+	is.NoErr(err)
+	defer f.Close()
+	groups, err := postman.Convert(f)
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(write.Write(&buf, groups...))
+	is.Equal(buf.String(), "# Comments API\n"+
+		"\n"+
+		"## GET /health\n"+
+		"\n"+
+		"Health check\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"# Comments\n"+
+		"\n"+
+		"Managing comments.\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"Create a comment\n"+
+		"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* Authorization: \"Bearer {{index . \\\"api-token\\\"}}\"\n"+
+		"* ?pretty=\"true\"\n"+
+		"\n"+
+		"```\n"+
+		"{\"name\": \"{{.name}}\", \"comment\": \"Good work\"}\n"+
+		"```\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"\n"+
+		"## DELETE /comments/{id}\n"+
+		"\n"+
+		"Delete a comment\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 204\n")
+}
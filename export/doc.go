@@ -0,0 +1,3 @@
+// Package export contains packages that convert Silk files into
+// other formats.
+package export
@@ -0,0 +1,478 @@
+// Package gotest generates standalone Go tests from Silk files.
+//
+// Each request becomes a test function that makes the request
+// with net/http and checks the response, for scenarios that need
+// custom logic that silk files can't express.
+// Templates and variables are not expanded, so requests that use
+// them need editing by hand, as do time and duration assertions,
+// which are left as comments.
+package gotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// Generate writes a Go test file, in the package pkg, with a test
+// for each request in the groups.
+// The tests make requests to the URL given by the -silk.url flag.
+func Generate(w io.Writer, pkg string, groups ...*parse.Group) error {
+	g := &generator{names: make(map[string]int)}
+	for _, group := range groups {
+		for _, req := range group.Setup {
+			g.request(group, req)
+		}
+		for _, req := range group.Requests {
+			g.request(group, req)
+		}
+		for _, req := range group.Teardown {
+			g.request(group, req)
+		}
+	}
+	if g.err != nil {
+		return g.err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by silk gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import (\n")
+	for _, imp := range imports {
+		if imp == "io/ioutil" && !g.readsBody {
+			continue
+		}
+		fmt.Fprintf(&buf, "%q\n", imp)
+	}
+	fmt.Fprintf(&buf, ")\n\n%s", helpers)
+	g.buf.WriteTo(&buf)
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	buf bytes.Buffer
+	// names counts the test function names, to keep them unique.
+	names map[string]int
+	// readsBody is whether any test reads the response body.
+	readsBody bool
+	err       error
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) request(group *parse.Group, req *parse.Request) {
+	switch string(req.Method) {
+	case parse.MethodExec, parse.MethodSQL, parse.MethodConsume:
+		g.printf("\n// %s:%d is a %s step, which isn't generated: %s\n", group.Filename, req.Number, req.Method, req.Path)
+		return
+	}
+	name := "Test" + identifier(string(group.Title)) + "_" + identifier(string(req.Method)+" "+string(req.Path))
+	g.names[name]++
+	if n := g.names[name]; n > 1 {
+		name += strconv.Itoa(n)
+	}
+	g.printf("\n// %s tests %s %s (%s:%d).\n", name, req.Method, req.Path, group.Filename, req.Number)
+	g.printf("func %s(t *testing.T) {\n", name)
+	body := "nil"
+	if len(req.Body) > 0 {
+		b, err := runner.RequestBody(req)
+		if err != nil {
+			g.err = fmt.Errorf("line %d: %v", req.Body.Number(), err)
+			return
+		}
+		body = "strings.NewReader(" + strconv.Quote(string(b)) + ")"
+	}
+	g.printf("req, err := http.NewRequest(%q, *silkURL+%q, %s)\n", req.Method, req.Path, body)
+	g.printf("if err != nil {\nt.Fatal(err)\n}\n")
+	var rawQuery string
+	for _, line := range req.Details {
+		detail := line.Detail()
+		if detail.Key == "RawQuery" {
+			rawQuery = fmt.Sprintf("%v", detail.Value.Data)
+		}
+		if runner.IsDirective(detail.Key) {
+			continue
+		}
+		if http.CanonicalHeaderKey(detail.Key) == "Host" {
+			g.printf("req.Host = %q\n", fmt.Sprintf("%v", detail.Value.Data))
+			continue
+		}
+		g.printf("req.Header.Add(%q, %q)\n", detail.Key, fmt.Sprintf("%v", detail.Value.Data))
+	}
+	if len(req.Params) > 0 {
+		g.printf("q := req.URL.Query()\n")
+		for _, line := range req.Params {
+			detail := line.Detail()
+			for _, val := range runner.ParamValues(detail) {
+				g.printf("q.Add(%q, %q)\n", detail.Key, val)
+			}
+		}
+		g.printf("req.URL.RawQuery = q.Encode()\n")
+	}
+	if rawQuery != "" {
+		if len(req.Params) > 0 {
+			g.printf("req.URL.RawQuery = %q + \"&\" + req.URL.RawQuery\n", rawQuery)
+		} else {
+			g.printf("req.URL.RawQuery = %q\n", rawQuery)
+		}
+	}
+	g.printf("res, err := http.DefaultClient.Do(req)\n")
+	g.printf("if err != nil {\nt.Fatal(err)\n}\n")
+	g.printf("defer res.Body.Close()\n")
+	expectedBody, _, err := runner.ExpectedBody(group, req)
+	if err != nil {
+		g.err = err
+		return
+	}
+	if expectedBody == nil && !assertsData(req) && !assertsBodySize(req) {
+		for _, line := range req.ExpectedDetails {
+			g.assertDetail(line)
+		}
+		g.printf("}\n")
+		return
+	}
+	g.readsBody = true
+	g.printf("body, err := ioutil.ReadAll(res.Body)\n")
+	g.printf("if err != nil {\nt.Fatal(err)\n}\n")
+	if expectedBody != nil {
+		g.printf("if expected := %s; string(body) != expected {\n", strconv.Quote(string(expectedBody)))
+		g.printf("t.Errorf(\"body expected:\\n%%s\\nactual:\\n%%s\", expected, body)\n")
+		g.printf("}\n")
+	}
+	if assertsData(req) {
+		g.printf("var data interface{}\n")
+		g.printf("if err := json.Unmarshal(body, &data); err != nil {\nt.Fatal(err)\n}\n")
+	}
+	for _, line := range req.ExpectedDetails {
+		g.assertDetail(line)
+	}
+	for _, block := range req.ExpectedData {
+		var v interface{}
+		if err := json.Unmarshal([]byte(block.Lines.String()), &v); err != nil {
+			g.err = fmt.Errorf("line %d: %s: %v", block.Number, block.Key, err)
+			return
+		}
+		expected, err := literal(v)
+		if err != nil {
+			g.err = err
+			return
+		}
+		g.printf("silkAssert(t, %q, silkGet(data, %q), %s)\n", block.Key, strings.TrimPrefix(block.Key, "Data"), expected)
+	}
+	g.printf("}\n")
+}
+
+func (g *generator) assertDetail(line *parse.Line) {
+	detail := line.Detail()
+	if runner.IsResponseDirective(detail.Key) {
+		return
+	}
+	e, err := runner.Expect(detail)
+	if err != nil {
+		g.err = fmt.Errorf("line %d: %s: %v", line.Number, detail.Key, err)
+		return
+	}
+	if e.Subject == runner.SubjectXPath || e.Subject == runner.SubjectStream || e.Subject == runner.SubjectCORS || e.Subject == runner.SubjectIdempotent {
+		g.printf("// %s: %v is not checked\n", detail.Key, detail.Value.Data)
+		return
+	}
+	if e.Subject == runner.SubjectBodySize {
+		cmp, err := sizeComparison(e.Value)
+		if err != nil {
+			g.err = fmt.Errorf("line %d: %s: %v", line.Number, detail.Key, err)
+			return
+		}
+		g.printf("if n := len(body); !(n %s) {\n", cmp)
+		g.printf("t.Errorf(\"%s expected %s  actual %%d\", n)\n", detail.Key, cmp)
+		g.printf("}\n")
+		return
+	}
+	switch e.Match {
+	case runner.MatchIgnoreCase:
+		actual := actualExpr(e)
+		if e.Subject != runner.SubjectHeader || e.Index >= 0 {
+			actual = "fmt.Sprint(" + actual + ")"
+		}
+		g.printf("if v := %s; !strings.EqualFold(v, %q) {\n", actual, e.Value)
+		g.printf("t.Errorf(\"%s expected %%q (ignoring case)  actual %%q\", %q, v)\n", detail.Key, e.Value)
+		g.printf("}\n")
+	case runner.MatchAbsent:
+		g.printf("if v, ok := res.Header[%q]; ok {\n", http.CanonicalHeaderKey(e.Name))
+		g.printf("t.Errorf(\"%s expected (absent)  actual %%q\", v)\n", detail.Key)
+		g.printf("}\n")
+	case runner.MatchNull, runner.MatchMissing:
+		failed := "ok"
+		if e.Match == runner.MatchNull {
+			failed = "!ok || v != nil"
+		}
+		g.printf("if v, ok := silkLookup(data, %q); %s {\n", e.Name, failed)
+		g.printf("t.Errorf(\"%s expected %v  actual %%v\", v)\n", detail.Key, detail.Value.Data)
+		g.printf("}\n")
+	case runner.MatchEmpty:
+		g.printf("if v, ok := silkLookup(data, %q); ok && v != nil && fmt.Sprint(v) != \"[]\" {\n", e.Name)
+		g.printf("t.Errorf(\"%s expected empty  actual %%v\", v)\n", detail.Key)
+		g.printf("}\n")
+	case runner.MatchApprox:
+		g.printf("silkApprox(t, %q, %s, %v, %v)\n", detail.Key, actualExpr(e), e.Value, e.Tolerance)
+	case runner.MatchCustom:
+		g.printf("// %s: %v is not checked\n", detail.Key, detail.Value.Data)
+	default:
+		expected, err := literal(e.Value)
+		if err != nil {
+			g.err = err
+			return
+		}
+		g.printf("silkAssert(t, %q, %s, %s)\n", detail.Key, actualExpr(e), expected)
+	}
+}
+
+// actualExpr gets the Go expression for the actual value of the
+// expectation.
+func actualExpr(e *runner.Expectation) string {
+	switch e.Subject {
+	case runner.SubjectStatus:
+		return "float64(res.StatusCode)"
+	case runner.SubjectData:
+		return fmt.Sprintf("silkGet(data, %q)", e.Name)
+	case runner.SubjectCookie:
+		return fmt.Sprintf("silkCookie(res, %q, %q)", e.Name, e.Attr)
+	}
+	if e.Index >= 0 {
+		return fmt.Sprintf("silkGet(silkValues(res.Header[%q]), %q)", http.CanonicalHeaderKey(e.Name), "["+strconv.Itoa(e.Index)+"]")
+	}
+	if isList(e.Value) {
+		return fmt.Sprintf("silkValues(res.Header[%q])", http.CanonicalHeaderKey(e.Name))
+	}
+	return fmt.Sprintf("res.Header.Get(%q)", e.Name)
+}
+
+func isList(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// bodySizeKey is the assertion about the length of the body.
+const bodySizeKey = "BodySize"
+
+func assertsBodySize(req *parse.Request) bool {
+	for _, line := range req.ExpectedDetails {
+		if line.Detail().Key == bodySizeKey {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeComparison gets the Go comparison for a BodySize value,
+// like "< 10240".
+func sizeComparison(v interface{}) (string, error) {
+	if n, ok := v.(float64); ok {
+		v = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	s := strings.TrimSpace(fmt.Sprint(v))
+	op := "=="
+	for _, o := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+		if strings.HasPrefix(s, o) {
+			op, s = o, strings.TrimSpace(s[len(o):])
+			break
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return "", fmt.Errorf("bad size %q", fmt.Sprint(v))
+	}
+	return op + " " + strconv.Itoa(n), nil
+}
+
+func assertsData(req *parse.Request) bool {
+	if len(req.ExpectedData) > 0 {
+		return true
+	}
+	for _, line := range req.ExpectedDetails {
+		if key := line.Detail().Key; strings.HasPrefix(key, "Data") || strings.HasPrefix(key, "GraphQLErrors") {
+			return true
+		}
+	}
+	return false
+}
+
+// literal gets the Go expression for the value.
+func literal(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return "float64(" + strconv.FormatFloat(v, 'g', -1, 64) + ")", nil
+	case string:
+		return strconv.Quote(v), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return "silkJSON(" + strconv.Quote(string(b)) + ")", nil
+}
+
+// identifier makes a Go identifier from s.
+func identifier(s string) string {
+	var buf bytes.Buffer
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// imports are the packages imported by the generated tests.
+var imports = []string{
+	"encoding/json",
+	"flag",
+	"fmt",
+	"io/ioutil",
+	"net/http",
+	"reflect",
+	"regexp",
+	"strconv",
+	"strings",
+	"testing",
+}
+
+// helpers is the code shared by the generated tests.
+const helpers = `var silkURL = flag.String("silk.url", "http://localhost:8080", "url to make requests to")
+
+// silkAssert checks the actual value equals the expected value,
+// or matches it if it is a /regex/.
+func silkAssert(t *testing.T, key string, actual, expected interface{}) {
+	t.Helper()
+	if s, ok := expected.(string); ok && len(s) > 1 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/") {
+		if regexp.MustCompile(s[1 : len(s)-1]).MatchString(fmt.Sprint(actual)) {
+			return
+		}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("%s expected %v  actual %v", key, expected, actual)
+	}
+}
+
+// silkApprox checks the actual value is a number within the
+// tolerance of the expected value.
+func silkApprox(t *testing.T, key string, actual interface{}, expected, tolerance float64) {
+	t.Helper()
+	n, ok := actual.(float64)
+	if s, isString := actual.(string); isString {
+		var err error
+		n, err = strconv.ParseFloat(strings.TrimSpace(s), 64)
+		ok = err == nil
+	}
+	if !ok || n < expected-tolerance || n > expected+tolerance {
+		t.Errorf("%s expected %v ± %v  actual %v", key, expected, tolerance, actual)
+	}
+}
+
+// silkGet gets the value at the path (like .field[0].name) in the data.
+func silkGet(data interface{}, path string) interface{} {
+	v, _ := silkLookup(data, path)
+	return v
+}
+
+// silkLookup gets the value at the path in the data, and whether
+// it is present.
+func silkLookup(data interface{}, path string) (interface{}, bool) {
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '.' || r == '[' || r == ']' }) {
+		switch d := data.(type) {
+		case map[string]interface{}:
+			v, ok := d[part]
+			if !ok {
+				return nil, false
+			}
+			data = v
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(d) {
+				return nil, false
+			}
+			data = d[i]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// silkValues gets the values of a header as a list.
+func silkValues(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// silkCookie gets the value (if attr is empty) or attribute of the
+// last cookie with the name set by the response.
+func silkCookie(res *http.Response, name, attr string) interface{} {
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		return nil
+	}
+	switch attr {
+	case "Path":
+		return cookie.Path
+	case "Domain":
+		return cookie.Domain
+	case "Secure":
+		return cookie.Secure
+	case "HttpOnly":
+		return cookie.HttpOnly
+	case "SameSite":
+		return map[http.SameSite]string{http.SameSiteLaxMode: "Lax", http.SameSiteStrictMode: "Strict", http.SameSiteNoneMode: "None"}[cookie.SameSite]
+	case "MaxAge":
+		return float64(cookie.MaxAge)
+	case "Expires":
+		if cookie.RawExpires == "" {
+			return ""
+		}
+		return cookie.Expires.UTC().Format(http.TimeFormat)
+	}
+	return cookie.Value
+}
+
+// silkJSON decodes a JSON value.
+func silkJSON(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		panic(err)
+	}
+	return v
+}
+`
@@ -0,0 +1,109 @@
+package gotest_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/export/gotest"
+	"github.com/matryer/silk/parse"
+)
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.Parse("comments.silk.md", strings.NewReader("# Comments\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* Repeat: 2\n"+
+		"* ?pretty=true\n"+
+		"\n"+
+		"```\n"+
+		"{\"name\":\"Mat\"}\n"+
+		"```\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"* Data.id: /[0-9]+/\n"+
+		"* Data.tags: [\"new\"]\n"+
+		"* Data.total: 10.5 ± 0.01\n"+
+		"* Data.created: (time) within 1m of now\n"+
+		"* Data.deleted: (null)\n"+
+		"* Data.password: (missing)\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Location: \"/comments/1\"\n"+
+		"* BodySize: < 1024\n"+
+		"* X-Debug: (absent)\n"+
+		"* Set-Cookie[1]: \"b=2\"\n"+
+		"* Vary: [\"Accept\", \"Origin\"]\n"+
+		"* content-type: (ignorecase) \"TEXT/PLAIN\"\n"+
+		"* Cookie.session.HttpOnly: true\n"+
+		"\n"+
+		"## GET /comments/1\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"```json Data.author\n"+
+		"{\"name\": \"Mat\"}\n"+
+		"```\n"+
+		"\n"+
+		"## PUT /avatar\n"+
+		"\n"+
+		"```base64\n"+
+		"iVBORw0KGgo=\n"+
+		"```\n"))
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(gotest.Generate(&buf, "api_test", groups...))
+	src := buf.String()
+	is.NoErr(typeCheck(src))
+	is.True(strings.Contains(src, "package api_test\n"))
+	is.True(strings.Contains(src, "\t\"io/ioutil\"\n"))
+	is.True(strings.Contains(src, "func TestComments_POSTComments(t *testing.T) {\n"))
+	is.True(strings.Contains(src, "func TestComments_POSTComments2(t *testing.T) {\n"))
+	is.True(strings.Contains(src, `req, err := http.NewRequest("POST", *silkURL+"/comments", strings.NewReader("{\"name\":\"Mat\"}"))`))
+	is.True(strings.Contains(src, `req.Header.Add("Content-Type", "application/json")`))
+	is.False(strings.Contains(src, `"Repeat"`))
+	is.True(strings.Contains(src, `q.Add("pretty", "true")`))
+	is.True(strings.Contains(src, `silkAssert(t, "Status", float64(res.StatusCode), float64(201))`))
+	is.True(strings.Contains(src, `silkAssert(t, "Data.id", silkGet(data, ".id"), "/[0-9]+/")`))
+	is.True(strings.Contains(src, `silkAssert(t, "Data.tags", silkGet(data, ".tags"), silkJSON("[\"new\"]"))`))
+	is.True(strings.Contains(src, `silkApprox(t, "Data.total", silkGet(data, ".total"), 10.5, 0.01)`))
+	is.True(strings.Contains(src, `if v, ok := silkLookup(data, ".deleted"); !ok || v != nil {`))
+	is.True(strings.Contains(src, `if v, ok := silkLookup(data, ".password"); ok {`))
+	is.True(strings.Contains(src, "// Data.created: (time) within 1m of now is not checked\n"))
+	is.True(strings.Contains(src, `silkAssert(t, "Location", res.Header.Get("Location"), "/comments/1")`))
+	is.True(strings.Contains(src, "if n := len(body); !(n < 1024) {\n"))
+	is.True(strings.Contains(src, `if v, ok := res.Header["X-Debug"]; ok {`))
+	is.True(strings.Contains(src, `if v := res.Header.Get("content-type"); !strings.EqualFold(v, "TEXT/PLAIN") {`))
+	is.True(strings.Contains(src, `silkAssert(t, "Cookie.session.HttpOnly", silkCookie(res, "session", "HttpOnly"), true)`))
+	is.True(strings.Contains(src, `silkAssert(t, "Set-Cookie[1]", silkGet(silkValues(res.Header["Set-Cookie"]), "[1]"), "b=2")`))
+	is.True(strings.Contains(src, `silkAssert(t, "Data.author", silkGet(data, ".author"), silkJSON("{\"name\":\"Mat\"}"))`))
+	is.True(strings.Contains(src, `req, err := http.NewRequest("PUT", *silkURL+"/avatar", strings.NewReader("\x89PNG\r\n\x1a\n"))`))
+	is.True(strings.Contains(src, `silkAssert(t, "Vary", silkValues(res.Header["Vary"]), silkJSON("[\"Accept\",\"Origin\"]"))`))
+}
+
+// typeCheck parses and type checks the generated source, so
+// generated code that wouldn't compile fails the test.
+func typeCheck(src string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "api_test.go", src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("api_test", fset, []*ast.File{f}, nil)
+	return err
+}
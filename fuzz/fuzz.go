@@ -0,0 +1,220 @@
+// Package fuzz mutates the bodies and parameters of the requests
+// in silk files, with invalid JSON, boundary numbers, long strings
+// and injection strings, and reports the requests that the API
+// responds to with a server error (5xx).
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// payloads are the values that fields and parameters are replaced
+// with.
+var payloads = []interface{}{
+	0.0,
+	-1.0,
+	2147483648.0,
+	-2147483649.0,
+	1e308,
+	-1e308,
+	"",
+	strings.Repeat("A", 10000),
+	"' OR '1'='1",
+	"\"; DROP TABLE users; --",
+	"<script>alert(1)</script>",
+	"../../../../etc/passwd",
+	"\x00",
+	"😀",
+	true,
+	nil,
+	[]interface{}{},
+	map[string]interface{}{},
+}
+
+// Run runs the requests in the groups, each followed by its mutants,
+// with a Runner from newRunner, and gets the Report.
+// The original requests are run (and their values captured) so that
+// later requests work as they normally would, but only server errors
+// are reported, whichever request causes them. The Runner's
+// Reporter, Log and Verbose are replaced, and it continues after
+// failures.
+// Fuzzing changes data, so only fuzz disposable environments.
+func Run(ctx context.Context, newRunner func(runner.T) *runner.Runner, groups ...*parse.Group) *Report {
+	c := &collector{}
+	r := newRunner(nopT{})
+	r.Log = func(string) {}
+	r.Verbose = func(...interface{}) {}
+	r.Reporter = c
+	r.ContinueOnFailure = true
+	fuzzed := make([]*parse.Group, len(groups))
+	for i, group := range groups {
+		g := *group
+		g.Requests = nil
+		for _, req := range group.Requests {
+			g.Requests = append(g.Requests, req)
+			g.Requests = append(g.Requests, mutants(req)...)
+		}
+		fuzzed[i] = &g
+	}
+	r.RunGroupContext(ctx, fuzzed...)
+	return c.report()
+}
+
+// Report is the outcome of fuzzing.
+type Report struct {
+	// Requests is the number of requests made.
+	Requests int
+	// Failures are the results of the requests that the API
+	// responded to with a server error.
+	Failures []*runner.Result
+}
+
+// Write writes the failures, and a summary.
+func (r *Report) Write(w io.Writer) error {
+	for _, result := range r.Failures {
+		fmt.Fprintf(w, "%s:%d: %s %s - %d\n", result.Filename, result.RequestLine, result.Method, result.URL, result.StatusCode)
+		if result.RequestBody != "" {
+			fmt.Fprintf(w, "  body: %s\n", abbreviate(result.RequestBody))
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d request(s), %d server error(s)\n", r.Requests, len(r.Failures))
+	return err
+}
+
+// abbreviate shortens long strings, like the long string payload.
+func abbreviate(s string) string {
+	const max = 200
+	if len(s) <= max {
+		return s
+	}
+	return fmt.Sprintf("%s... (%d bytes)", s[:max], len(s))
+}
+
+// mutants gets copies of the request with its body or one of its
+// parameters changed, without its assertions.
+func mutants(req *parse.Request) []*parse.Request {
+	var list []*parse.Request
+	mutant := func(body parse.Lines, params parse.Lines) {
+		m := *req
+		m.Body, m.Params = body, params
+		m.ExpectedBody, m.ExpectedDetails, m.ExpectedData = nil, nil, nil
+		list = append(list, &m)
+	}
+	for i, line := range req.Params {
+		key := line.Detail().Key
+		for _, payload := range payloads {
+			s, ok := paramValue(payload)
+			if !ok {
+				continue
+			}
+			param, err := parse.ParseLine(line.Number, []byte("* ?"+key+"="+s))
+			if err != nil {
+				continue
+			}
+			params := append(parse.Lines(nil), req.Params...)
+			params[i] = param
+			mutant(req.Body, params)
+		}
+	}
+	if len(req.Body) == 0 || req.BodyLang == "base64" {
+		return list
+	}
+	number := req.Body.Number()
+	for _, body := range bodies(req.Body.String()) {
+		mutant(parse.Lines{{Number: number, Type: parse.LineTypePlain, Bytes: []byte(body)}}, req.Params)
+	}
+	return list
+}
+
+// paramValue gets the payload as the value of a parameter, and
+// whether it can be one.
+func paramValue(payload interface{}) (string, bool) {
+	switch p := payload.(type) {
+	case string:
+		return p, true
+	case float64, bool:
+		b, _ := json.Marshal(p)
+		return string(b), true
+	}
+	return "", false
+}
+
+// bodies gets the mutated bodies. JSON objects get each of their
+// fields replaced with each payload in turn, and other bodies are
+// replaced with the strings.
+func bodies(body string) []string {
+	var list []string
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &obj); err == nil && obj != nil {
+		list = append(list, body[:len(body)/2], "[]")
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, payload := range payloads {
+				m := make(map[string]interface{}, len(obj))
+				for k, v := range obj {
+					m[k] = v
+				}
+				m[k] = payload
+				b, err := json.Marshal(m)
+				if err != nil {
+					continue
+				}
+				list = append(list, string(b))
+			}
+		}
+		return list
+	}
+	for _, payload := range payloads {
+		if s, ok := payload.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// collector is a runner.Reporter that collects the results of
+// server errors.
+type collector struct {
+	mu       sync.Mutex
+	requests int
+	failures []*runner.Result
+}
+
+func (c *collector) Report(result *runner.Result) {
+	if result.URL == "" {
+		// the request wasn't made
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if result.StatusCode >= 500 {
+		c.failures = append(c.failures, result)
+	}
+}
+
+func (c *collector) report() *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &Report{Requests: c.requests, Failures: c.failures}
+}
+
+// nopT is a runner.T that ignores failures, since only server
+// errors are reported.
+type nopT struct{}
+
+func (nopT) FailNow()           {}
+func (nopT) Log(...interface{}) {}
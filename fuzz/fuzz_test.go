@@ -0,0 +1,60 @@
+package fuzz_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/fuzz"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	var gets int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			gets++
+			return
+		}
+		var user struct {
+			Name string
+			Age  int
+		}
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(user.Name) > 100 {
+			// a bug
+			http.Error(w, "name too long", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer s.Close()
+	groups, err := parse.ParseFile("../testfiles/fuzz/fuzz.silk.md")
+	is.NoErr(err)
+	newRunner := func(t runner.T) *runner.Runner {
+		return runner.New(t, s.URL)
+	}
+	report := fuzz.Run(context.Background(), newRunner, groups...)
+	is.True(report.Requests > 40)
+	// requests without a body or parameters aren't mutated
+	is.Equal(gets, 1)
+	is.Equal(len(report.Failures), 1)
+	is.Equal(report.Failures[0].StatusCode, 500)
+	is.True(strings.Contains(report.Failures[0].RequestBody, `"name":"AAAA`))
+
+	var buf bytes.Buffer
+	is.NoErr(report.Write(&buf))
+	is.True(strings.Contains(buf.String(), "../testfiles/fuzz/fuzz.silk.md:3: POST "+s.URL+"/users?source=signup - 500\n"))
+	is.True(strings.Contains(buf.String(), "... (10020 bytes)\n"))
+	is.True(strings.Contains(buf.String(), " request(s), 1 server error(s)\n"))
+}
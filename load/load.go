@@ -0,0 +1,215 @@
+// Package load runs the requests in silk files over and over, from
+// many workers at once, to generate load, and reports the latency
+// and error rate of each request rather than whether it passed.
+package load
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// Options control how much load is generated.
+type Options struct {
+	// Concurrency is the number of workers running the requests
+	// at the same time. The default is 1.
+	Concurrency int
+	// Iterations is the number of times each worker runs the
+	// groups. The default is 1.
+	Iterations int
+	// Duration, if set, stops the workers after this long, even
+	// if they haven't finished their iterations.
+	Duration time.Duration
+}
+
+// Run runs the groups with the options, and gets the Report.
+// Each worker gets its own Runner from newRunner. The Runner's
+// Reporter, Log and Verbose are replaced, and it continues after
+// failures, which are counted as errors.
+func Run(ctx context.Context, newRunner func(runner.T) *runner.Runner, opts Options, groups ...*parse.Group) *Report {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Iterations < 1 {
+		opts.Iterations = 1
+	}
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+	c := &collector{stats: make(map[string]*Stats)}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := newRunner(nopT{})
+			r.Log = func(string) {}
+			r.Verbose = func(...interface{}) {}
+			r.Reporter = c
+			r.ContinueOnFailure = true
+			for n := 0; n < opts.Iterations && ctx.Err() == nil; n++ {
+				r.RunGroupContext(ctx, groups...)
+			}
+		}()
+	}
+	wg.Wait()
+	return c.report(time.Since(start))
+}
+
+// Report is the outcome of a load test.
+type Report struct {
+	// Duration is how long the load test took.
+	Duration time.Duration
+	// Requests are the Stats of each request, in the order they
+	// were first made.
+	Requests []*Stats
+	// Total are the Stats of every request.
+	Total *Stats
+}
+
+// Stats are the latencies and errors of a request.
+type Stats struct {
+	// Name describes the request, like GET /users (users.silk.md:12).
+	Name string
+	// Count is the number of times the request was made, and
+	// Errors the number of times it failed.
+	Count  int
+	Errors int
+	// Min, Mean and Max are the latencies of the requests, and
+	// P50, P90, P95 and P99 their percentiles.
+	Min, Mean, Max     time.Duration
+	P50, P90, P95, P99 time.Duration
+
+	durations []time.Duration
+}
+
+// ErrorRate gets the fraction of the requests that failed.
+func (s *Stats) ErrorRate() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Count)
+}
+
+// add adds the duration of a request to the Stats.
+func (s *Stats) add(d time.Duration, failed bool) {
+	s.Count++
+	if failed {
+		s.Errors++
+	}
+	s.durations = append(s.durations, d)
+}
+
+// calculate calculates the latencies from the durations.
+func (s *Stats) calculate() {
+	if len(s.durations) == 0 {
+		return
+	}
+	sort.Slice(s.durations, func(i, j int) bool {
+		return s.durations[i] < s.durations[j]
+	})
+	var total time.Duration
+	for _, d := range s.durations {
+		total += d
+	}
+	s.Min = s.durations[0]
+	s.Max = s.durations[len(s.durations)-1]
+	s.Mean = total / time.Duration(len(s.durations))
+	s.P50 = percentile(s.durations, 50)
+	s.P90 = percentile(s.durations, 90)
+	s.P95 = percentile(s.durations, 95)
+	s.P99 = percentile(s.durations, 99)
+}
+
+// percentile gets the pth percentile of the sorted durations,
+// using the nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Write writes the report as a table.
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "request\tcount\terrors\tmin\tmean\tp50\tp90\tp95\tp99\tmax\t")
+	for _, s := range append(r.Requests, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", s.Name, s.Count,
+			strconv.FormatFloat(s.ErrorRate()*100, 'f', 1, 64)+"%",
+			round(s.Min), round(s.Mean), round(s.P50), round(s.P90), round(s.P95), round(s.P99), round(s.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	rps := 0.0
+	if r.Duration > 0 {
+		rps = float64(r.Total.Count) / r.Duration.Seconds()
+	}
+	_, err := fmt.Fprintf(w, "%d request(s) in %s (%.1f/s)\n", r.Total.Count, round(r.Duration), rps)
+	return err
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond * 100)
+}
+
+// collector is a runner.Reporter that collects the Stats of the
+// requests.
+type collector struct {
+	mu    sync.Mutex
+	order []string
+	stats map[string]*Stats
+	total Stats
+}
+
+func (c *collector) Report(result *runner.Result) {
+	if result.Outcome == runner.Skipped {
+		return
+	}
+	name := fmt.Sprintf("%s %s (%s:%d)", result.Method, result.Path, result.Filename, result.RequestLine)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.stats[name]
+	if !ok {
+		s = &Stats{Name: name}
+		c.stats[name] = s
+		c.order = append(c.order, name)
+	}
+	failed := result.Outcome == runner.Failed
+	s.add(result.Duration, failed)
+	c.total.add(result.Duration, failed)
+}
+
+func (c *collector) report(d time.Duration) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	report := &Report{Duration: d, Total: &c.total}
+	report.Total.Name = "total"
+	for _, name := range c.order {
+		s := c.stats[name]
+		s.calculate()
+		report.Requests = append(report.Requests, s)
+	}
+	report.Total.calculate()
+	return report
+}
+
+// nopT is a runner.T that ignores failures, since they are counted
+// as errors instead.
+type nopT struct{}
+
+func (nopT) FailNow()           {}
+func (nopT) Log(...interface{}) {}
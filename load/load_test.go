@@ -0,0 +1,43 @@
+package load_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/load"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	groups, err := parse.ParseFile("../testfiles/success/bodysize.silk.md", "../testfiles/failure/echo.failure.bodysize.silk.md")
+	is.NoErr(err)
+	newRunner := func(t runner.T) *runner.Runner {
+		return runner.New(t, s.URL)
+	}
+	report := load.Run(context.Background(), newRunner, load.Options{Concurrency: 4, Iterations: 5}, groups...)
+	is.Equal(len(report.Requests), 2)
+	is.Equal(report.Requests[0].Name, "GET /echo (../testfiles/success/bodysize.silk.md:3)")
+	is.Equal(report.Requests[0].Count, 20)
+	is.Equal(report.Requests[0].Errors, 0)
+	is.Equal(report.Requests[1].Count, 20)
+	is.Equal(report.Requests[1].ErrorRate(), 1.0)
+	is.Equal(report.Total.Count, 40)
+	is.Equal(report.Total.Errors, 20)
+	is.True(report.Total.Min <= report.Total.P50)
+	is.True(report.Total.P50 <= report.Total.P99)
+	is.True(report.Total.P99 <= report.Total.Max)
+
+	var buf bytes.Buffer
+	is.NoErr(report.Write(&buf))
+	is.True(strings.Contains(buf.String(), "100.0%"))
+	is.True(strings.Contains(buf.String(), "40 request(s) in "))
+}
@@ -20,6 +20,13 @@ import (
 var (
 	showVersion = flag.Bool("version", false, "show version and exit")
 	url         = flag.String("silk.url", "", "(required) target url")
+	tags        = flag.String("silk.tags", "", "only run requests with these tags (e.g. \"smoke !slow\")")
+	run         = flag.String("silk.run", "", "only run groups and requests (like \"GET /users\") matching this regexp")
+	printCurl   = flag.Bool("silk.print-curl", false, "print the curl command for each request")
+	escapePath  = flag.Bool("silk.escape-path", false, "escape {name} values in request paths")
+	continueOn  = flag.Bool("silk.continue", false, "keep running after a request fails")
+	onFailure   = flag.String("silk.on-failure", "fail-fast", "what to do when a request fails: fail-fast, finish-group or run-all")
+	shuffle     = flag.Bool("silk.shuffle", false, "run the groups in a random order")
 	help        = flag.Bool("help", false, "show help")
 	root        string
 )
@@ -61,6 +68,17 @@ func main() {
 
 func testFunc(t *testing.T) {
 	r := runner.New(t, *url)
+	r.Tags = *tags
+	r.Run = *run
+	r.Shuffle = *shuffle
+	r.PrintCurl = *printCurl
+	r.EscapePathVars = *escapePath
+	r.ContinueOnFailure = *continueOn
+	policy, err := runner.ParsePolicy(*onFailure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.OnFailure = policy
 	files, err := filepath.Glob(root)
 	if err != nil {
 		log.Fatalln(err)
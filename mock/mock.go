@@ -0,0 +1,288 @@
+// Package mock serves the responses described in Silk files, so
+// clients can be developed against the documented behaviour of
+// an API without the real service.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// Handler is an http.Handler that answers requests with the
+// responses described by the requests in silk files.
+type Handler struct {
+	groups []*parse.Group
+	// Log, if set, is called with a description of each request
+	// and the request from the silk files that answered it.
+	Log func(string)
+}
+
+// New makes a Handler that answers requests using the groups.
+func New(groups ...*parse.Group) *Handler {
+	return &Handler{groups: groups}
+}
+
+// ServeHTTP responds with the first request in the silk files
+// whose method, path and parameters match the request.
+// Path segments containing variables (like {id}) match any value.
+// The status, headers and body are taken from the expected response.
+// If there is no expected body, one is made from the Data
+// assertions and data blocks. Regex assertions are ignored.
+// Requests that don't match any request get a 404 Not Found.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	group, req := h.match(r)
+	if req == nil {
+		h.log(r.Method, r.URL.RequestURI(), "(no match)")
+		http.Error(w, fmt.Sprintf("silk: no request matches %s %s", r.Method, r.URL.Path), http.StatusNotFound)
+		return
+	}
+	h.log(r.Method, r.URL.RequestURI(), "-", fmt.Sprintf("%s:%d", group.Filename, req.Number))
+	body, _, err := runner.ExpectedBody(group, req)
+	if err != nil {
+		h.log(err)
+		http.Error(w, "silk: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	setCookies(w, req)
+	status := http.StatusOK
+	data := make(map[string]interface{})
+	for _, line := range req.ExpectedDetails {
+		detail := line.Detail()
+		if runner.IsResponseDirective(detail.Key) {
+			continue
+		}
+		e, err := runner.Expect(detail)
+		if err != nil {
+			continue
+		}
+		example, ok := e.Example()
+		if !ok {
+			continue
+		}
+		switch e.Subject {
+		case runner.SubjectStatus:
+			if n, ok := example.(float64); ok {
+				status = int(n)
+			}
+		case runner.SubjectData:
+			if e.Name == "" {
+				// the whole body is asserted
+				if body == nil {
+					writeJSON(w, status, example)
+					return
+				}
+				continue
+			}
+			set(data, strings.TrimPrefix(e.Name, "."), example)
+		case runner.SubjectHeader:
+			if http.CanonicalHeaderKey(e.Name) == "Content-Length" {
+				continue
+			}
+			if e.Index >= 0 {
+				// one of the values of a repeated header
+				w.Header().Add(e.Name, fmt.Sprintf("%v", example))
+				continue
+			}
+			if values, ok := example.([]interface{}); ok {
+				for _, value := range values {
+					w.Header().Add(e.Name, fmt.Sprintf("%v", value))
+				}
+				continue
+			}
+			w.Header().Set(e.Name, fmt.Sprintf("%v", example))
+		}
+	}
+	for _, block := range req.ExpectedData {
+		var v interface{}
+		if err := json.Unmarshal([]byte(block.Lines.String()), &v); err != nil {
+			continue
+		}
+		switch {
+		case block.Key == "Data":
+			if body == nil {
+				writeJSON(w, status, v)
+				return
+			}
+		case strings.HasPrefix(block.Key, "Data."):
+			set(data, strings.TrimPrefix(block.Key, "Data."), v)
+		}
+	}
+	if body == nil && len(data) > 0 {
+		writeJSON(w, status, data)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (h *Handler) log(args ...interface{}) {
+	if h.Log != nil {
+		h.Log(fmt.Sprintln(args...))
+	}
+}
+
+// match gets the first request matching r.
+func (h *Handler) match(r *http.Request) (*parse.Group, *parse.Request) {
+	for _, group := range h.groups {
+		for _, requests := range [][]*parse.Request{group.Setup, group.Requests, group.Teardown} {
+			for _, req := range requests {
+				if matches(req, r) {
+					return group, req
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+func matches(req *parse.Request, r *http.Request) bool {
+	if !strings.EqualFold(string(req.Method), r.Method) {
+		return false
+	}
+	path := string(req.Path)
+	if i := strings.Index(path, "?"); i != -1 {
+		path = path[:i]
+	}
+	expected := strings.Split(strings.Trim(path, "/"), "/")
+	actual := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if strings.Contains(expected[i], "{") {
+			continue
+		}
+		if expected[i] != actual[i] {
+			return false
+		}
+	}
+	q := r.URL.Query()
+	for _, line := range req.Params {
+		detail := line.Detail()
+		for _, value := range runner.ParamValues(detail) {
+			if strings.Contains(value, "{") {
+				if _, ok := q[detail.Key]; !ok {
+					return false
+				}
+				continue
+			}
+			if !contains(q[detail.Key], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// contains gets whether the value is one of the values of a
+// repeated parameter.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// dataPathRegex matches the field names and [n] indexes in the
+// path of a Data assertion, like items[0].id.
+var dataPathRegex = regexp.MustCompile(`[^.\[\]]+|\[[0-9]+\]`)
+
+// set sets the value at the path in data, making the objects and
+// arrays on the way to it.
+func set(data map[string]interface{}, path string, value interface{}) {
+	setPath(data, dataPathRegex.FindAllString(path, -1), value)
+}
+
+// setPath sets the value at the path in v, and gets v, or the
+// object or array made to hold the value if v isn't one.
+func setPath(v interface{}, path []string, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	if strings.HasPrefix(path[0], "[") {
+		i, _ := strconv.Atoi(path[0][1 : len(path[0])-1])
+		list, _ := v.([]interface{})
+		for len(list) <= i {
+			list = append(list, nil)
+		}
+		list[i] = setPath(list[i], path[1:], value)
+		return list
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		obj = make(map[string]interface{})
+	}
+	obj[path[0]] = setPath(obj[path[0]], path[1:], value)
+	return obj
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// setCookies sets the cookies described by the Cookie assertions.
+func setCookies(w http.ResponseWriter, req *parse.Request) {
+	var cookies []*http.Cookie
+	byName := make(map[string]*http.Cookie)
+	for _, line := range req.ExpectedDetails {
+		e, err := runner.Expect(line.Detail())
+		if err != nil || e.Subject != runner.SubjectCookie {
+			continue
+		}
+		value, ok := e.Example()
+		if !ok {
+			continue
+		}
+		cookie, ok := byName[e.Name]
+		if !ok {
+			cookie = &http.Cookie{Name: e.Name}
+			byName[e.Name] = cookie
+			cookies = append(cookies, cookie)
+		}
+		switch e.Attr {
+		case "":
+			cookie.Value = fmt.Sprintf("%v", value)
+		case "Path":
+			cookie.Path = fmt.Sprintf("%v", value)
+		case "Domain":
+			cookie.Domain = fmt.Sprintf("%v", value)
+		case "Secure":
+			cookie.Secure = value == true
+		case "HttpOnly":
+			cookie.HttpOnly = value == true
+		case "SameSite":
+			switch value {
+			case "Lax":
+				cookie.SameSite = http.SameSiteLaxMode
+			case "Strict":
+				cookie.SameSite = http.SameSiteStrictMode
+			case "None":
+				cookie.SameSite = http.SameSiteNoneMode
+			}
+		case "MaxAge":
+			if n, ok := value.(float64); ok {
+				cookie.MaxAge = int(n)
+			}
+		case "Expires":
+			if t, err := http.ParseTime(fmt.Sprintf("%v", value)); err == nil {
+				cookie.Expires = t
+			}
+		}
+	}
+	for _, cookie := range cookies {
+		http.SetCookie(w, cookie)
+	}
+}
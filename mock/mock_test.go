@@ -0,0 +1,71 @@
+package mock_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/mock"
+	"github.com/matryer/silk/parse"
+)
+
+func TestHandler(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/mock/mock.silk.md")
+	is.NoErr(err)
+	s := httptest.NewServer(mock.New(groups...))
+	defer s.Close()
+
+	for _, test := range []struct {
+		method, path string
+		status       int
+		contentType  string
+		body         string
+	}{
+		{"GET", "/comments?pretty=true", 200, "text/plain", "pretty comments"},
+		{"GET", "/comments", 200, "text/plain", "comments"},
+		{"GET", "/comments/123", 200, "application/json", `{"author":{"links":["a","b"],"name":"Mat"},"deleted":null,"id":"123","replies":[null,{"id":"r2"}],"score":4.5}` + "\n"},
+		{"DELETE", "/comments/123", 204, "", ""},
+		{"POST", "/comments", 404, "text/plain; charset=utf-8", "silk: no request matches POST /comments\n"},
+		{"GET", "/comments/123/replies", 404, "text/plain; charset=utf-8", "silk: no request matches GET /comments/123/replies\n"},
+	} {
+		req, err := http.NewRequest(test.method, s.URL+test.path, nil)
+		is.NoErr(err)
+		res, err := http.DefaultClient.Do(req)
+		is.NoErr(err)
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		is.NoErr(err)
+		is.Equal(res.StatusCode, test.status)
+		is.Equal(res.Header.Get("Content-Type"), test.contentType)
+		is.Equal(string(body), test.body)
+	}
+
+	// repeated headers
+	req, err := http.NewRequest("DELETE", s.URL+"/comments/123", nil)
+	is.NoErr(err)
+	res, err := http.DefaultClient.Do(req)
+	is.NoErr(err)
+	res.Body.Close()
+	is.Equal(res.Header["Vary"], []string{"Accept", "Origin"})
+	is.Equal(res.Header["Set-Cookie"], []string{"session=abc; HttpOnly", "a=1"})
+	is.Equal(len(res.Header["X-Debug"]), 0)
+}
+
+func TestHandlerBinary(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/binary/binary.silk.md")
+	is.NoErr(err)
+	s := httptest.NewServer(mock.New(groups...))
+	defer s.Close()
+	res, err := http.Post(s.URL+"/echo", "image/png", strings.NewReader("\x89PNG\r\n\x1a\n"))
+	is.NoErr(err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, 200)
+	is.Equal(body, []byte("\x89PNG\r\n\x1a\n"))
+}
@@ -0,0 +1,126 @@
+package parse
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// headingRegex matches Markdown headings of any level.
+var headingRegex = regexp.MustCompile(`^#{1,6}\s`)
+
+// Format formats silk source into the canonical style:
+//
+//   - Headings and separators are surrounded by a single blank line
+//   - Separators are written as ===
+//   - Details and parameters are unindented, and use the * bullet
+//   - Code fence languages are lowercase (the Data paths of data
+//     blocks are left alone)
+//   - Repeated blank lines and trailing whitespace are removed
+//
+// Comments, plain text, front matter and code block contents are
+// left untouched.
+func Format(src []byte) ([]byte, error) {
+	var out []string
+	inCodeblock, inFrontMatter := false, false
+	blank := false // whether a blank line is needed before the next line
+	n := 0
+	for _, raw := range strings.Split(strings.Replace(string(src), "\r\n", "\n", -1), "\n") {
+		n++
+		if n == 1 && isFrontMatterDelim([]byte(raw)) {
+			out = append(out, frontMatterDelim)
+			inFrontMatter = true
+			continue
+		}
+		if inFrontMatter {
+			if isFrontMatterDelim([]byte(raw)) {
+				out = append(out, frontMatterDelim)
+				inFrontMatter = false
+				blank = true
+				continue
+			}
+			out = append(out, raw)
+			continue
+		}
+		if inCodeblock {
+			if strings.HasPrefix(raw, "```") {
+				out = append(out, "```")
+				inCodeblock = false
+				continue
+			}
+			out = append(out, raw)
+			continue
+		}
+		text := strings.TrimRight(raw, " \t")
+		if text == "" {
+			blank = len(out) > 0
+			continue
+		}
+		content, comment := text, ""
+		if i := strings.Index(text, string(commentPrefix)); i != -1 {
+			content, comment = text[:i], text[i+len(commentPrefix):]
+		}
+		line, err := ParseLine(n, []byte(content))
+		if err != nil {
+			return nil, err
+		}
+		surround := false
+		switch line.Type {
+		case LineTypeSeparator:
+			content, surround = "===", true
+		case LineTypeCodeBlock:
+			info := strings.Fields(strings.TrimPrefix(content, "```"))
+			if len(info) > 0 {
+				info[0] = strings.ToLower(info[0])
+			}
+			content = "```" + strings.Join(info, " ")
+			inCodeblock = true
+		case LineTypeDetail:
+			content = formatDetail(content)
+		case LineTypeParam:
+			content = "* " + strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "*"))
+		default:
+			if headingRegex.MatchString(content) {
+				content, surround = strings.Join(strings.Fields(content), " "), true
+			} else {
+				// plain text is left alone, including comments
+				content, comment = text, ""
+			}
+		}
+		if comment != "" {
+			content = strings.TrimRight(content, " \t") + string(commentPrefix) + " " + strings.TrimSpace(comment)
+		}
+		if len(out) > 0 && (blank || surround) {
+			out = append(out, "")
+		}
+		out = append(out, content)
+		blank = surround
+	}
+	if inCodeblock {
+		return nil, &ErrLine{N: n, Err: errMissingEndCodeblock}
+	}
+	if inFrontMatter {
+		return nil, &ErrLine{N: 1, Err: errMissingEndFrontMatter}
+	}
+	var buf bytes.Buffer
+	for _, line := range out {
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDetail formats a detail line as "* Key: value".
+func formatDetail(content string) string {
+	content = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(content), "*"))
+	sep := strings.IndexAny(content, ":=")
+	if sep == -1 {
+		return "* " + content
+	}
+	key := strings.TrimSpace(content[:sep])
+	value := strings.TrimSpace(content[sep+1:])
+	if value == "" {
+		return "* " + key + ":"
+	}
+	return "* " + key + ": " + value
+}
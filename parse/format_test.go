@@ -0,0 +1,115 @@
+package parse_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+)
+
+func TestFormat(t *testing.T) {
+	is := is.New(t)
+	src := []byte("\n\n#   Comments  \n" +
+		"* Root: \"http://localhost:8080/\"\n" +
+		"## POST    /comments @smoke\n" +
+		"Create a comment.  \n" +
+		"\n\n\n" +
+		"```JSON\n" +
+		"{  \n" +
+		"  \"name\": \"Mat\"\n" +
+		"}\n" +
+		"```\n" +
+		"  *   `Content-Type`:\"application/json\"   //   ensure correct type\n" +
+		"  - not a detail\n" +
+		"  * ?pretty=true\n" +
+		"-----\n" +
+		"* Status=201\n" +
+		"* Data.id:\n" +
+		"```JSON  Data.user\n" +
+		"{}\n" +
+		"```\n")
+	expected := "# Comments\n" +
+		"\n" +
+		"* Root: \"http://localhost:8080/\"\n" +
+		"\n" +
+		"## POST /comments @smoke\n" +
+		"\n" +
+		"Create a comment.\n" +
+		"\n" +
+		"```json\n" +
+		"{  \n" +
+		"  \"name\": \"Mat\"\n" +
+		"}\n" +
+		"```\n" +
+		"* `Content-Type`: \"application/json\" // ensure correct type\n" +
+		"  - not a detail\n" +
+		"* ?pretty=true\n" +
+		"\n" +
+		"===\n" +
+		"\n" +
+		"* Status: 201\n" +
+		"* Data.id:\n" +
+		"```json Data.user\n" +
+		"{}\n" +
+		"```\n"
+	actual, err := parse.Format(src)
+	is.NoErr(err)
+	is.Equal(string(actual), expected)
+
+	// formatting is stable
+	again, err := parse.Format(actual)
+	is.NoErr(err)
+	is.Equal(string(again), expected)
+
+	_, err = parse.Format([]byte("# Group\n## GET /\n```\nnever ends"))
+	is.Err(err)
+
+	// front matter is left alone
+	actual, err = parse.Format([]byte("---\nheaders:\n  Accept:   text/plain\n---\n# Group\n"))
+	is.NoErr(err)
+	is.Equal(string(actual), "---\nheaders:\n  Accept:   text/plain\n---\n\n# Group\n")
+	_, err = parse.Format([]byte("---\n# Group\n"))
+	is.Err(err)
+}
+
+func TestFormatPreservesMeaning(t *testing.T) {
+	is := is.New(t)
+	files, err := filepath.Glob("../testfiles/success/*.silk.md")
+	is.NoErr(err)
+	failures, err := filepath.Glob("../testfiles/failure/*.silk.md")
+	is.NoErr(err)
+	files = append(files, failures...)
+	is.True(len(files) > 0)
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		is.NoErr(err)
+		formatted, err := parse.Format(src)
+		is.NoErr(err)
+		expected, err := parse.Parse(file, bytes.NewReader(src))
+		is.NoErr(err)
+		actual, err := parse.Parse(file, bytes.NewReader(formatted))
+		is.NoErr(err)
+		is.Equal(len(actual), len(expected))
+		for i := range expected {
+			is.Equal(actual[i].Title, expected[i].Title)
+			is.Equal(len(actual[i].Requests), len(expected[i].Requests))
+			for j, req := range expected[i].Requests {
+				is.Equal(actual[i].Requests[j].Method, req.Method)
+				is.Equal(actual[i].Requests[j].Path, req.Path)
+				is.Equal(actual[i].Requests[j].Body.String(), req.Body.String())
+				is.Equal(actual[i].Requests[j].ExpectedBody.String(), req.ExpectedBody.String())
+				is.Equal(len(actual[i].Requests[j].Details), len(req.Details))
+				for k, line := range req.Details {
+					is.Equal(actual[i].Requests[j].Details[k].Detail().String(), line.Detail().String())
+				}
+				is.Equal(len(actual[i].Requests[j].ExpectedDetails), len(req.ExpectedDetails))
+				for k, line := range req.ExpectedDetails {
+					is.Equal(actual[i].Requests[j].ExpectedDetails[k].Detail().String(), line.Detail().String())
+				}
+			}
+		}
+	}
+}
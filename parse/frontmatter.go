@@ -0,0 +1,67 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// frontMatterDelim starts and ends the front matter of a file.
+const frontMatterDelim = "---"
+
+var errMissingEndFrontMatter = errors.New("missing end of front matter")
+
+// FrontMatter is the settings of every group in a file, given in
+// YAML between --- lines at the top of it:
+//
+//	---
+//	url: http://localhost:8081
+//	headers:
+//	  Accept: application/json
+//	tags: [billing]
+//	vars:
+//	  userID: 123
+//	---
+//
+//	# Invoices
+type FrontMatter struct {
+	// URL is the root URL of the requests in the file, instead of
+	// the Runner's.
+	URL string `yaml:"url"`
+	// Headers are sent with every request in the file. Headers of
+	// requests take precedence.
+	Headers map[string]string `yaml:"headers"`
+	// Tags are the tags of every group in the file.
+	Tags []string `yaml:"tags"`
+	// Vars are variables available to every request in the file.
+	Vars map[string]interface{} `yaml:"vars"`
+}
+
+// isFrontMatterDelim gets whether the line starts or ends front
+// matter.
+func isFrontMatterDelim(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t")) == frontMatterDelim
+}
+
+// scanFrontMatter scans the front matter after its opening line n,
+// up to and including its closing line.
+func scanFrontMatter(n int, scanner *bufio.Scanner) (int, *FrontMatter, error) {
+	start := n
+	var src bytes.Buffer
+	for scanner.Scan() {
+		n++
+		if isFrontMatterDelim(scanner.Bytes()) {
+			var fm FrontMatter
+			if err := yaml.UnmarshalStrict(src.Bytes(), &fm); err != nil {
+				return n, nil, &ErrLine{N: start, Err: fmt.Errorf("front matter: %v", err)}
+			}
+			return n, &fm, nil
+		}
+		src.Write(scanner.Bytes())
+		src.WriteByte('\n')
+	}
+	return n, nil, &ErrLine{N: start, Err: errMissingEndFrontMatter}
+}
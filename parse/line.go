@@ -139,6 +139,7 @@ const (
 	LineTypeDetail
 	LineTypeSeparator
 	LineTypeParam
+	LineTypeSection
 )
 
 var lineTypeStrs = map[LineType]string{
@@ -149,6 +150,7 @@ var lineTypeStrs = map[LineType]string{
 	LineTypeDetail:       "detail",
 	LineTypeSeparator:    "separator",
 	LineTypeParam:        "param",
+	LineTypeSection:      "section",
 }
 
 func (l LineType) String() string {
@@ -161,6 +163,10 @@ var matchTypes = []struct {
 	R    string
 	Type LineType
 }{{
+	// ## Setup
+	R:    "^## (Setup|Teardown)\\s*$",
+	Type: LineTypeSection,
+}, {
 	// ## GET /comments
 	R:    "^## (.*) (.*)",
 	Type: LineTypeRequest,
@@ -0,0 +1,99 @@
+package parse
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// Matcher matches actual values with expected values that name it,
+// followed by (), like uuid() or email().
+type Matcher interface {
+	// Match gets whether the actual value matches.
+	Match(actual interface{}) bool
+	// Example gets a value that matches, for mock responses.
+	Example() interface{}
+}
+
+var (
+	matchersLock sync.RWMutex
+	matchers     = map[string]Matcher{
+		"uuid":   regexMatcher{regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`), "123e4567-e89b-12d3-a456-426614174000"},
+		"semver": regexMatcher{regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`), "1.0.0"},
+		"email":  funcMatcher{isEmail, "someone@example.com"},
+		"url":    funcMatcher{isURL, "https://example.com/"},
+		"ip":     funcMatcher{isIP, "192.0.2.1"},
+	}
+)
+
+// RegisterMatcher registers the Matcher for expected values like
+// name(), replacing any Matcher with the name, including the
+// built-in uuid, semver, email, url and ip.
+func RegisterMatcher(name string, m Matcher) {
+	matchersLock.Lock()
+	defer matchersLock.Unlock()
+	matchers[name] = m
+}
+
+// matcherRegex matches expected values that name a matcher.
+var matcherRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\(\)$`)
+
+// LookupMatcher gets the registered Matcher that the expected value
+// names, like uuid(), and whether there is one.
+func LookupMatcher(s string) (Matcher, bool) {
+	match := matcherRegex.FindStringSubmatch(s)
+	if match == nil {
+		return nil, false
+	}
+	matchersLock.RLock()
+	defer matchersLock.RUnlock()
+	m, ok := matchers[match[1]]
+	return m, ok
+}
+
+// regexMatcher matches strings with a regex.
+type regexMatcher struct {
+	regex   *regexp.Regexp
+	example string
+}
+
+func (m regexMatcher) Match(actual interface{}) bool {
+	s, ok := actual.(string)
+	return ok && m.regex.MatchString(s)
+}
+
+func (m regexMatcher) Example() interface{} {
+	return m.example
+}
+
+// funcMatcher matches strings with a func.
+type funcMatcher struct {
+	fn      func(s string) bool
+	example string
+}
+
+func (m funcMatcher) Match(actual interface{}) bool {
+	s, ok := actual.(string)
+	return ok && m.fn(s)
+}
+
+func (m funcMatcher) Example() interface{} {
+	return m.example
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	// only plain addresses, not "Name <address>"
+	return err == nil && addr.Address == s
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestMatchers(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		expected string
+		good     []interface{}
+		bad      []interface{}
+	}{
+		{"uuid()", []interface{}{"123e4567-e89b-12d3-a456-426614174000"}, []interface{}{"123e4567", 1.0, nil}},
+		{"semver()", []interface{}{"1.2.3", "v0.10.0-beta.1+build.5"}, []interface{}{"1.2", "01.2.3", 1.2}},
+		{"email()", []interface{}{"someone@example.com"}, []interface{}{"someone", "Someone <someone@example.com>"}},
+		{"url()", []interface{}{"https://example.com/path?q=1"}, []interface{}{"/path", "example"}},
+		{"ip()", []interface{}{"192.0.2.1", "2001:db8::1"}, []interface{}{"192.0.2", "localhost"}},
+	}
+	for _, test := range tests {
+		v := ParseValue([]byte(test.expected))
+		is.Equal(v.Type(), "matcher")
+		for _, actual := range test.good {
+			is.True(v.Equal(actual))
+		}
+		for _, actual := range test.bad {
+			is.False(v.Equal(actual))
+		}
+		m, ok := LookupMatcher(test.expected)
+		is.True(ok)
+		is.True(m.Match(m.Example()))
+	}
+
+	// unknown names are plain strings
+	v := ParseValue([]byte("nope()"))
+	is.Equal(v.Type(), "string")
+	is.True(v.Equal("nope()"))
+}
+
+type upperMatcher struct{}
+
+func (upperMatcher) Match(actual interface{}) bool {
+	s, ok := actual.(string)
+	return ok && s != "" && s == strings.ToUpper(s)
+}
+
+func (upperMatcher) Example() interface{} {
+	return "SILK"
+}
+
+func TestRegisterMatcher(t *testing.T) {
+	is := is.New(t)
+	RegisterMatcher("upper", upperMatcher{})
+	v := ParseValue([]byte("upper()"))
+	is.True(v.Equal("SILK"))
+	is.False(v.Equal("silk"))
+}
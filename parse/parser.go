@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strings"
 )
 
 var (
@@ -21,19 +23,76 @@ var (
 type Group struct {
 	Filename string
 	Title    []byte
+	Tags     []string
 	Requests []*Request
 	Details  Lines
+	// Setup are the requests to run before the group's Requests.
+	Setup []*Request
+	// Teardown are the requests to run after the group's Requests,
+	// regardless of whether they pass or fail.
+	Teardown []*Request
+	// FrontMatter is the front matter of the file, shared by its
+	// groups, or nil if it has none.
+	FrontMatter *FrontMatter
 }
 
+// MethodExec is the method of requests that run a shell command,
+// like ## EXEC ./seed.sh, rather than make an HTTP request. Their
+// Path is the command.
+const MethodExec = "EXEC"
+
+// MethodSQL is the method of requests that run the SQL in their
+// body against a database, like ## SQL main. Their Path is the name
+// of the database.
+const MethodSQL = "SQL"
+
+// MethodConsume is the method of requests that wait for a message
+// from a message broker, like ## CONSUME events orders. Their Path
+// is the name of the broker and the topic (or queue).
+const MethodConsume = "CONSUME"
+
+// stepMethods are the methods whose requests have the rest of the
+// heading as their Path, even if it has spaces in it.
+var stepMethods = []string{MethodExec, MethodConsume}
+
 type Request struct {
+	// Number is the line number of the request heading.
+	Number  int
 	Path    []byte
 	Method  []byte
+	Tags    []string
 	Details Lines
 	Params  Lines
 	Body    Lines
+	// BodyLang is the language of the body's code block, like
+	// json or base64.
+	BodyLang string
+	// Script is the ```script code block before the ===, which
+	// runs before the request is made.
+	Script Lines
 
 	ExpectedBody    Lines
 	ExpectedDetails Lines
+	// ExpectedBodyLang is the language of the expected body's
+	// code block.
+	ExpectedBodyLang string
+	// ResponseScript is the ```script code block after the ===,
+	// which runs once the response meets the expectations.
+	ResponseScript Lines
+	// ExpectedData are the code blocks after the === that are
+	// compared with part of the response data, rather than the
+	// whole body, like ```json Data.user
+	ExpectedData []*DataBlock
+}
+
+// DataBlock is a code block of JSON that is compared with the
+// value at a path in the response data.
+type DataBlock struct {
+	// Number is the line number of the opening ```.
+	Number int
+	// Key is the path of the data, like Data.user.
+	Key   string
+	Lines Lines
 }
 
 type ErrLine struct {
@@ -77,67 +136,132 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 	// not.
 	settingExpectations := false
 
+	var frontMatter *FrontMatter
 	var currentGroup *Group
 	var currentRequest *Request
+	// the section the current request is in, and the
+	// section subsequent ### requests will be in.
+	var currentRequestSection, currentSection []byte
+	addRequest := func() {
+		if currentRequest == nil {
+			return
+		}
+		switch string(currentRequestSection) {
+		case sectionSetup:
+			currentGroup.Setup = append(currentGroup.Setup, currentRequest)
+		case sectionTeardown:
+			currentGroup.Teardown = append(currentGroup.Teardown, currentRequest)
+		default:
+			currentGroup.Requests = append(currentGroup.Requests, currentRequest)
+		}
+		currentRequest = nil
+	}
 
 	for scanner.Scan() {
 		n++
+		if n == 1 && isFrontMatterDelim(scanner.Bytes()) {
+			var err error
+			if n, frontMatter, err = scanFrontMatter(n, scanner); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		line, err := ParseLine(n, scanner.Bytes())
 		if err != nil {
 			return nil, err
 		}
+		if line.Type == LineTypePlain && currentSection != nil && sectionRequestRegex.Match(line.Bytes) {
+			// ### GET /comments
+			line.Type = LineTypeRequest
+			line.Regexp = sectionRequestRegex
+		}
 		switch line.Type {
 		case LineTypeGroupHeading:
 			// new group
 			if currentGroup != nil {
-				if currentRequest != nil {
-					currentGroup.Requests = append(currentGroup.Requests, currentRequest)
-					currentRequest = nil
-				}
+				addRequest()
 				groups = append(groups, currentGroup)
 			}
-			title, err := getok(line.Regexp.FindSubmatch(line.Bytes), 1)
+			currentSection = nil
+			text, tags := parseTags(line.Bytes)
+			title, err := getok(line.Regexp.FindSubmatch(text), 1)
 			if err != nil {
 				return nil, &ErrLine{N: n, Err: err}
 			}
 			currentGroup = &Group{
-				Filename: filename,
-				Title:    title,
+				Filename:    filename,
+				Title:       title,
+				Tags:        tags,
+				FrontMatter: frontMatter,
+			}
+		case LineTypeSection:
+			if currentGroup == nil {
+				return nil, &ErrLine{N: n, Err: errMissingGroupHeader}
+			}
+			addRequest()
+			if currentSection, err = getok(line.Regexp.FindSubmatch(line.Bytes), 1); err != nil {
+				return nil, &ErrLine{N: n, Err: err}
 			}
 		case LineTypeRequest:
 			// new request
 			if currentGroup == nil {
 				return nil, &ErrLine{N: n, Err: errMissingGroupHeader}
 			}
-			if currentRequest != nil {
-				currentGroup.Requests = append(currentGroup.Requests, currentRequest)
+			addRequest()
+			currentRequestSection = nil
+			if line.Regexp == sectionRequestRegex {
+				currentRequestSection = currentSection
 			}
 			settingExpectations = false
 			var err error
-			currentRequest = &Request{}
-			matches := line.Regexp.FindSubmatch(line.Bytes)
+			text, tags := parseTags(line.Bytes)
+			currentRequest = &Request{Number: n, Tags: tags}
+			matches := line.Regexp.FindSubmatch(text)
 			if currentRequest.Method, err = getok(matches, 1); err != nil {
 				return nil, &ErrLine{N: n, Err: err}
 			}
 			if currentRequest.Path, err = getok(matches, 2); err != nil {
 				return nil, &ErrLine{N: n, Err: err}
 			}
+			for _, method := range stepMethods {
+				if bytes.HasPrefix(currentRequest.Method, []byte(method+" ")) {
+					// the rest of the heading is the path
+					rest := bytes.TrimPrefix(currentRequest.Method, []byte(method+" "))
+					currentRequest.Method = []byte(method)
+					currentRequest.Path = bytes.Join([][]byte{rest, currentRequest.Path}, []byte(" "))
+				}
+			}
 		case LineTypeCodeBlock:
 
 			if currentRequest == nil {
 				return nil, &ErrLine{N: n, Err: errUnexpectedCodeblock}
 			}
 
+			info := strings.Fields(strings.TrimPrefix(string(line.Bytes), "```"))
+			lang := ""
+			if len(info) > 0 {
+				lang = strings.ToLower(info[0])
+			}
+			start := n
 			var lines Lines
 			var err error
 			n, lines, err = scancodeblock(n, scanner)
 			if err != nil {
 				return nil, &ErrLine{N: n, Err: err}
 			}
-			if settingExpectations {
+			if lang == "script" && settingExpectations {
+				currentRequest.ResponseScript = lines
+			} else if lang == "script" {
+				currentRequest.Script = lines
+			} else if settingExpectations && len(info) > 1 {
+				// ```json Data.user
+				currentRequest.ExpectedData = append(currentRequest.ExpectedData, &DataBlock{Number: start, Key: info[1], Lines: lines})
+			} else if settingExpectations {
 				currentRequest.ExpectedBody = lines
+				currentRequest.ExpectedBodyLang = lang
 			} else {
 				currentRequest.Body = lines
+				currentRequest.BodyLang = lang
 			}
 
 		case LineTypeDetail:
@@ -170,9 +294,7 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 	if currentGroup == nil {
 		return nil, &ErrLine{N: n, Err: errMissingGroupHeader}
 	}
-	if currentRequest != nil {
-		currentGroup.Requests = append(currentGroup.Requests, currentRequest)
-	}
+	addRequest()
 	groups = append(groups, currentGroup)
 
 	return groups, nil
@@ -196,6 +318,33 @@ func scancodeblock(n int, scanner *bufio.Scanner) (int, Lines, error) {
 	return n, lines, errMissingEndCodeblock
 }
 
+// Sections of a group.
+const (
+	sectionSetup    = "Setup"
+	sectionTeardown = "Teardown"
+)
+
+// sectionRequestRegex matches requests inside Setup and
+// Teardown sections.
+var sectionRequestRegex = regexp.MustCompile("^### (.*) (.*)")
+
+// tagsRegex matches @tags at the end of a heading.
+var tagsRegex = regexp.MustCompile(`(\s+@[\w-]+)+\s*$`)
+
+// parseTags gets the @tags from the end of a heading, and the
+// heading without them.
+func parseTags(heading []byte) ([]byte, []string) {
+	loc := tagsRegex.FindIndex(heading)
+	if loc == nil {
+		return heading, nil
+	}
+	var tags []string
+	for _, tag := range bytes.Fields(heading[loc[0]:loc[1]]) {
+		tags = append(tags, string(tag[1:]))
+	}
+	return heading[:loc[0]], tags
+}
+
 func getok(src [][]byte, i int) ([]byte, error) {
 	if i+1 > len(src) {
 		return nil, fmt.Errorf("bad format: expected at least %d regex matches, but was %d: %s", i+1, len(src), string(bytes.Join(src, []byte("\n"))))
@@ -1,6 +1,7 @@
 package parse_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cheekybits/is"
@@ -65,3 +66,111 @@ func TestParser(t *testing.T) {
 	is.Equal(len(group.Requests), 1)
 
 }
+
+func TestParserTags(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/success/tags.silk.md")
+	is.NoErr(err)
+	is.Equal(len(groups), 2)
+
+	group := groups[0]
+	is.Equal(group.Title, "Users")
+	is.Equal(group.Tags, []string{"users", "smoke"})
+	is.Equal(len(group.Requests), 3)
+	is.Equal(group.Requests[0].Method, "GET")
+	is.Equal(group.Requests[0].Path, "/users")
+	is.Equal(group.Requests[0].Tags, []string{"fast"})
+	is.Equal(group.Requests[1].Path, "/users/1")
+	is.Equal(group.Requests[1].Tags, []string{"slow"})
+	is.Equal(group.Requests[2].Method, "DELETE")
+	is.Equal(group.Requests[2].Path, "/users/1")
+	is.Equal(group.Requests[2].Tags, []string{"slow", "destructive"})
+
+	group = groups[1]
+	is.Equal(group.Title, "Comments")
+	is.Equal(len(group.Tags), 0)
+	is.Equal(len(group.Requests[0].Tags), 0)
+
+}
+
+func TestParserSetupTeardown(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/success/setup.silk.md")
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+
+	group := groups[0]
+	is.Equal(len(group.Setup), 1)
+	is.Equal(group.Setup[0].Method, "POST")
+	is.Equal(group.Setup[0].Path, "/users")
+	is.Equal(len(group.Setup[0].ExpectedDetails), 2)
+	is.Equal(len(group.Requests), 2)
+	is.Equal(group.Requests[0].Method, "GET")
+	is.Equal(group.Requests[1].Method, "PUT")
+	is.Equal(len(group.Teardown), 1)
+	is.Equal(group.Teardown[0].Method, "DELETE")
+	is.Equal(group.Teardown[0].Path, "/{collection}/1")
+
+}
+
+func TestParserDataBlock(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/datablock/datablock.silk.md")
+	is.NoErr(err)
+	req := groups[0].Requests[0]
+	is.Equal(req.BodyLang, "json")
+	is.Equal(len(req.ExpectedBody), 0)
+	is.Equal(len(req.ExpectedData), 2)
+	is.Equal(req.ExpectedData[0].Number, 14)
+	is.Equal(req.ExpectedData[0].Key, "Data.body.user")
+	is.Equal(req.ExpectedData[0].Lines.Number(), 15)
+	is.Equal(req.ExpectedData[1].Key, "Data.body.user.tags")
+	is.Equal(req.ExpectedData[1].Lines.String(), `["a", "b"]`)
+
+}
+
+func TestParserFrontMatter(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/frontmatter/frontmatter.silk.md")
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	fm := groups[0].FrontMatter
+	is.Equal(fm.URL, "http://localhost:8081")
+	is.Equal(fm.Headers, map[string]string{"X-Tenant": "acme"})
+	is.Equal(fm.Tags, []string{"billing"})
+	is.Equal(fm.Vars["invoiceID"], 7)
+	// line numbers count the front matter
+	is.Equal(groups[0].Requests[0].Number, 12)
+
+	groups, err = parse.ParseFile("../testfiles/success/tags.silk.md")
+	is.NoErr(err)
+	is.Nil(groups[0].FrontMatter)
+
+	_, err = parse.Parse("test.silk.md", strings.NewReader("---\nurl: http://localhost\n# Group\n"))
+	is.Equal(err.Error(), "1: missing end of front matter")
+	_, err = parse.Parse("test.silk.md", strings.NewReader("---\nurls: http://localhost\n---\n# Group\n"))
+	is.True(strings.HasPrefix(err.Error(), "1: front matter: "))
+
+}
+
+func TestParserExec(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/exec/exec.silk.md")
+	is.NoErr(err)
+	reqs := groups[0].Requests
+	is.Equal(reqs[0].Method, parse.MethodExec)
+	is.Equal(reqs[0].Path, `echo '{"id": 7}'`)
+	is.Equal(reqs[1].Method, "GET")
+	is.Equal(reqs[3].Path, "echo oops >&2; exit 3")
+
+	groups, err = parse.ParseFile("../testfiles/consume/consume.silk.md")
+	is.NoErr(err)
+	is.Equal(groups[0].Requests[1].Method, parse.MethodConsume)
+	is.Equal(groups[0].Requests[1].Path, "events orders")
+
+}
@@ -3,6 +3,7 @@ package parse
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -26,15 +27,20 @@ func (v Value) String() string {
 }
 
 // Equal gets whether the Data and specified value are equal.
-// Supports regexp values.
+// Supports regexp values, and registered matchers like uuid()
+// (see RegisterMatcher).
 func (v Value) Equal(val interface{}) bool {
 	// check to see if this is regex
 	var str string
 	var ok bool
 	if str, ok = v.Data.(string); !ok {
-		return v.Data == val
+		// lists and objects can't be compared with ==
+		return reflect.DeepEqual(v.Data, val)
 	}
-	if strings.HasPrefix(str, "/") && strings.HasSuffix(str, "/") {
+	if m, ok := LookupMatcher(str); ok {
+		return m.Match(val)
+	}
+	if isRegex(str) {
 		// looks like regexp to me
 		regex := regexp.MustCompile(str[1 : len(str)-1])
 		// turn the value into a string
@@ -52,12 +58,42 @@ func (v Value) Type() string {
 	if str, ok = v.Data.(string); !ok {
 		return fmt.Sprintf("%T", v.Data)
 	}
-	if strings.HasPrefix(str, "/") && strings.HasSuffix(str, "/") {
+	if isRegex(str) {
 		return "regex"
 	}
+	if _, ok := LookupMatcher(str); ok {
+		return "matcher"
+	}
 	return "string"
 }
 
+// Captures gets the named capture groups from matching a regex
+// value against val.
+// Returns nil if the value is not a regex, or if it doesn't match.
+func (v Value) Captures(val interface{}) map[string]string {
+	str, ok := v.Data.(string)
+	if !ok || !isRegex(str) {
+		return nil
+	}
+	regex := regexp.MustCompile(str[1 : len(str)-1])
+	matches := regex.FindStringSubmatch(fmt.Sprintf("%v", val))
+	if matches == nil {
+		return nil
+	}
+	captures := make(map[string]string)
+	for i, name := range regex.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		captures[name] = matches[i]
+	}
+	return captures
+}
+
+func isRegex(str string) bool {
+	return len(str) > 1 && strings.HasPrefix(str, "/") && strings.HasSuffix(str, "/")
+}
+
 func ParseValue(src []byte) *Value {
 	var v interface{}
 	src = clean(src)
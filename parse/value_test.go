@@ -51,3 +51,21 @@ func TestValueEqual(t *testing.T) {
 	is.Equal("regex", v.Type())
 
 }
+
+func TestValueCaptures(t *testing.T) {
+	is := is.New(t)
+
+	v := ParseValue([]byte(`/^\/users\/(?P<userID>[0-9]+)\/(?P<section>.*)$/`))
+	is.True(v.Equal("/users/123/orders"))
+	captures := v.Captures("/users/123/orders")
+	is.Equal(len(captures), 2)
+	is.Equal(captures["userID"], "123")
+	is.Equal(captures["section"], "orders")
+
+	is.Nil(v.Captures("/people/123"))
+	is.Nil(ParseValue([]byte("something")).Captures("something"))
+
+	v = ParseValue([]byte(`/^2(?P<rest>.{2})$/`))
+	is.Equal(v.Captures(float64(201))["rest"], "01")
+
+}
@@ -0,0 +1,3 @@
+// Package record provides tools for recording HTTP traffic as
+// Silk files.
+package record
@@ -0,0 +1,123 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/matryer/silk/write"
+)
+
+// IgnoredHeaders are request headers that are not recorded.
+var IgnoredHeaders = map[string]bool{
+	"Accept-Encoding": true,
+	"Connection":      true,
+	"Content-Length":  true,
+	"User-Agent":      true,
+	"X-Forwarded-For": true,
+}
+
+// AssertedHeaders are the response headers that are recorded
+// as assertions.
+var AssertedHeaders = []string{
+	"Content-Type",
+	"Location",
+}
+
+// Recorder is an http.RoundTripper that records requests and
+// their responses, so they can be written as silk files.
+type Recorder struct {
+	transport http.RoundTripper
+	mu        sync.Mutex
+	requests  []*write.Request
+}
+
+// New makes a new Recorder that makes requests with the transport.
+// If transport is nil, http.DefaultTransport is used.
+func New(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport}
+}
+
+// RoundTrip makes the request and records it.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.requests = append(r.requests, newRequest(req, reqBody, res, resBody))
+	r.mu.Unlock()
+	return res, nil
+}
+
+// Requests gets the recorded requests.
+func (r *Recorder) Requests() []*write.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	requests := make([]*write.Request, len(r.requests))
+	copy(requests, r.requests)
+	return requests
+}
+
+// Group gets the recorded requests as a silk group.
+func (r *Recorder) Group(title string) *write.Group {
+	return &write.Group{
+		Title:    title,
+		Requests: r.Requests(),
+	}
+}
+
+func newRequest(req *http.Request, reqBody []byte, res *http.Response, resBody []byte) *write.Request {
+	request := &write.Request{
+		Method:         req.Method,
+		Path:           req.URL.EscapedPath(),
+		Params:         req.URL.Query(),
+		ExpectedStatus: res.StatusCode,
+	}
+	var keys []string
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if IgnoredHeaders[k] {
+			continue
+		}
+		for _, v := range req.Header[k] {
+			request.Details = append(request.Details, write.Detail{Key: k, Value: v})
+		}
+	}
+	if write.Printable(reqBody) {
+		request.Body = reqBody
+	}
+	for _, k := range AssertedHeaders {
+		if v := res.Header.Get(k); v != "" {
+			request.ExpectedDetails = append(request.ExpectedDetails, write.Detail{Key: k, Value: v})
+		}
+	}
+	if write.Printable(resBody) {
+		request.ExpectedBody = resBody
+	}
+	return request
+}
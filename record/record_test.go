@@ -0,0 +1,64 @@
+package record_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/record"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+	"github.com/matryer/silk/write"
+)
+
+func TestRecorder(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+
+	recorder := record.New(nil)
+	client := &http.Client{Transport: recorder}
+	req, err := http.NewRequest("POST", s.URL+"/comments?pretty=true", strings.NewReader(`{"name":"Mat"}`))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req)
+	is.NoErr(err)
+	res.Body.Close()
+	res, err = client.Get(s.URL + "/comments/1")
+	is.NoErr(err)
+	res.Body.Close()
+
+	requests := recorder.Requests()
+	is.Equal(len(requests), 2)
+	is.Equal(requests[0].Method, "POST")
+	is.Equal(requests[0].Path, "/comments")
+	is.Equal(requests[0].Params.Get("pretty"), "true")
+	is.Equal(requests[0].Details, []write.Detail{{Key: "Content-Type", Value: "application/json"}})
+	is.Equal(string(requests[0].Body), `{"name":"Mat"}`)
+	is.Equal(requests[0].ExpectedStatus, 200)
+	is.Equal(requests[0].ExpectedDetails, []write.Detail{{Key: "Content-Type", Value: "text/plain; charset=utf-8"}})
+	is.True(strings.Contains(string(requests[0].ExpectedBody), "POST /comments"))
+
+	// the recording can be run
+	var buf bytes.Buffer
+	is.NoErr(write.Write(&buf, recorder.Group("Comments")))
+	groups, err := parse.Parse("recorded.silk.md", &buf)
+	is.NoErr(err)
+	subT := &testT{}
+	runner.New(subT, s.URL).RunGroup(groups...)
+	is.False(subT.failed)
+}
+
+type testT struct {
+	failed bool
+}
+
+func (t *testT) FailNow() {
+	t.failed = true
+}
+
+func (t *testT) Log(args ...interface{}) {}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// dependencies gets the titles of the groups that the group must
+// run after, from its After directive.
+func dependencies(group *parse.Group) []string {
+	line := directive(group.Details, directiveAfter)
+	if line == nil {
+		return nil
+	}
+	var titles []string
+	for _, title := range strings.Split(fmt.Sprintf("%v", line.Detail().Value.Data), ",") {
+		if title = strings.TrimSpace(title); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// orderGroups orders the groups so that they run after the groups
+// they depend on, and otherwise in the order they are given.
+func orderGroups(groups []*parse.Group) ([]*parse.Group, error) {
+	titles := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		titles[string(group.Title)] = true
+	}
+	// remaining counts the groups with each title that haven't
+	// been ordered yet
+	remaining := make(map[string]int, len(groups))
+	for _, group := range groups {
+		for _, dep := range dependencies(group) {
+			if !titles[dep] {
+				return nil, fmt.Errorf("%s: %s: %s: unknown group", group.Filename, directiveAfter, dep)
+			}
+		}
+		remaining[string(group.Title)]++
+	}
+	ordered := make([]*parse.Group, 0, len(groups))
+	done := make([]bool, len(groups))
+	for len(ordered) < len(groups) {
+		next := -1
+		for i, group := range groups {
+			if !done[i] && ready(group, remaining) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			var cycle []string
+			for i, group := range groups {
+				if !done[i] {
+					cycle = append(cycle, string(group.Title))
+				}
+			}
+			return nil, fmt.Errorf("%s: groups depend on each other: %s", directiveAfter, strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		remaining[string(groups[next].Title)]--
+		ordered = append(ordered, groups[next])
+	}
+	return ordered, nil
+}
+
+// ready gets whether every group the group depends on has been
+// ordered.
+func ready(group *parse.Group, remaining map[string]int) bool {
+	for _, dep := range dependencies(group) {
+		if remaining[dep] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// failedDependency gets the title of a group that the group depends
+// on that didn't pass, or an empty string.
+func failedDependency(group *parse.Group, passed map[string]bool) string {
+	for _, dep := range dependencies(group) {
+		if !passed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
+
+// skipGroup reports the requests of the group as skipped.
+func (r *Runner) skipGroup(group *parse.Group, reason string) {
+	for _, req := range group.Requests {
+		r.skip(&call{t: r.t, group: group, req: req}, req.Number, reason)
+	}
+}
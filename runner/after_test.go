@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+)
+
+func TestOrderGroups(t *testing.T) {
+	is := is.New(t)
+	group := func(title, after string) *parse.Group {
+		g := &parse.Group{Title: []byte(title)}
+		if after != "" {
+			line, err := parse.ParseLine(1, []byte("* After: "+after))
+			is.NoErr(err)
+			g.Details = parse.Lines{line}
+		}
+		return g
+	}
+	titles := func(groups []*parse.Group) []string {
+		var list []string
+		for _, g := range groups {
+			list = append(list, string(g.Title))
+		}
+		return list
+	}
+
+	ordered, err := orderGroups([]*parse.Group{
+		group("Comments", "Posts, Log in"),
+		group("Posts", "Log in"),
+		group("Health", ""),
+		group("Log in", ""),
+	})
+	is.NoErr(err)
+	is.Equal(titles(ordered), []string{"Health", "Log in", "Posts", "Comments"})
+
+	_, err = orderGroups([]*parse.Group{group("Posts", "Log in")})
+	is.Err(err)
+	is.Equal(err.Error(), ": After: Log in: unknown group")
+
+	_, err = orderGroups([]*parse.Group{group("A", "B"), group("B", "A"), group("C", "")})
+	is.Err(err)
+	is.Equal(err.Error(), "After: groups depend on each other: A, B")
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cheekybits/m"
+)
+
+// assertKey is the detail that calls an Asserter, like
+// "* Assert: validOrder(Data.order)".
+const assertKey = "Assert"
+
+// Asserter is an assertion written in Go, for checks that are
+// specific to an API, which silk files call by name with the Assert
+// detail, as in Assert: validOrder(Data.order).
+// The argument is Data (or a field of it, like Data.order), Body,
+// Status or the name of a header. Asserters are added to a Runner
+// with Register.
+type Asserter interface {
+	// Name is the name that silk files call the Asserter by.
+	Name() string
+	// Match gets an error saying why the actual value doesn't
+	// pass the assertion, or nil if it does.
+	Match(actual interface{}) error
+}
+
+// Register adds Asserters to the Runner. Asserters with the same
+// name as earlier ones replace them.
+func (r *Runner) Register(asserters ...Asserter) {
+	r.Asserters = append(r.Asserters, asserters...)
+}
+
+// asserter gets the Asserter with the name, or nil if there isn't
+// one.
+func (r *Runner) asserter(name string) Asserter {
+	for i := len(r.Asserters) - 1; i >= 0; i-- {
+		if r.Asserters[i].Name() == name {
+			return r.Asserters[i]
+		}
+	}
+	return nil
+}
+
+var assertRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\((.*)\)$`)
+
+var errBadAssert = errors.New("expected name(argument), like validOrder(Data.order)")
+
+// parseAssert parses the value of an Assert detail into the name of
+// the Asserter and its argument.
+func parseAssert(s string) (name, arg string, err error) {
+	match := assertRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return "", "", errBadAssert
+	}
+	return match[1], strings.TrimSpace(match[2]), nil
+}
+
+// assertCustom checks the response with the Asserter called by the
+// Assert detail. data gets the data of the body.
+func (r *Runner) assertCustom(c *call, value string, res *http.Response, body []byte, data func() (interface{}, error)) bool {
+	name, arg, err := parseAssert(value)
+	if err != nil {
+		c.log(assertKey, err)
+		return false
+	}
+	asserter := r.asserter(name)
+	if asserter == nil {
+		c.log(assertKey, fmt.Sprintf("unknown asserter %s (see Runner.Register)", name))
+		return false
+	}
+	var actual interface{}
+	switch {
+	case arg == "Body":
+		actual = string(body)
+	case arg == "Status":
+		actual = float64(res.StatusCode)
+	case arg == "Data" || strings.HasPrefix(arg, "Data."), strings.HasPrefix(arg, "Data["):
+		d, err := data()
+		if err != nil {
+			c.log(assertKey, fmt.Sprintf("%s: failed to parse body: %s", value, err))
+			return false
+		}
+		actual, _ = m.GetOK(map[string]interface{}{"Data": d}, arg)
+	default:
+		actual = res.Header.Get(arg)
+	}
+	if err := asserter.Match(actual); err != nil {
+		c.log(assertKey, fmt.Sprintf("%s: %s", value, err))
+		return false
+	}
+	return true
+}
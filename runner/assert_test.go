@@ -0,0 +1,82 @@
+package runner_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+// orderAsserter checks that orders add up.
+type orderAsserter struct{}
+
+func (orderAsserter) Name() string { return "validOrder" }
+
+func (orderAsserter) Match(actual interface{}) error {
+	order, ok := actual.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an order, not %T", actual)
+	}
+	if order["total"] != order["price"].(float64)*order["quantity"].(float64) {
+		return errors.New("total is not price × quantity")
+	}
+	return nil
+}
+
+type evenAsserter struct{}
+
+func (evenAsserter) Name() string { return "evenStatus" }
+
+func (evenAsserter) Match(actual interface{}) error {
+	if int(actual.(float64))%2 != 0 {
+		return errors.New("odd")
+	}
+	return nil
+}
+
+func TestAsserter(t *testing.T) {
+	is := is.New(t)
+	total := "20"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"order":{"price":5,"quantity":4,"total":`+total+`}}`)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Register(orderAsserter{}, evenAsserter{})
+	r.RunFile("../testfiles/assert/assert.silk.md")
+	is.False(subT.Failed())
+
+	total = "21"
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.Register(orderAsserter{}, evenAsserter{})
+	r.RunFile("../testfiles/assert/assert.silk.md")
+	is.True(subT.Failed())
+	log := strings.Join(logs, "\n")
+	is.True(strings.Contains(log, "validOrder(Data.order): total is not price × quantity"))
+
+	// asserters must be registered
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	logs = nil
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/assert/assert.silk.md")
+	is.True(subT.Failed())
+	log = strings.Join(logs, "\n")
+	is.True(strings.Contains(log, "unknown asserter validOrder"))
+}
@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Benchmark runs the requests in the files b.N times under the
+// benchmark, so that go test -bench reports the time and allocations
+// it takes to run them all:
+//
+//	func BenchmarkComments(b *testing.B) {
+//		runner.New(b, s.URL).Benchmark(b, "comments.silk.md")
+//	}
+//
+// The files are parsed once, before the timer starts. A failing
+// request fails the benchmark. Only the results of the last run are
+// kept, for Summary.
+func (r *Runner) Benchmark(b *testing.B, filenames ...string) {
+	b.Helper()
+	groups, err := parse.ParseFile(filenames...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	n := len(r.results)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.results = r.results[:n]
+		r.RunGroup(groups...)
+	}
+}
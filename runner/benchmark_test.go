@@ -0,0 +1,36 @@
+package runner_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func BenchmarkEcho(b *testing.B) {
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(b, s.URL)
+	r.Log = func(string) {}
+	r.Verbose = func(...interface{}) {}
+	r.Benchmark(b, "../testfiles/success/echo.success.silk.md")
+}
+
+func TestBenchmark(t *testing.T) {
+	is := is.New(t)
+	result := testing.Benchmark(BenchmarkEcho)
+	is.True(result.N > 0)
+	is.True(result.MemAllocs > 0)
+
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	result = testing.Benchmark(func(b *testing.B) {
+		r := runner.New(b, s.URL)
+		r.Log = func(string) {}
+		r.Verbose = func(...interface{}) {}
+		r.Benchmark(b, "../testfiles/failure/echo.failure.wrongheader.silk.md")
+	})
+	is.Equal(result.N, 0)
+}
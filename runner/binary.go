@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/matryer/silk/parse"
+)
+
+// langBase64 is the language of code blocks containing base64
+// encoded binary bodies.
+//
+//	```base64
+//	iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk
+//	```
+const langBase64 = "base64"
+
+// decodeBase64 decodes the lines of a base64 code block, ignoring
+// white space.
+func decodeBase64(lines parse.Lines) ([]byte, error) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, lines.String())
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %v", err)
+	}
+	return b, nil
+}
+
+// RequestBody gets the body of the request as it is written in the
+// silk file, before templates are executed. Bodies in base64 code
+// blocks are decoded.
+func RequestBody(req *parse.Request) ([]byte, error) {
+	if req.BodyLang == langBase64 {
+		return decodeBase64(req.Body)
+	}
+	return []byte(req.Body.String()), nil
+}
+
+// binary gets whether the body is binary, rather than text.
+func binary(body []byte) bool {
+	return !utf8.Valid(body)
+}
+
+// describeBinaryDiff describes how two binary bodies differ.
+func describeBinaryDiff(expected, actual []byte) string {
+	i := 0
+	for i < len(expected) && i < len(actual) && expected[i] == actual[i] {
+		i++
+	}
+	return fmt.Sprintf("binary body differs at byte %d: expected %d bytes, actual %d bytes", i, len(expected), len(actual))
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cheekybits/m"
+	"github.com/matryer/silk/parse"
+)
+
+// captureRegex matches the values of Capture directives, like
+// Location => {createdURL}.
+var captureRegex = regexp.MustCompile(`^\s*(\S+)\s*=>\s*\{([A-Za-z_][A-Za-z0-9_]*)\}\s*$`)
+
+var errCapture = errors.New("expected what to capture and a variable, like Location => {createdURL}")
+
+// parseCapture gets what a Capture directive captures (a header,
+// Status, Body, or a Data field), and the name of the variable.
+func parseCapture(v *parse.Value) (source, name string, err error) {
+	match := captureRegex.FindStringSubmatch(fmt.Sprintf("%v", v.Data))
+	if match == nil {
+		return "", "", errCapture
+	}
+	return match[1], match[2], nil
+}
+
+func checkCapture(v *parse.Value) error {
+	_, _, err := parseCapture(v)
+	return err
+}
+
+// captures gets the names of the variables captured by the Capture
+// directives in the lines.
+func captures(lines parse.Lines) []string {
+	var names []string
+	for _, line := range lines {
+		if detail := line.Detail(); detail.Key == directiveCapture {
+			if _, name, err := parseCapture(detail.Value); err == nil {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// captureResponse stores the values of the request's Capture directives in
+// the call's vars, and gets the line of the directive that fails.
+// data gets the data of the response, which is only parsed if a
+// Data field is captured.
+func (r *Runner) captureResponse(c *call, res *http.Response, body []byte, data func() (interface{}, error)) (int, error) {
+	for _, line := range c.req.ExpectedDetails {
+		detail := line.Detail()
+		if detail.Key != directiveCapture {
+			continue
+		}
+		source, name, err := parseCapture(detail.Value)
+		if err != nil {
+			return line.Number, err
+		}
+		var value interface{}
+		var ok bool
+		switch {
+		case source == "Status":
+			value, ok = float64(res.StatusCode), true
+		case source == "Body":
+			value, ok = string(body), true
+		case strings.HasPrefix(source, "Data"):
+			d, err := data()
+			if err != nil {
+				return line.Number, fmt.Errorf("%s: %v", source, err)
+			}
+			value, ok = m.GetOK(map[string]interface{}{"Data": d}, source)
+		default:
+			value, ok = responseHeader(res.Header, source, nil)
+		}
+		if !ok {
+			return line.Number, fmt.Errorf("%s is %s", source, missing)
+		}
+		c.vars[name] = value
+	}
+	return 0, nil
+}
@@ -0,0 +1,347 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/matryer/silk/parse"
+)
+
+// CheckError is a problem found by Check.
+type CheckError struct {
+	Filename string
+	Line     int
+	Err      error
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.Filename, e.Line, e.Err)
+}
+
+// Check parses the files and checks them for problems, without
+// making any requests. It checks directives, regex values, and
+// that templates only refer to variables that will be available.
+func (r *Runner) Check(filenames ...string) []error {
+	var errs []error
+	for _, filename := range filenames {
+		groups, err := parse.ParseFile(filename)
+		if err != nil {
+			line := 0
+			if errLine, ok := err.(*parse.ErrLine); ok {
+				line, err = errLine.N, errLine.Err
+			}
+			errs = append(errs, &CheckError{Filename: filename, Line: line, Err: err})
+			continue
+		}
+		var fileErrs []error
+		fileSnippets := snippets(groups)[filename]
+		for _, group := range groups {
+			fileErrs = append(fileErrs, r.checkGroup(group, fileSnippets)...)
+		}
+		sort.SliceStable(fileErrs, func(i, j int) bool {
+			return fileErrs[i].(*CheckError).Line < fileErrs[j].(*CheckError).Line
+		})
+		errs = append(errs, fileErrs...)
+	}
+	return errs
+}
+
+func (r *Runner) checkGroup(group *parse.Group, snippets map[string]*parse.Request) []error {
+	c := &checker{
+		group:    group,
+		funcs:    r.funcs(),
+		known:    make(map[string]bool),
+		snippets: snippets,
+	}
+	for name := range r.Vars {
+		c.known[name] = true
+	}
+	if group.FrontMatter != nil {
+		for name := range group.FrontMatter.Vars {
+			c.known[name] = true
+		}
+	}
+	c.checkDirectives(group.Details, directives)
+	for _, requests := range [][]*parse.Request{group.Setup, group.Requests, group.Teardown} {
+		for _, req := range requests {
+			c.checkRequest(req)
+		}
+	}
+	return c.errs
+}
+
+// checker checks a group.
+type checker struct {
+	group *parse.Group
+	funcs template.FuncMap
+	// known are the variables available to the group.
+	known map[string]bool
+	// snippets are the snippets of the file, by name.
+	snippets map[string]*parse.Request
+	errs     []error
+}
+
+func (c *checker) errorf(line int, format string, args ...interface{}) {
+	c.errs = append(c.errs, &CheckError{
+		Filename: c.group.Filename,
+		Line:     line,
+		Err:      fmt.Errorf(format, args...),
+	})
+}
+
+func (c *checker) checkRequest(req *parse.Request) {
+	if isSnippet(req) {
+		// snippets are checked where they are used, with the
+		// arguments
+		c.checkDirectives(req.Details, directives)
+		return
+	}
+	// variables available to this request only
+	known := make(map[string]bool, len(c.known))
+	for name := range c.known {
+		known[name] = true
+	}
+	if line := directive(req.Details, directiveUse); line != nil {
+		if name, args, err := parseUse(fmt.Sprintf("%v", line.Detail().Value.Data)); err == nil {
+			snippet, ok := c.snippets[name]
+			if !ok {
+				c.errorf(line.Number, "%s: unknown snippet %s", directiveUse, name)
+				return
+			}
+			req = withSnippet(snippet, req)
+			for name := range args {
+				known[name] = true
+			}
+		}
+	}
+	if line := directive(req.Details, directiveDataFile); line != nil {
+		filename := filepath.Join(filepath.Dir(c.group.Filename), fmt.Sprintf("%v", line.Detail().Value.Data))
+		rows, err := loadDataFile(filename)
+		if err != nil {
+			c.errorf(line.Number, "%s: %v", directiveDataFile, err)
+		}
+		for _, row := range rows {
+			for name := range row {
+				known[name] = true
+			}
+		}
+	}
+	if directive(req.Details, directiveRepeat) != nil {
+		known[iterationVar] = true
+	}
+	c.checkTemplate(req.Number, string(req.Path), known)
+	c.checkTemplate(req.Body.Number(), req.Body.String(), known)
+	c.checkScript(req.Script, known, "Method", "Path", "Body")
+	if expectedBody, line, err := ExpectedBody(c.group, req); err != nil {
+		c.errorf(line, "%v", err)
+	} else if expectedBody != nil && string(req.Method) == http.MethodHead {
+		c.errorf(line, "%v", errHeadBody)
+	} else {
+		c.checkTemplate(line, string(expectedBody), known)
+	}
+	for _, block := range req.ExpectedData {
+		c.checkTemplate(block.Number, block.Lines.String(), known)
+	}
+	c.checkScript(req.ResponseScript, known, "Status", "Body", "Data")
+	c.checkDirectives(req.Details, directives)
+	c.checkDirectives(req.ExpectedDetails, expectedDirectives)
+	for _, lines := range []parse.Lines{req.Details, req.Params, req.ExpectedDetails} {
+		for _, line := range lines {
+			if line.Detail() != nil && line.Detail().Key == directiveCapture {
+				// the variable is set, not used
+				continue
+			}
+			c.checkTemplate(line.Number, string(line.Bytes), known)
+		}
+	}
+	for _, name := range captures(req.ExpectedDetails) {
+		c.known[name] = true
+	}
+	for _, line := range req.ExpectedDetails {
+		detail := line.Detail()
+		if strings.HasPrefix(detail.Key, "Data") && string(req.Method) == http.MethodHead {
+			c.errorf(line.Number, "%s: %v", detail.Key, errHeadBody)
+			continue
+		}
+		if detail.Key == continueKey || detail.Key == idempotentKey {
+			if err := checkBool(detail.Value); err != nil {
+				c.errorf(line.Number, "%s: %v", detail.Key, err)
+			}
+			continue
+		}
+		if detail.Key == corsKey {
+			if err := checkCORS(detail.Value); err != nil {
+				c.errorf(line.Number, "%s: %v", detail.Key, err)
+			}
+			continue
+		}
+		if detail.Key == bodySizeKey || detail.Key == chunksKey {
+			if _, err := parseComparison(detail.Value); err != nil {
+				c.errorf(line.Number, "%s: %v", detail.Key, err)
+			}
+			continue
+		}
+		if detail.Key == firstByteKey {
+			if m, _ := parseDuration(fmt.Sprintf("%v", detail.Value.Data)); m == nil {
+				c.errorf(line.Number, "%s: expected a comparison with a duration, like < 500ms", detail.Key)
+			}
+			continue
+		}
+		if detail.Key == assertKey {
+			// Asserters are only known when the files are run
+			if _, _, err := parseAssert(fmt.Sprintf("%v", detail.Value.Data)); err != nil {
+				c.errorf(line.Number, "%s: %v", detail.Key, err)
+			}
+			continue
+		}
+		if _, err := parseMatcher(detail.Value); err != nil {
+			c.errorf(line.Number, "%s: %v", detail.Key, err)
+			continue
+		}
+		if detail.Value.Type() != "regex" {
+			continue
+		}
+		str := detail.Value.Data.(string)
+		regex, err := regexp.Compile(str[1 : len(str)-1])
+		if err != nil {
+			c.errorf(line.Number, "%s: bad regex: %v", detail.Key, err)
+			continue
+		}
+		// captured values are available to later requests
+		for _, name := range regex.SubexpNames() {
+			if name != "" {
+				c.known[name] = true
+			}
+		}
+	}
+}
+
+// checkScript checks the statements of a script, and adds the
+// variables it sets to those known to the request and the group.
+// The extra names are the data only scripts have, like Body.
+func (c *checker) checkScript(lines parse.Lines, known map[string]bool, extra ...string) {
+	statements, err := parseScript(lines)
+	if err != nil {
+		errLine := err.(*parse.ErrLine)
+		c.errorf(errLine.N, "%v", errLine.Err)
+		return
+	}
+	available := make(map[string]bool, len(known)+len(extra))
+	for name := range known {
+		available[name] = true
+	}
+	for _, name := range extra {
+		available[name] = true
+	}
+	for _, s := range statements {
+		c.checkTemplate(s.Line, "{{"+s.Expr+"}}", available)
+		available[s.Name], known[s.Name], c.known[s.Name] = true, true, true
+	}
+}
+
+// expectedDirectives are the directives allowed in expected
+// details, and the functions that check their values.
+var expectedDirectives = func() map[string]func(*parse.Value) error {
+	m := make(map[string]func(*parse.Value) error)
+	for name := range responseDirectives {
+		m[name] = directives[name]
+	}
+	return m
+}()
+
+// checkDirectives checks the values of the directives in lines,
+// and looks for misspelled directive names.
+func (c *checker) checkDirectives(lines parse.Lines, directives map[string]func(*parse.Value) error) {
+	for _, line := range lines {
+		detail := line.Detail()
+		if check, ok := directives[detail.Key]; ok {
+			if check == nil {
+				continue
+			}
+			if err := check(detail.Value); err != nil {
+				c.errorf(line.Number, "%s: %v", detail.Key, err)
+			}
+			continue
+		}
+		for name := range directives {
+			if similar(detail.Key, name) {
+				c.errorf(line.Number, "unknown directive %s (did you mean %s?)", detail.Key, name)
+			}
+		}
+	}
+}
+
+// templateFieldRegex matches the fields used in template actions.
+var (
+	templateActionRegex = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	templateFieldRegex  = regexp.MustCompile(`(?:^|[\s(|])\.([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// checkTemplate checks that s is a valid template, and that the
+// variables it uses, in templates and {name} references, are known.
+func (c *checker) checkTemplate(line int, s string, known map[string]bool) {
+	stripped := templateActionRegex.ReplaceAllString(s, "")
+	for _, loc := range varRefRegex.FindAllStringSubmatchIndex(stripped, -1) {
+		if loc[0] > 0 && stripped[loc[0]-1] == '$' {
+			// ${NAME} environment variables
+			continue
+		}
+		if name := stripped[loc[2]:loc[3]]; !known[name] {
+			c.errorf(line, "unknown variable %s", name)
+		}
+	}
+	if !strings.Contains(s, "{{") {
+		return
+	}
+	if _, err := template.New("silk").Funcs(c.funcs).Parse(s); err != nil {
+		c.errorf(line, "%v", err)
+		return
+	}
+	for _, action := range templateActionRegex.FindAllStringSubmatch(s, -1) {
+		for _, field := range templateFieldRegex.FindAllStringSubmatch(action[1], -1) {
+			if !known[field[1]] {
+				c.errorf(line, "unknown variable %s", field[1])
+			}
+		}
+	}
+}
+
+// similar gets whether two different names are similar enough
+// that one is probably a mistake.
+// They are similar if they only differ by case, or by a single
+// insertion, deletion, substitution or transposition.
+func similar(a, b string) bool {
+	if a == b {
+		return false
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if a == b {
+		return true
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 || len(a) < 3 {
+		return false
+	}
+	// find the first difference
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	if len(a) < len(b) {
+		// insertion
+		return a[i:] == b[i+1:]
+	}
+	if a[i+1:] == b[i+1:] {
+		// substitution
+		return true
+	}
+	// transposition
+	return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+}
@@ -0,0 +1,38 @@
+package runner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	r := runner.New(&testT{}, "")
+	r.Vars["token"] = "abc123"
+	errs := r.Check("../testfiles/check/check.silk.md", "../testfiles/check/malformed.silk.md")
+	var actual []string
+	for _, err := range errs {
+		actual = append(actual, err.Error())
+	}
+	is.Equal(strings.Join(actual, "\n"), strings.Join([]string{
+		"../testfiles/check/check.silk.md:3: unknown directive Tag (did you mean Tags?)",
+		"../testfiles/check/check.silk.md:8: unknown directive Repaet (did you mean Repeat?)",
+		"../testfiles/check/check.silk.md:9: unknown variable userID",
+		"../testfiles/check/check.silk.md:10: SkipIf: malformed condition",
+		"../testfiles/check/check.silk.md:16: Data.id: bad regex: error parsing regexp: missing closing ): `(?P<id>[0-9]+`",
+		"../testfiles/check/check.silk.md:17: Data.created: expected \"within 5s of now\", \"after ...\" or \"before ...\"",
+		"../testfiles/check/check.silk.md:24: unknown variable name",
+		"../testfiles/check/check.silk.md:27: Repeat: expected a positive whole number",
+		"../testfiles/check/check.silk.md:28: DataFile: open ../testfiles/check/missing.csv: no such file or directory",
+		"../testfiles/check/check.silk.md:29: template: silk:1: function \"nope\" not defined",
+		"../testfiles/check/check.silk.md:30: unknown variable nickname",
+		"../testfiles/check/check.silk.md:34: Use: unknown snippet listUsers",
+		"../testfiles/check/malformed.silk.md:3: missing group header",
+	}, "\n"))
+
+	errs = r.Check("../testfiles/success/datafile.silk.md", "../testfiles/success/repeat.silk.md", "../testfiles/success/setup.silk.md", "../testfiles/script/script.silk.md", "../testfiles/capture/capture.silk.md", "../testfiles/success/head.silk.md", "../testfiles/cors/cors.silk.md", "../testfiles/continue/continue.silk.md", "../testfiles/idempotency/idempotency.silk.md", "../testfiles/frontmatter/frontmatter.silk.md", "../testfiles/snippets/snippets.silk.md")
+	is.Equal(len(errs), 0)
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// envRefRegex matches ${NAME} environment variable references.
+var envRefRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+var errBadCondition = errors.New("malformed condition")
+
+// evalCondition evaluates a condition. Conditions take the form:
+//
+//	${ENV_VAR} == "value"
+//	${ENV_VAR} != "value"
+//	${ENV_VAR}
+//
+// A condition without an operator is true if the value is not
+// empty, "0" or "false". Operators inside quoted values are part
+// of the value.
+func evalCondition(cond string) (bool, error) {
+	if i := operatorIndex(cond); i != -1 {
+		op := cond[i : i+2]
+		left, err := conditionOperand(cond[:i])
+		if err != nil {
+			return false, err
+		}
+		right, err := conditionOperand(cond[i+len(op):])
+		if err != nil {
+			return false, err
+		}
+		return (left == right) == (op == "=="), nil
+	}
+	val, err := conditionOperand(cond)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(val) {
+	case "", "0", "false":
+		return false, nil
+	}
+	return true, nil
+}
+
+// operatorIndex gets the index of the first == or != operator
+// outside of quotes in the condition, or -1 if there isn't one.
+func operatorIndex(cond string) int {
+	quoted := false
+	for i := 0; i < len(cond)-1; i++ {
+		switch {
+		case cond[i] == '\\' && quoted:
+			i++
+		case cond[i] == '"':
+			quoted = !quoted
+		case !quoted && (cond[i] == '=' || cond[i] == '!') && cond[i+1] == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// conditionOperand gets the value of an operand, expanding any
+// environment variables and removing quotes.
+func conditionOperand(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", errBadCondition
+		}
+		s = unquoted
+	}
+	return envRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		return os.Getenv(ref[2 : len(ref)-1])
+	}), nil
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestEvalCondition(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_TEST_CI", "true")
+	defer os.Unsetenv("SILK_TEST_CI")
+
+	for cond, expected := range map[string]bool{
+		`${SILK_TEST_CI} == "true"`:      true,
+		`${SILK_TEST_CI} == true`:        true,
+		`${SILK_TEST_CI} != "true"`:      false,
+		`"${SILK_TEST_CI}" == "true"`:    true,
+		`${SILK_TEST_MISSING} == "true"`: false,
+		`${SILK_TEST_MISSING} == ""`:     true,
+		`${SILK_TEST_CI}`:                true,
+		`${SILK_TEST_MISSING}`:           false,
+		`false`:                          false,
+		`0`:                              false,
+		`"a==b" == "a==b"`:               true,
+		`${SILK_TEST_CI} == "a==b"`:      false,
+		`"a!=b" != "a!=b"`:               false,
+		`"say \"==\"" == "say \"==\""`:   true,
+	} {
+		actual, err := evalCondition(cond)
+		is.NoErr(err)
+		is.Equal(actual, expected)
+	}
+
+	_, err := evalCondition(`${SILK_TEST_CI} == "true`)
+	is.Err(err)
+
+}
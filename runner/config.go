@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	configLoaderLock sync.RWMutex
+	configLoader     func(t T, env string) (*Runner, error)
+)
+
+var errNoConfigLoader = errors.New("runner: no config loader (import github.com/matryer/silk/config)")
+
+// RegisterConfigLoader registers the func that NewFromConfig uses
+// to make Runners from the config file. The config package, which
+// imports this one, registers itself when it is imported.
+func RegisterConfigLoader(load func(t T, env string) (*Runner, error)) {
+	configLoaderLock.Lock()
+	defer configLoaderLock.Unlock()
+	configLoader = load
+}
+
+// NewFromConfig makes a new Runner from the silk.yaml config file in
+// the current directory (the directory of the package, in go test),
+// with the root URL, headers, timeout and other settings of the
+// named environment, or of the defaults if env is empty.
+// The config package reads the file, so it must be imported:
+//
+//	import _ "github.com/matryer/silk/config"
+func NewFromConfig(t T, env string) (*Runner, error) {
+	configLoaderLock.RLock()
+	load := configLoader
+	configLoaderLock.RUnlock()
+	if load == nil {
+		return nil, errNoConfigLoader
+	}
+	return load(t, env)
+}
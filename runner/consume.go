@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultWait is how long CONSUME steps wait for a message when the
+// Wait directive doesn't say.
+const defaultWait = 10 * time.Second
+
+// Keys of the expected details of CONSUME steps, along with the
+// names of the message's headers.
+const (
+	messageKeyKey  = "Key"
+	messageBodyKey = "Body"
+)
+
+// Message is a message from a message broker.
+type Message struct {
+	// Key is the key of the message, or empty if it hasn't got one.
+	Key string
+	// Headers are the headers (or properties) of the message.
+	Headers map[string]string
+	// Body is the body of the message.
+	Body []byte
+}
+
+// Consumer gets messages from a message broker, like Kafka,
+// RabbitMQ or NATS, for CONSUME steps. It should subscribe before
+// the files are run, so that it gets the messages that requests
+// cause.
+type Consumer interface {
+	// Consume gets the next message on the topic (or queue, or
+	// subject), waiting until there is one or ctx is done.
+	Consume(ctx context.Context, topic string) (*Message, error)
+}
+
+var errConsumePath = errors.New("expected the name of a consumer and a topic, like CONSUME events orders")
+
+// consume gets messages from the topic of a CONSUME step until one
+// meets its expectations, or the time of the Wait directive passes.
+// Messages that don't are skipped, and only the last of them is
+// described if none do.
+func (r *Runner) consume(c *call) bool {
+	helperOf(c.t).Helper()
+	c.start = time.Now()
+	req := c.req
+	path, err := interpolate(string(req.Path), r.templateData(c), r.funcs())
+	if err != nil {
+		r.fail(c, req.Number, "invalid step:", err)
+		return false
+	}
+	fields := strings.Fields(path)
+	if len(fields) != 2 {
+		r.fail(c, req.Number, errConsumePath)
+		return false
+	}
+	name, topic := fields[0], fields[1]
+	wait := defaultWait
+	if line := directive(req.Details, directiveWait); line != nil {
+		if wait, err = time.ParseDuration(fmt.Sprintf("%v", line.Detail().Value.Data)); err != nil {
+			r.fail(c, line.Number, directiveWait+":", errNotDuration)
+			return false
+		}
+	}
+	r.Verbose(string(req.Method), name, topic)
+	r.logVars(c)
+	if r.DryRun {
+		r.Log(fmt.Sprintf("%s %s %s", req.Method, name, topic))
+		r.skip(c, req.Number, "dry run")
+		return true
+	}
+	consumer, ok := r.Consumers[name]
+	if !ok {
+		r.fail(c, req.Number, fmt.Sprintf("unknown consumer %q", name))
+		return false
+	}
+	ctx, cancel := context.WithTimeout(r.context(), wait)
+	defer cancel()
+	var last *call
+	for {
+		msg, err := consumer.Consume(ctx, topic)
+		if err != nil && ctx.Err() != nil {
+			if last != nil {
+				c.details, c.assertions = last.details, last.assertions
+				c.log("last message", string(last.responseBody))
+			}
+			r.fail(c, req.Number, "no message met the expectations within", wait)
+			return false
+		}
+		if err != nil {
+			r.fail(c, req.Number, err)
+			return false
+		}
+		r.Verbose(indent, "message:", string(msg.Body))
+		attempt := &call{t: c.t, subtest: c.subtest, group: c.group, req: req, vars: c.vars, local: c.local, name: c.name, polling: true, responseBody: msg.Body}
+		values := map[string]interface{}{messageKeyKey: msg.Key, messageBodyKey: string(msg.Body)}
+		for k, v := range msg.Headers {
+			values[k] = v
+		}
+		data, errData := r.ParseBody(bytes.NewReader(msg.Body))
+		if _, ok := r.assertStep(attempt, values, data, errData); ok {
+			c.responseBody, c.assertions = msg.Body, attempt.assertions
+			break
+		}
+		last = attempt
+	}
+	if reason := expectFail(req); reason != "" {
+		r.reportFailure(c, req.Number, "passed, but expected to fail:", reason)
+		return false
+	}
+	r.report(c, Passed, req.Number)
+	return true
+}
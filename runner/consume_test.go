@@ -0,0 +1,51 @@
+package runner_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+// chanConsumer is a runner.Consumer of the messages on a channel.
+type chanConsumer chan *runner.Message
+
+func (ch chanConsumer) Consume(ctx context.Context, topic string) (*runner.Message, error) {
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestConsume(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	events := make(chanConsumer, 2)
+	events <- &runner.Message{Key: "41", Headers: map[string]string{"Type": "order.created"}, Body: []byte(`{"id": "41", "total": 10}`)}
+	events <- &runner.Message{Key: "42", Headers: map[string]string{"Type": "order.created"}, Body: []byte(`{"id": "42", "total": 12.5}`)}
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Consumers = map[string]runner.Consumer{"events": events}
+	r.RunFile("../testfiles/consume/consume.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(events), 0)
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	events <- &runner.Message{Body: []byte(`{"id": "43"}`)}
+	r.RunFile("../testfiles/consume/missing.silk.md")
+	is.True(subT.Failed())
+	out := strings.Join(logs, "\n")
+	is.True(strings.Contains(out, "no message met the expectations within 50ms"))
+	is.True(strings.Contains(out, `last message {"id": "43"}`))
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"encoding/json"
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// bodyKind is how a body is compared, based on its Content-Type.
+type bodyKind int
+
+const (
+	// kindText bodies are compared line by line, ignoring
+	// differences in spaces within the lines, and blank lines at
+	// the start and end.
+	kindText bodyKind = iota
+	// kindJSON bodies are compared by the data they contain,
+	// ignoring formatting and the order of fields.
+	kindJSON
+	// kindBinary bodies are compared byte for byte.
+	kindBinary
+)
+
+// contentKind gets the kind of the body, from the Content-Type, or
+// the body itself if the Content-Type doesn't say.
+func contentKind(contentType string, body []byte) bodyKind {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return kindJSON
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "font/"),
+		mediaType == "application/octet-stream",
+		mediaType == "application/pdf",
+		mediaType == "application/zip",
+		mediaType == "application/gzip",
+		strings.Contains(mediaType, "protobuf"):
+		return kindBinary
+	}
+	if binary(body) {
+		return kindBinary
+	}
+	return kindText
+}
+
+// bodiesMatch gets whether the bodies match, when compared as the
+// kind of body.
+func bodiesMatch(kind bodyKind, actual, expected []byte) bool {
+	switch kind {
+	case kindJSON:
+		var actualData, expectedData interface{}
+		if json.Unmarshal(actual, &actualData) != nil || json.Unmarshal(expected, &expectedData) != nil {
+			return false
+		}
+		return reflect.DeepEqual(actualData, expectedData)
+	case kindText:
+		return normalizeSpace(string(actual)) == normalizeSpace(string(expected))
+	}
+	return false
+}
+
+// normalizeSpace replaces the runs of white space within each
+// line of s with single spaces, keeping the line breaks, and
+// trims blank lines from the start and end.
+func normalizeSpace(s string) string {
+	lines := strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
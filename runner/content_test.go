@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestBodiesMatchText(t *testing.T) {
+	is := is.New(t)
+	for actual, expected := range map[string]bool{
+		"Hello world":                true,
+		"  Hello \t world  ":         true,
+		"\nHello world\n\n":          true,
+		"Hello world\r\n":            true,
+		"Hello\nworld":               false,
+		"Hello world again":          false,
+		"Hello world\n\nand goodbye": false,
+	} {
+		is.Equal(bodiesMatch(kindText, []byte(actual), []byte("Hello world")), expected)
+	}
+	is.True(bodiesMatch(kindText, []byte("a  b\n  c\n"), []byte("a b\nc")))
+}
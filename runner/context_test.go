@@ -0,0 +1,36 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestRunFileContext(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.ContinueOnFailure = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	r.RunFileContext(ctx, "../testfiles/success/echo.success.silk.md", "../testfiles/success/data.silk.md")
+	is.True(time.Since(start) < time.Second)
+	is.True(subT.Failed())
+	summary := r.Summary()
+	is.Equal(len(summary.Results), 1)
+	is.Equal(summary.Failed, 1)
+}
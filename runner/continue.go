@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// continueKey is the assertion about whether the server sent a
+// 100 Continue interim response before its final response, like
+// "* Continue: false" for a server that rejects a large upload
+// without asking for the body.
+const continueKey = "Continue"
+
+var errNotBool = errors.New("expected true or false")
+
+func checkBool(v *parse.Value) error {
+	if _, ok := v.Data.(bool); !ok {
+		return errNotBool
+	}
+	return nil
+}
+
+// expectContinue gets how long to wait for a 100 Continue before
+// sending the body anyway, from the ExpectContinue directive of
+// the group or request, or zero if the body is sent straight away.
+func expectContinue(c *call) time.Duration {
+	var d time.Duration
+	for _, lines := range []parse.Lines{c.group.Details, c.req.Details} {
+		if line := directive(lines, directiveExpectContinue); line != nil {
+			// checked by checkWait
+			d, _ = time.ParseDuration(fmt.Sprintf("%v", line.Detail().Value.Data))
+		}
+	}
+	return d
+}
+
+// traceContinue gets a context that records whether the server
+// sends a 100 Continue in continued.
+func traceContinue(ctx context.Context, continued *bool) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		Got100Continue: func() {
+			*continued = true
+		},
+	})
+}
+
+// assertContinue asserts whether the server sent a 100 Continue.
+func (r *Runner) assertContinue(c *call, continued bool, expected *parse.Value) bool {
+	if err := checkBool(expected); err != nil {
+		c.log(continueKey, err)
+		return false
+	}
+	if continued != expected.Data.(bool) {
+		c.log(continueKey, fmt.Sprintf("expected %v  actual: %v", expected.Data, continued))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package runner_test
+
+import (
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+// goexitT is a runner.T that stops the goroutine in FailNow,
+// like testing.T.
+type goexitT struct {
+	logs *[]string
+}
+
+func (t goexitT) FailNow() {
+	*t.logs = append(*t.logs, "FailNow")
+	runtime.Goexit()
+}
+
+func (t goexitT) Log(args ...interface{}) {}
+
+func TestContinueOnFailure(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	for _, continueOnFailure := range []bool{false, true} {
+		var logs []string
+		r := runner.New(goexitT{logs: &logs}, s.URL)
+		r.ContinueOnFailure = continueOnFailure
+		r.Log = func(s string) {
+			logs = append(logs, s)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.RunFile("../testfiles/failure/echo.failure.wrongbody.silk.md",
+				"../testfiles/failure/echo.failure.wrongheader.silk.md",
+				"../testfiles/success/echo.success.silk.md")
+		}()
+		<-done
+		logstr := strings.Join(logs, "\n")
+		is.True(strings.Contains(logstr, "echo.failure.wrongbody.silk.md:14 - body doesn't match"))
+		is.Equal(strings.Count(logstr, "FailNow"), 1)
+		if !continueOnFailure {
+			is.False(strings.Contains(logstr, "wrongheader"))
+			is.Equal(r.Summary().Failed, 1)
+			continue
+		}
+		is.True(strings.Contains(logstr, "echo.failure.wrongheader.silk.md:22 - Content-Type doesn't match"))
+		is.True(strings.Contains(logstr, "FAIL: 3 file(s), 3 request(s): 1 passed, 2 failed, 0 skipped"))
+		is.Equal(r.Summary().Failed, 2)
+		is.Equal(r.Summary().Passed, 1)
+		// the test fails after the summary
+		is.True(strings.HasSuffix(logstr, "FailNow"))
+	}
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+)
+
+// cookiePrefix prefixes assertions about cookies set by the
+// response, like Cookie.session or Cookie.session.HttpOnly.
+const cookiePrefix = "Cookie."
+
+// cookieAttrs gets the attributes of cookies that may be asserted.
+var cookieAttrs = map[string]func(*http.Cookie) interface{}{
+	"Path":     func(c *http.Cookie) interface{} { return c.Path },
+	"Domain":   func(c *http.Cookie) interface{} { return c.Domain },
+	"Secure":   func(c *http.Cookie) interface{} { return c.Secure },
+	"HttpOnly": func(c *http.Cookie) interface{} { return c.HttpOnly },
+	"SameSite": func(c *http.Cookie) interface{} { return sameSite(c.SameSite) },
+	"MaxAge":   func(c *http.Cookie) interface{} { return float64(c.MaxAge) },
+	"Expires": func(c *http.Cookie) interface{} {
+		if c.RawExpires == "" {
+			return ""
+		}
+		return c.Expires.UTC().Format(http.TimeFormat)
+	},
+}
+
+// cookieKey splits the key of a cookie assertion into the name of
+// the cookie and the attribute, which is empty for its value.
+// For example, Cookie.session.HttpOnly is the HttpOnly attribute
+// of the session cookie. Gets false if the key is not about a cookie.
+func cookieKey(key string) (name, attr string, ok bool) {
+	if !strings.HasPrefix(key, cookiePrefix) {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(key, cookiePrefix)
+	if i := strings.LastIndex(name, "."); i != -1 {
+		if _, isAttr := cookieAttrs[name[i+1:]]; isAttr {
+			name, attr = name[:i], name[i+1:]
+		}
+	}
+	return name, attr, name != ""
+}
+
+// responseCookie gets the value or attribute of a cookie set by
+// the response, and whether the cookie was set.
+// If the cookie is set more than once, the last one is used.
+func responseCookie(res *http.Response, key string) (interface{}, bool) {
+	name, attr, _ := cookieKey(key)
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == name {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		return nil, false
+	}
+	if attr == "" {
+		return cookie.Value, true
+	}
+	return cookieAttrs[attr](cookie), true
+}
+
+// sameSite gets the SameSite attribute as it is written in the
+// Set-Cookie header, or empty if it isn't.
+func sameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// corsKey is the assertion about whether the response allows the
+// cross-origin request (or the preflight request for it), like
+// "* CORS: allowed". The request says what it wants with its
+// Origin, Access-Control-Request-Method and
+// Access-Control-Request-Headers headers.
+const corsKey = "CORS"
+
+// The values of CORS assertions.
+const (
+	corsAllowed = "allowed"
+	corsDenied  = "denied"
+)
+
+var errBadCORS = errors.New("expected allowed or denied")
+
+func checkCORS(v *parse.Value) error {
+	if v.Data != corsAllowed && v.Data != corsDenied {
+		return errBadCORS
+	}
+	return nil
+}
+
+// safelistedMethods are the methods that browsers allow without
+// them being in Access-Control-Allow-Methods.
+var safelistedMethods = map[string]bool{"GET": true, "HEAD": true, "POST": true}
+
+// corsProblems gets the reasons a browser would block the request
+// because of the CORS headers of the response, or none if it would
+// allow it.
+func corsProblems(req *http.Request, res *http.Response) []string {
+	var problems []string
+	origin := req.Header.Get("Origin")
+	allowOrigin := res.Header.Get("Access-Control-Allow-Origin")
+	switch {
+	case allowOrigin == "":
+		problems = append(problems, "no Access-Control-Allow-Origin")
+	case allowOrigin == "*" && res.Header.Get("Access-Control-Allow-Credentials") == "true":
+		problems = append(problems, "Access-Control-Allow-Origin is * with credentials")
+	case allowOrigin != "*" && allowOrigin != origin:
+		problems = append(problems, fmt.Sprintf("Access-Control-Allow-Origin is %q, not %q", allowOrigin, origin))
+	}
+	if method := req.Header.Get("Access-Control-Request-Method"); method != "" && !safelistedMethods[method] {
+		methods := corsList(res.Header, "Access-Control-Allow-Methods")
+		if !methods[method] && !methods["*"] {
+			problems = append(problems, fmt.Sprintf("%s is not in Access-Control-Allow-Methods", method))
+		}
+	}
+	allowHeaders := corsList(res.Header, "Access-Control-Allow-Headers")
+	requested := corsList(req.Header, "Access-Control-Request-Headers")
+	names := make([]string, 0, len(requested))
+	for name := range requested {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// * doesn't allow Authorization
+		if allowHeaders[name] || allowHeaders["*"] && name != "authorization" {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s is not in Access-Control-Allow-Headers", name))
+	}
+	return problems
+}
+
+// corsList gets the set of comma separated values of the header,
+// which may be repeated. Header names are case insensitive, so
+// lists of them are lower case; methods are not.
+func corsList(h http.Header, key string) map[string]bool {
+	list := make(map[string]bool)
+	for _, value := range h.Values(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if key != "Access-Control-Allow-Methods" {
+				item = strings.ToLower(item)
+			}
+			list[item] = true
+		}
+	}
+	return list
+}
+
+// assertCORS asserts whether the response allows the cross-origin
+// request.
+func (r *Runner) assertCORS(c *call, req *http.Request, res *http.Response, expected *parse.Value) bool {
+	if err := checkCORS(expected); err != nil {
+		c.log(corsKey, err)
+		return false
+	}
+	if req.Header.Get("Origin") == "" {
+		c.log(corsKey, "the request has no Origin header")
+		return false
+	}
+	problems := corsProblems(req, res)
+	switch {
+	case expected.Data == corsAllowed && len(problems) > 0:
+		c.log(corsKey, fmt.Sprintf("expected %s  actual: %s", corsAllowed, strings.Join(problems, ", ")))
+		return false
+	case expected.Data == corsDenied && len(problems) == 0:
+		c.log(corsKey, fmt.Sprintf("expected %s  actual: %s", corsDenied, corsAllowed))
+		return false
+	}
+	return true
+}
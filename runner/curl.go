@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// curl gets the curl command that makes the same request.
+func curl(req *http.Request, body string) string {
+	args := []string{"curl"}
+	switch req.Method {
+	case "GET":
+	case "HEAD":
+		args = append(args, "--head")
+	default:
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(req.URL.String()))
+	var keys []string
+	for k := range req.Header {
+		// curl sets the Content-Length itself
+		if k == "Content-Length" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			args = append(args, "\\\n  -H", shellQuote(k+": "+v))
+		}
+	}
+	if len(body) > 0 {
+		args = append(args, "\\\n  --data-binary", shellQuote(body))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s as a single argument for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
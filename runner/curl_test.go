@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestCurl(t *testing.T) {
+	is := is.New(t)
+	req, err := http.NewRequest("POST", "http://localhost:8080/comments?pretty=true", strings.NewReader(""))
+	is.NoErr(err)
+	req.Header.Add("Content-Length", "27")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer abc")
+	is.Equal(curl(req, `{"comment":"Mat's comment"}`), `curl -X POST 'http://localhost:8080/comments?pretty=true' \
+  -H 'Authorization: Bearer abc' \
+  -H 'Content-Type: application/json' \
+  --data-binary '{"comment":"Mat'\''s comment"}'`)
+
+	req, err = http.NewRequest("GET", "http://localhost:8080/comments", nil)
+	is.NoErr(err)
+	is.Equal(curl(req, ""), `curl 'http://localhost:8080/comments'`)
+
+	req, err = http.NewRequest("HEAD", "http://localhost:8080/comments", nil)
+	is.NoErr(err)
+	is.Equal(curl(req, ""), `curl --head 'http://localhost:8080/comments'`)
+}
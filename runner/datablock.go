@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cheekybits/m"
+	"github.com/matryer/silk/parse"
+)
+
+// assertDataBlock asserts that the value at the path of the block
+// has the same data as the JSON in the block.
+func (r *Runner) assertDataBlock(c *call, data interface{}, errData error, block *parse.DataBlock, src []byte) bool {
+	var expected interface{}
+	if err := json.Unmarshal(src, &expected); err != nil {
+		c.log(block.Key, "bad JSON:", err)
+		return false
+	}
+	if errData != nil {
+		c.log(block.Key, fmt.Sprintf("expected %s  actual: failed to parse body: %s", jsonString(expected), errData))
+		return false
+	}
+	actual, ok := m.GetOK(map[string]interface{}{"Data": data}, block.Key)
+	if !ok {
+		c.log(block.Key, fmt.Sprintf("expected %s  actual: %s", jsonString(expected), missing))
+		return false
+	}
+	if diffs := diffData(block.Key, expected, actual); len(diffs) > 0 {
+		c.log(block.Key, "data differs:")
+		for _, diff := range diffs {
+			c.log(indent, diff)
+		}
+		return false
+	}
+	return true
+}
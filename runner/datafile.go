@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errNoRows = errors.New("no rows")
+
+// loadDataFile loads the rows from a data file.
+// Files with a .json extension must contain an array of objects,
+// all others are treated as CSV where the first record contains
+// the field names. Files without any rows are an error.
+func loadDataFile(filename string) ([]map[string]interface{}, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		var rows []map[string]interface{}
+		if err := json.NewDecoder(f).Decode(&rows); err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return nil, errNoRows
+		}
+		return rows, nil
+	}
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header record")
+	}
+	if len(records) == 1 {
+		return nil, errNoRows
+	}
+	fields := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]interface{}, len(fields))
+		for i, field := range fields {
+			row[strings.TrimSpace(field)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
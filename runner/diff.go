@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around
+	// changes in diffs.
+	diffContext = 3
+	// maxDiffCells limits the size of the table used to find the
+	// differences between lines (to a few MB). Bigger bodies are
+	// shown in full.
+	maxDiffCells = 400000
+)
+
+// diffLines gets a unified diff between the expected and actual
+// lines.
+func diffLines(expected, actual []string) []string {
+	ops := editScript(expected, actual)
+	var out []string
+	// find each change, and show it with its context
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// extend the hunk while changes are close together
+		end := i
+		for j := i; j < len(ops) && j <= end+2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			}
+		}
+		end += diffContext + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		var expectedN, actualN int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				expectedN++
+			}
+			if op.kind != '-' {
+				actualN++
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", ops[start].expectedLine, expectedN, ops[start].actualLine, actualN))
+		for _, op := range ops[start:end] {
+			out = append(out, string(op.kind)+op.text)
+		}
+		i = end
+	}
+	return out
+}
+
+type diffOp struct {
+	kind rune // ' ', '-' or '+'
+	text string
+	// expectedLine and actualLine are the (1 based) line numbers
+	// the op is at.
+	expectedLine, actualLine int
+}
+
+// editScript gets the operations that turn expected into actual,
+// using the longest common subsequence of lines.
+func editScript(expected, actual []string) []diffOp {
+	n, m := len(expected), len(actual)
+	var ops []diffOp
+	if n*m > maxDiffCells {
+		for i, line := range expected {
+			ops = append(ops, diffOp{kind: '-', text: line, expectedLine: i + 1, actualLine: 1})
+		}
+		for i, line := range actual {
+			ops = append(ops, diffOp{kind: '+', text: line, expectedLine: n + 1, actualLine: i + 1})
+		}
+		return ops
+	}
+	// lcs[i][j] is the length of the longest common subsequence
+	// of expected[i:] and actual[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && expected[i] == actual[j]:
+			ops = append(ops, diffOp{kind: ' ', text: expected[i], expectedLine: i + 1, actualLine: j + 1})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', text: expected[i], expectedLine: i + 1, actualLine: j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: actual[j], expectedLine: i + 1, actualLine: j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+// diffData gets the differences between the expected and actual
+// data (decoded from JSON), described by their path.
+func diffData(path string, expected, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for k := range e {
+			keys[k] = true
+		}
+		for k := range a {
+			keys[k] = true
+		}
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		var diffs []string
+		for _, k := range sorted {
+			ev, inExpected := e[k]
+			av, inActual := a[k]
+			switch {
+			case !inActual:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: expected %s  actual: (missing)", path, k, jsonString(ev)))
+			case !inExpected:
+				diffs = append(diffs, fmt.Sprintf("%s.%s: unexpected %s", path, k, jsonString(av)))
+			default:
+				diffs = append(diffs, diffData(path+"."+k, ev, av)...)
+			}
+		}
+		return diffs
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []string
+		if len(e) != len(a) {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %d items  actual %d items", path, len(e), len(a)))
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			diffs = append(diffs, diffData(path+"["+strconv.Itoa(i)+"]", e[i], a[i])...)
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: expected %s  actual %s", path, jsonString(expected), jsonString(actual))}
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestDiffLines(t *testing.T) {
+	is := is.New(t)
+	expected := strings.Split("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl", "\n")
+	actual := strings.Split("a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm", "\n")
+	is.Equal(strings.Join(diffLines(expected, actual), "\n"), `@@ -1,6 +1,6 @@
+ a
+ b
+-c
++C
+ d
+ e
+ f
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m`)
+	is.Equal(len(diffLines(expected, expected)), 0)
+}
+
+func TestDiffLinesTooBig(t *testing.T) {
+	is := is.New(t)
+	expected := strings.Split(strings.Repeat("a\n", 1000), "\n")
+	actual := strings.Split(strings.Repeat("b\n", 1000), "\n")
+	ops := editScript(expected, actual)
+	is.Equal(len(ops), len(expected)+len(actual))
+	is.Equal(ops[0].kind, '-')
+	is.Equal(ops[len(ops)-1].kind, '+')
+}
+
+func TestDiffData(t *testing.T) {
+	is := is.New(t)
+	expected := map[string]interface{}{
+		"id":    "123",
+		"name":  "Mat",
+		"tags":  []interface{}{"a", "b"},
+		"owner": map[string]interface{}{"name": "Mat"},
+	}
+	actual := map[string]interface{}{
+		"id":    float64(123),
+		"tags":  []interface{}{"a", "c", "d"},
+		"owner": map[string]interface{}{"name": "David"},
+		"extra": true,
+	}
+	is.Equal(diffData("Data", expected, actual), []string{
+		`Data.extra: unexpected true`,
+		`Data.id: expected "123"  actual 123`,
+		`Data.name: expected "Mat"  actual: (missing)`,
+		`Data.owner.name: expected "Mat"  actual "David"`,
+		`Data.tags: expected 2 items  actual 3 items`,
+		`Data.tags[1]: expected "b"  actual "c"`,
+	})
+	is.Equal(len(diffData("Data", expected, expected)), 0)
+}
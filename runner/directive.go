@@ -0,0 +1,255 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Directives are request details that control how the request
+// is run, rather than being sent as headers.
+const (
+	// directiveDataFile runs the request once for each row in
+	// a CSV or JSON data file.
+	//     * DataFile: ./users.csv
+	directiveDataFile = "DataFile"
+	// directiveRepeat runs the request the specified number of
+	// times, stopping at the first failure.
+	//     * Repeat: 10
+	directiveRepeat = "Repeat"
+	// directiveSkipIf skips the request if the condition is true.
+	//     * SkipIf: ${CI} == "true"
+	directiveSkipIf = "SkipIf"
+	// directiveTags tags the request, or the group if used before
+	// any requests.
+	//     * Tags: smoke, slow
+	directiveTags = "Tags"
+	// directiveProxy makes requests through a proxy, or directly,
+	// for the request, or the group if used before any requests.
+	//     * Proxy: http://proxy:3128
+	//     * Proxy: direct
+	directiveProxy = "Proxy"
+	// directiveConnectTo connects to the address (with an
+	// optional port) instead of the host of the URL, which is
+	// still used for the Host header and TLS, for the request, or
+	// the group if used before any requests.
+	//     * ConnectTo: 10.0.0.5:8443
+	directiveConnectTo = "ConnectTo"
+	// directiveExpectedBodyFile compares the response body with
+	// the contents of a file, instead of an expected body in the
+	// silk file. It goes with the assertions, after the ===.
+	//     * ExpectedBodyFile: ./golden/user.json
+	directiveExpectedBodyFile = "ExpectedBodyFile"
+	// directiveSaveBody writes the response body to a file,
+	// relative to the silk file, whether or not the assertions
+	// pass.
+	//     * SaveBody: ./out/user-{{.id}}.json
+	directiveSaveBody = "SaveBody"
+	// directiveThrottle makes no more than the number of requests
+	// per second, for the request, or the group if used before any
+	// requests.
+	//     * Throttle: 5
+	directiveThrottle = "Throttle"
+	// directivePollUntil makes the request again, waiting for the
+	// interval (one second by default) between attempts, until its
+	// assertions pass or the timeout passes.
+	//     * PollUntil: 10s, 500ms
+	directivePollUntil = "PollUntil"
+	// directiveAfter runs the group after the groups with the
+	// titles, and skips it if any of them fail. It goes before any
+	// requests.
+	//     * After: Create user, Log in
+	directiveAfter = "After"
+	// directiveQuarantine quarantines a request that is known to
+	// be broken, or every request in the group if used before any
+	// requests, with the ticket that tracks it. It still runs, but
+	// its failures are reported as quarantined, and don't fail the
+	// run.
+	//     * Quarantine: TICKET-123
+	directiveQuarantine = "Quarantine"
+	// directiveExpectFail documents a known bug: the request is
+	// expected to fail, and failing passes, but passing fails, so
+	// that a fix gets noticed.
+	//     * ExpectFail: TICKET-456 totals are rounded
+	directiveExpectFail = "ExpectFail"
+	// directiveWait is how long a CONSUME step waits for a message
+	// that meets its expectations (ten seconds by default).
+	//     * Wait: 30s
+	directiveWait = "Wait"
+	// directiveCapture stores a header, the Status, the Body or a
+	// Data field of the response in a variable, once its
+	// assertions pass. It goes with the assertions, after the ===.
+	//     * Capture: Location => {createdURL}
+	directiveCapture = "Capture"
+	// directiveRawQuery sends the query string exactly as it is
+	// written, without encoding it again. Any parameters are added
+	// after it.
+	//     * RawQuery: a=1&b=%2Fx
+	directiveRawQuery = "RawQuery"
+	// directiveExpectContinue sends the body with the 100-continue
+	// handshake: the request has an Expect: 100-continue header, and
+	// the body is only sent once the server answers with 100
+	// Continue, or the time passes without an answer. Servers may
+	// reject the request without asking for the body. It applies to
+	// the request, or the group if used before any requests.
+	//     * ExpectContinue: 1s
+	directiveExpectContinue = "ExpectContinue"
+	// directiveIdempotencyKey sends an Idempotency-Key header with
+	// the key, or with a fresh key for the request in each run if
+	// it is new. Repeats of the request use the same fresh key.
+	//     * IdempotencyKey: new
+	//     * IdempotencyKey: order-{{.orderID}}
+	directiveIdempotencyKey = "IdempotencyKey"
+	// directiveSnippet makes the request a snippet, a template for
+	// requests in the file that use it, which isn't run itself.
+	//     * Snippet: createOrder
+	directiveSnippet = "Snippet"
+	// directiveUse runs the request with the details, parameters,
+	// bodies and scripts of the snippet that it doesn't have itself,
+	// and the arguments as variables.
+	//     * Use: createOrder sku=abc qty=2
+	directiveUse = "Use"
+)
+
+// iterationVar is the variable holding the (1 based) iteration
+// number of a repeated request.
+const iterationVar = "iteration"
+
+// directives maps the directives to functions that check
+// their values, or nil if any value is allowed.
+var directives = map[string]func(*parse.Value) error{
+	directiveDataFile:         nil,
+	directiveRepeat:           checkPositiveInt,
+	directiveSkipIf:           checkCondition,
+	directiveTags:             nil,
+	directiveProxy:            checkProxy,
+	directiveConnectTo:        checkConnectToValue,
+	directiveExpectedBodyFile: nil,
+	directiveSaveBody:         nil,
+	directiveThrottle:         checkPositive,
+	directivePollUntil:        checkPollUntil,
+	directiveAfter:            nil,
+	directiveQuarantine:       checkNotEmpty,
+	directiveExpectFail:       checkNotEmpty,
+	directiveWait:             checkWait,
+	directiveCapture:          checkCapture,
+	directiveRawQuery:         nil,
+	directiveExpectContinue:   checkWait,
+	directiveIdempotencyKey:   checkNotEmpty,
+	directiveSnippet:          checkNotEmpty,
+	directiveUse:              checkUse,
+}
+
+// responseDirectives are the directives that go with the
+// assertions, after the ===. Any other details there are
+// assertions, even if they have the name of a request directive.
+var responseDirectives = map[string]bool{
+	directiveExpectedBodyFile: true,
+	directiveSaveBody:         true,
+	directiveCapture:          true,
+}
+
+// IsDirective gets whether the key of a request detail is a
+// directive, rather than a header to send with the request.
+func IsDirective(key string) bool {
+	_, ok := directives[key]
+	return ok
+}
+
+// IsResponseDirective gets whether the key of an expected detail
+// is a directive, rather than an assertion.
+func IsResponseDirective(key string) bool {
+	return responseDirectives[key]
+}
+
+var errNotPositiveInt = errors.New("expected a positive whole number")
+
+func checkPositiveInt(v *parse.Value) error {
+	n, ok := v.Data.(float64)
+	if !ok || n < 1 || n != math.Trunc(n) {
+		return errNotPositiveInt
+	}
+	return nil
+}
+
+var errNotPositive = errors.New("expected a positive number")
+
+func checkPositive(v *parse.Value) error {
+	if n, ok := v.Data.(float64); !ok || n <= 0 {
+		return errNotPositive
+	}
+	return nil
+}
+
+var errEmpty = errors.New("expected a value")
+
+func checkNotEmpty(v *parse.Value) error {
+	if strings.TrimSpace(fmt.Sprintf("%v", v.Data)) == "" {
+		return errEmpty
+	}
+	return nil
+}
+
+var errNotDuration = errors.New("expected a positive duration, like 10s")
+
+func checkWait(v *parse.Value) error {
+	if d, err := time.ParseDuration(fmt.Sprintf("%v", v.Data)); err != nil || d <= 0 {
+		return errNotDuration
+	}
+	return nil
+}
+
+func checkProxy(v *parse.Value) error {
+	s := fmt.Sprintf("%v", v.Data)
+	if s == proxyDirect {
+		return nil
+	}
+	_, err := parseProxy(s)
+	return err
+}
+
+func checkConnectToValue(v *parse.Value) error {
+	return checkConnectTo(fmt.Sprintf("%v", v.Data))
+}
+
+func checkCondition(v *parse.Value) error {
+	// environment variables are not known yet, so only check
+	// the syntax
+	_, err := evalCondition(fmt.Sprintf("%v", v.Data))
+	return err
+}
+
+// directive gets the line for the specified directive, or nil
+// if it is not present.
+func directive(lines parse.Lines, key string) *parse.Line {
+	for _, line := range lines {
+		if line.Detail() != nil && line.Detail().Key == key {
+			return line
+		}
+	}
+	return nil
+}
+
+// quarantine gets the ticket of the Quarantine directive of the
+// request, or its group, or "" if it isn't quarantined.
+func quarantine(group *parse.Group, req *parse.Request) string {
+	for _, lines := range []parse.Lines{req.Details, group.Details} {
+		if line := directive(lines, directiveQuarantine); line != nil {
+			return fmt.Sprintf("%v", line.Detail().Value.Data)
+		}
+	}
+	return ""
+}
+
+// expectFail gets the reason of the ExpectFail directive of the
+// request, or "" if it is expected to pass.
+func expectFail(req *parse.Request) string {
+	if line := directive(req.Details, directiveExpectFail); line != nil {
+		return fmt.Sprintf("%v", line.Detail().Value.Data)
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// dryRun logs the request instead of making it, and stores a
+// placeholder for each value it would capture, like {userID}, so
+// that later requests that use them can be shown too.
+func (r *Runner) dryRun(c *call, req *http.Request, body string) {
+	r.Log(c.redact(requestText(req, body)))
+	for _, line := range c.req.ExpectedDetails {
+		for _, name := range captureNames(line.Detail().Value) {
+			c.vars[name] = "{" + name + "}"
+		}
+	}
+	for _, name := range captures(c.req.ExpectedDetails) {
+		c.vars[name] = "{" + name + "}"
+	}
+	r.skip(c, c.req.Number, "dry run")
+}
+
+// requestText gets the request as text, like it is sent over the
+// wire: the method and URL, the headers sorted by name, and the
+// body. The path isn't escaped, so placeholders are readable.
+func requestText(req *http.Request, body string) string {
+	var b strings.Builder
+	u := req.URL
+	s := u.Scheme + "://" + u.Host + u.Path
+	if u.RawQuery != "" {
+		s += "?" + u.RawQuery
+	}
+	fmt.Fprintln(&b, req.Method, s)
+	if req.Host != "" && req.Host != req.URL.Host {
+		fmt.Fprintln(&b, "Host:", req.Host)
+	}
+	var keys []string
+	for k := range req.Header {
+		// like curl, the length is left to the body
+		if k == "Content-Length" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			fmt.Fprintf(&b, "%s: %s\n", k, v)
+		}
+	}
+	if body != "" {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, body)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// captureNames gets the names of the capture groups of a regex
+// value.
+func captureNames(v *parse.Value) []string {
+	if v.Type() != "regex" {
+		return nil
+	}
+	s := v.Data.(string)
+	regex, err := regexp.Compile(s[1 : len(s)-1])
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
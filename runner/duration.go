@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"strings"
+	"time"
+)
+
+// durationMatcher compares durations, like <= 30m, with durations
+// (like "1m30s") or seconds.
+type durationMatcher struct {
+	expected string
+	op       string
+	d        time.Duration
+}
+
+// parseDuration parses a comparison with a duration. Only values
+// starting with an operator are comparisons, so that plain strings
+// like "30m" are still compared as strings.
+func parseDuration(s string) (matcher, error) {
+	s = strings.TrimSpace(s)
+	for _, op := range comparisonOps {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(s[len(op):]))
+		if err != nil {
+			return nil, nil
+		}
+		return &durationMatcher{expected: s, op: op, d: d}, nil
+	}
+	return nil, nil
+}
+
+// duration gets the actual value as a duration, parsing strings
+// like "1m30s" and treating numbers as seconds.
+func duration(actual interface{}) (time.Duration, bool) {
+	if s, ok := actual.(string); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(s)); err == nil {
+			return d, true
+		}
+	}
+	n, ok := number(actual)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n * float64(time.Second)), true
+}
+
+func (m *durationMatcher) match(actual interface{}) bool {
+	d, ok := duration(actual)
+	if !ok {
+		return false
+	}
+	cmp := comparison{op: m.op, n: m.d.Seconds()}
+	return cmp.match(d.Seconds())
+}
+
+func (m *durationMatcher) example() interface{} {
+	switch m.op {
+	case "<":
+		return (m.d / 2).String()
+	case ">":
+		return (m.d * 2).String()
+	case "!=":
+		return (m.d + time.Second).String()
+	}
+	return m.d.String()
+}
+
+func (m *durationMatcher) String() string {
+	return m.expected
+}
@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Keys of the expected details of exec steps.
+const (
+	exitCodeKey = "ExitCode"
+	stdoutKey   = "Stdout"
+	stderrKey   = "Stderr"
+)
+
+// exec runs the command of an exec step, in the directory of its
+// file, with its body as the standard input, and asserts the exit
+// code, output and Data (the output parsed as JSON). Commands that
+// exit with a code other than 0 fail, unless ExitCode is asserted.
+func (r *Runner) exec(c *call) bool {
+	helperOf(c.t).Helper()
+	c.start = time.Now()
+	req := c.req
+	tplData := r.templateData(c)
+	funcs := r.funcs()
+	command, err := interpolate(string(req.Path), tplData, funcs)
+	if err == nil {
+		command, err = r.revealSecrets(c, command)
+	}
+	if err != nil {
+		r.fail(c, req.Number, "invalid command:", err)
+		return false
+	}
+	stdin, err := interpolate(req.Body.String(), tplData, funcs)
+	if err == nil {
+		stdin, err = r.revealSecrets(c, stdin)
+	}
+	if err != nil {
+		r.fail(c, req.Number, "invalid command:", err)
+		return false
+	}
+	r.Verbose(parse.MethodExec, c.redact(command))
+	r.logVars(c)
+	c.requestBody = stdin
+	if r.DryRun {
+		r.Log(c.redact(parse.MethodExec + " " + command))
+		r.skip(c, req.Number, "dry run")
+		return true
+	}
+
+	cmd := shellCommand(r.context(), command)
+	cmd.Dir = filepath.Dir(c.group.Filename)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			r.fail(c, req.Number, err)
+			return false
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	r.Verbose(indent, exitCodeKey+":", exitCode)
+	c.responseBody = stdout.Bytes()
+
+	values := map[string]interface{}{
+		exitCodeKey: float64(exitCode),
+		stdoutKey:   strings.TrimRight(stdout.String(), "\n"),
+		stderrKey:   strings.TrimRight(stderr.String(), "\n"),
+	}
+	data, errData := r.ParseBody(bytes.NewReader(stdout.Bytes()))
+	asserted, ok := r.assertStep(c, values, data, errData)
+	if !ok {
+		return false
+	}
+	if exitCode != 0 && !asserted[exitCodeKey] {
+		c.log(stderrKey, values[stderrKey])
+		r.fail(c, req.Number, "exit code", exitCode)
+		return false
+	}
+	if reason := expectFail(req); reason != "" {
+		r.reportFailure(c, req.Number, "passed, but expected to fail:", reason)
+		return false
+	}
+	r.report(c, Passed, req.Number)
+	return true
+}
+
+// assertStep asserts the expected details of a step that isn't an
+// HTTP request, which are the step's values (like ExitCode) and
+// Data. It gets the keys that were asserted.
+func (r *Runner) assertStep(c *call, values map[string]interface{}, data interface{}, errData error) (map[string]bool, bool) {
+	asserted := make(map[string]bool)
+	tplData := r.templateData(c)
+	funcs := r.funcs()
+	for _, line := range c.req.ExpectedDetails {
+		line, err := interpolateLine(line, tplData, funcs)
+		if err != nil {
+			r.fail(c, line.Number, err)
+			return asserted, false
+		}
+		detail := line.Detail()
+		if IsResponseDirective(detail.Key) {
+			continue
+		}
+		var ok bool
+		if strings.HasPrefix(detail.Key, "Data") {
+			ok = r.assertData(c, data, errData, detail.Key, detail.Value)
+		} else if actual, present := values[detail.Key]; present {
+			ok = r.assertDetail(c, detail.Key, actual, detail.Value)
+		} else {
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			c.log(detail.Key, fmt.Sprintf("%s steps only assert %s and Data", c.req.Method, strings.Join(keys, ", ")))
+		}
+		asserted[detail.Key] = true
+		c.assertions = append(c.assertions, Assertion{Line: line.Number, Text: strings.TrimSpace(string(line.Bytes)), Passed: ok})
+		if !ok {
+			r.fail(c, line.Number, detail.Key+" doesn't match")
+			return asserted, false
+		}
+	}
+	return asserted, true
+}
+
+// shellCommand makes a command that runs the command line with the
+// shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Subject is what an assertion is about.
+type Subject int
+
+const (
+	// SubjectHeader assertions are about a response header.
+	SubjectHeader Subject = iota
+	// SubjectStatus assertions are about the status code.
+	SubjectStatus
+	// SubjectData assertions are about the data in the body, or a
+	// field of it.
+	SubjectData
+	// SubjectCookie assertions are about a cookie set by the
+	// response, or one of its attributes.
+	SubjectCookie
+	// SubjectBodySize assertions are about the length of the body.
+	SubjectBodySize
+	// SubjectAssert assertions are made by an Asserter, which only
+	// the Runner has. Name is the name of the Asserter, and Value
+	// its argument.
+	SubjectAssert
+	// SubjectXPath assertions are about the value at an XPath in an
+	// XML body. Name is the XPath.
+	SubjectXPath
+	// SubjectStream assertions are about how the response arrived,
+	// its FirstByte and Chunks, or whether it was after a 100
+	// Continue, which Name is.
+	SubjectStream
+	// SubjectCORS assertions are about whether the response allows
+	// the cross-origin request. Value is allowed or denied.
+	SubjectCORS
+	// SubjectIdempotent assertions are about whether the response is
+	// the same as the first response to its Idempotency-Key.
+	SubjectIdempotent
+)
+
+// Match is how an actual value is compared with the expected value.
+type Match int
+
+const (
+	// MatchEqual values are equal.
+	MatchEqual Match = iota
+	// MatchRegex values match the /regex/.
+	MatchRegex
+	// MatchIgnoreCase values are equal, regardless of case.
+	MatchIgnoreCase
+	// MatchApprox values are numbers within the tolerance of the
+	// expected value.
+	MatchApprox
+	// MatchAbsent headers are not present.
+	MatchAbsent
+	// MatchNull fields are present and null.
+	MatchNull
+	// MatchMissing fields are not present.
+	MatchMissing
+	// MatchEmpty lists are missing, null or empty, like the errors
+	// of GraphQLErrors: empty.
+	MatchEmpty
+	// MatchCustom values are checked by another matcher, like
+	// times and durations, which only the Runner understands.
+	MatchCustom
+)
+
+// Expectation is what an expected detail (after the ===) asserts,
+// for tools that need to understand assertions without checking
+// them, like mock servers and code generators.
+type Expectation struct {
+	// Subject is what the assertion is about.
+	Subject Subject
+	// Name is the name of the header or cookie, or the path of the
+	// Data field (like .items[0].id, or empty for all of the data).
+	Name string
+	// Index is the index of the value of a repeated header, as in
+	// Set-Cookie[1], or -1 if the assertion is about the header.
+	Index int
+	// Attr is the attribute of the cookie, or empty for its value.
+	Attr string
+	// Match is how the actual value is compared with Value.
+	Match Match
+	// Value is the expected value, without any (ignorecase) prefix,
+	// or the number for MatchApprox.
+	Value interface{}
+	// Tolerance is the tolerance of MatchApprox.
+	Tolerance float64
+
+	matcher matcher
+}
+
+// Expect gets the Expectation of an expected detail, or an error
+// if its value is not a valid matcher (like 10.5 ± -1).
+// Directives (see IsResponseDirective) are not expectations.
+func Expect(detail *parse.Detail) (*Expectation, error) {
+	e := &Expectation{Name: detail.Key, Index: -1, Value: detail.Value.Data}
+	errorsKey, isErrors := graphQLErrorsData(detail.Key)
+	switch {
+	case detail.Key == "Status":
+		e.Subject = SubjectStatus
+	case detail.Key == bodySizeKey:
+		// sizes are compared by the Runner
+		e.Subject = SubjectBodySize
+		return e, nil
+	case detail.Key == firstByteKey || detail.Key == chunksKey || detail.Key == continueKey:
+		// streams are measured by the Runner
+		e.Subject = SubjectStream
+		return e, nil
+	case detail.Key == corsKey:
+		// the request headers are needed too
+		e.Subject = SubjectCORS
+		return e, nil
+	case detail.Key == idempotentKey:
+		// the first response is only known to the Runner
+		e.Subject = SubjectIdempotent
+		return e, nil
+	case detail.Key == assertKey:
+		name, arg, err := parseAssert(fmt.Sprintf("%v", detail.Value.Data))
+		if err != nil {
+			return nil, err
+		}
+		e.Subject, e.Name, e.Value, e.Match = SubjectAssert, name, arg, MatchCustom
+		return e, nil
+	case isErrors:
+		e.Subject, e.Name = SubjectData, strings.TrimPrefix(errorsKey, "Data")
+		if isEmpty(detail.Key, detail.Value) {
+			e.Match = MatchEmpty
+			return e, nil
+		}
+	case strings.HasPrefix(detail.Key, xpathPrefix):
+		e.Subject = SubjectXPath
+		e.Name, _ = xpathKey(detail.Key)
+	case strings.HasPrefix(detail.Key, "Data"):
+		e.Subject = SubjectData
+		e.Name = strings.TrimPrefix(detail.Key, "Data")
+	default:
+		if name, attr, ok := cookieKey(detail.Key); ok {
+			e.Subject, e.Name, e.Attr = SubjectCookie, name, attr
+		} else if name, i, ok := headerIndex(detail.Key); ok {
+			e.Name, e.Index = name, i
+		}
+	}
+	if value, ok := ignoreCase(detail.Value); ok {
+		e.Match, e.Value = MatchIgnoreCase, value
+		return e, nil
+	}
+	switch {
+	case isAbsent(detail.Value):
+		e.Match = MatchAbsent
+		return e, nil
+	case isNull(detail.Value):
+		e.Match, e.Value = MatchNull, nil
+		return e, nil
+	case isMissing(detail.Value):
+		e.Match = MatchMissing
+		return e, nil
+	case detail.Value.Type() == "regex":
+		e.Match = MatchRegex
+		return e, nil
+	}
+	m, err := parseMatcher(detail.Value)
+	if err != nil {
+		return nil, err
+	}
+	switch m := m.(type) {
+	case nil:
+	case *approx:
+		e.Match, e.Value, e.Tolerance = MatchApprox, m.value, m.tolerance
+	default:
+		e.Match, e.matcher = MatchCustom, m
+	}
+	return e, nil
+}
+
+// Example gets a value that meets the expectation, like 10.5 for
+// 10.5 ± 0.01, and whether there is one. There isn't one for
+// absent or missing values, regexes, Asserters, streams, CORS or
+// idempotency.
+func (e *Expectation) Example() (interface{}, bool) {
+	if e.Subject == SubjectAssert || e.Subject == SubjectStream || e.Subject == SubjectCORS || e.Subject == SubjectIdempotent {
+		return nil, false
+	}
+	switch e.Match {
+	case MatchAbsent, MatchMissing, MatchEmpty, MatchRegex:
+		return nil, false
+	case MatchCustom:
+		return e.matcher.example(), true
+	}
+	return e.Value, true
+}
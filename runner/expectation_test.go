@@ -0,0 +1,63 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func TestExpect(t *testing.T) {
+	is := is.New(t)
+	for src, expected := range map[string]runner.Expectation{
+		"* Status: 201":                           {Subject: runner.SubjectStatus, Name: "Status", Index: -1, Value: 201.0},
+		"* Data.items[0].id: /[0-9]+/":            {Subject: runner.SubjectData, Name: ".items[0].id", Index: -1, Match: runner.MatchRegex, Value: "/[0-9]+/"},
+		"* Data.total: 10.5 ± 0.01":               {Subject: runner.SubjectData, Name: ".total", Index: -1, Match: runner.MatchApprox, Value: 10.5, Tolerance: 0.01},
+		"* Data.label: \"a ~= b\"":                {Subject: runner.SubjectData, Name: ".label", Index: -1, Value: "a ~= b"},
+		"* Data.deleted: (null)":                  {Subject: runner.SubjectData, Name: ".deleted", Index: -1, Match: runner.MatchNull},
+		"* Data.password: (missing)":              {Subject: runner.SubjectData, Name: ".password", Index: -1, Match: runner.MatchMissing, Value: "(missing)"},
+		"* X-Debug: (absent)":                     {Subject: runner.SubjectHeader, Name: "X-Debug", Index: -1, Match: runner.MatchAbsent, Value: "(absent)"},
+		"* Content-Type: (ignorecase) \"TEXT/A\"": {Subject: runner.SubjectHeader, Name: "Content-Type", Index: -1, Match: runner.MatchIgnoreCase, Value: "TEXT/A"},
+		"* Set-Cookie[1]: \"b=2\"":                {Subject: runner.SubjectHeader, Name: "Set-Cookie", Index: 1, Value: "b=2"},
+		"* Cookie.session.HttpOnly: true":         {Subject: runner.SubjectCookie, Name: "session", Attr: "HttpOnly", Index: -1, Value: true},
+		"* BodySize: < 1024":                      {Subject: runner.SubjectBodySize, Name: "BodySize", Index: -1, Value: "< 1024"},
+		"* GraphQLErrors: empty":                  {Subject: runner.SubjectData, Name: ".errors", Index: -1, Match: runner.MatchEmpty, Value: "empty"},
+		"* GraphQLErrors[0].message: \"denied\"":  {Subject: runner.SubjectData, Name: ".errors[0].message", Index: -1, Value: "denied"},
+		"* XPath(//user/id): 7":                   {Subject: runner.SubjectXPath, Name: "//user/id", Index: -1, Value: 7.0},
+	} {
+		e, err := expect(t, src)
+		is.NoErr(err)
+		is.Equal(*e, expected)
+	}
+
+	e, err := expect(t, "* Data.created: (time) within 1m of now")
+	is.NoErr(err)
+	is.Equal(e.Match, runner.MatchCustom)
+	_, ok := e.Example()
+	is.True(ok)
+
+	e, err = expect(t, "* Data.id: uuid()")
+	is.NoErr(err)
+	is.Equal(e.Match, runner.MatchCustom)
+	example, ok := e.Example()
+	is.True(ok)
+	is.Equal(example, "123e4567-e89b-12d3-a456-426614174000")
+
+	e, err = expect(t, "* Data.id: /[0-9]+/")
+	is.NoErr(err)
+	_, ok = e.Example()
+	is.False(ok)
+
+	_, err = expect(t, "* Data.total: 10.5 ± -1")
+	is.Err(err)
+}
+
+// expect gets the Expectation of the detail in the line of source.
+func expect(t *testing.T, src string) (*runner.Expectation, error) {
+	line, err := parse.ParseLine(0, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runner.Expect(line.Detail())
+}
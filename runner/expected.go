@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/matryer/silk/parse"
+)
+
+var errExpectedBodyTwice = errors.New("the request also has an expected body")
+
+// ExpectedBody gets the expected body of the request, and the line
+// number it is on. The body is either in the silk file, or in the
+// file given by an ExpectedBodyFile directive, relative to the silk
+// file. Bodies in base64 code blocks are decoded.
+// Gets nil if no body is expected.
+func ExpectedBody(group *parse.Group, req *parse.Request) ([]byte, int, error) {
+	line := directive(req.ExpectedDetails, directiveExpectedBodyFile)
+	if line == nil {
+		if len(req.ExpectedBody) == 0 {
+			return nil, 0, nil
+		}
+		if req.ExpectedBodyLang == langBase64 {
+			body, err := decodeBase64(req.ExpectedBody)
+			return body, req.ExpectedBody.Number(), err
+		}
+		return []byte(req.ExpectedBody.String()), req.ExpectedBody.Number(), nil
+	}
+	if len(req.ExpectedBody) > 0 {
+		return nil, line.Number, fmt.Errorf("%s: %v", directiveExpectedBodyFile, errExpectedBodyTwice)
+	}
+	filename := filepath.Join(filepath.Dir(group.Filename), fmt.Sprintf("%v", line.Detail().Value.Data))
+	body, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, line.Number, fmt.Errorf("%s: %v", directiveExpectedBodyFile, err)
+	}
+	return body, line.Number, nil
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	fakeFirstNames = []string{"Mat", "David", "Ryan", "Ada", "Grace", "Alan", "Edsger", "Barbara", "Ken", "Rob", "Linus", "Margaret", "Dennis", "Frances", "Niklaus", "Radia"}
+	fakeLastNames  = []string{"Ryer", "Hernandez", "Lovelace", "Hopper", "Turing", "Dijkstra", "Liskov", "Thompson", "Pike", "Torvalds", "Hamilton", "Ritchie", "Allen", "Wirth", "Perlman", "Knuth"}
+	fakeCompanies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Vandelay", "Stark", "Wayne", "Tyrell", "Cyberdyne"}
+	fakeCities     = []string{"London", "Boulder", "Bangkok", "Lisbon", "Toronto", "Nairobi", "Osaka", "Berlin", "Melbourne", "Bogotá"}
+)
+
+// faker generates realistic fake data, like names and emails, for
+// the fake template function, as in {{fake.Email}}.
+// Emails and usernames are unique within a run, with a random part
+// so they are very unlikely to collide with those of earlier runs
+// on unique constraints, unless the runs have the same Seed.
+type faker struct {
+	rnd *random
+}
+
+// FirstName gets a first name, like Ada.
+func (f faker) FirstName() string {
+	return fakeFirstNames[f.rnd.intn(len(fakeFirstNames))]
+}
+
+// LastName gets a last name, like Lovelace.
+func (f faker) LastName() string {
+	return fakeLastNames[f.rnd.intn(len(fakeLastNames))]
+}
+
+// Name gets a full name, like Ada Lovelace.
+func (f faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username gets a unique username, like ada.lovelace.k3x9q1.
+func (f faker) Username() string {
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s%d", f.FirstName(), f.LastName(), f.rnd.randString(5), f.rnd.next()))
+}
+
+// Email gets a unique email address at example.com, which is
+// reserved for examples, like ada.lovelace.k3x9q1@example.com.
+func (f faker) Email() string {
+	return f.Username() + "@example.com"
+}
+
+// Phone gets a phone number in the range reserved for fiction,
+// like +1-555-0142.
+func (f faker) Phone() string {
+	return fmt.Sprintf("+1-555-01%02d", f.rnd.intn(100))
+}
+
+// Company gets the name of a company, like Initech.
+func (f faker) Company() string {
+	return fakeCompanies[f.rnd.intn(len(fakeCompanies))]
+}
+
+// City gets the name of a city, like Lisbon.
+func (f faker) City() string {
+	return fakeCities[f.rnd.intn(len(fakeCities))]
+}
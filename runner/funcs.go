@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"text/template"
+	"time"
+)
+
+// builtinFuncs are the functions available to every template,
+// along with those that make random values (see randomFuncs).
+//
+//	now                     - the current time.Time
+//	date "2006-01-02"       - the current time in the given layout
+//	base64 "text"           - the text base64 encoded
+//	base64Decode "dGV4dA==" - the base64 data decoded
+//	sha256 "text"           - the SHA-256 hash of the text, in hex
+//	hmacSHA256 "key" "text" - the HMAC-SHA256 of the text, in hex
+//	add 1 2                 - the sum of two numbers
+//	pathEscape "a/b"        - the text escaped as a path segment
+//	queryEscape "a b"       - the text escaped as a query value
+var builtinFuncs = template.FuncMap{
+	"now":          time.Now,
+	"date":         func(layout string) string { return time.Now().Format(layout) },
+	"base64":       func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64Decode": base64Decode,
+	"sha256":       sha256Hex,
+	"hmacSHA256":   hmacSHA256,
+	"add":          add,
+	"pathEscape":   url.PathEscape,
+	"queryEscape":  url.QueryEscape,
+}
+
+func base64Decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key, s string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// add adds numbers, or strings of numbers, like page numbers.
+func add(a, b interface{}) (float64, error) {
+	x, okX := number(a)
+	y, okY := number(b)
+	if !okX || !okY {
+		return 0, fmt.Errorf("add: %v and %v are not both numbers", a, b)
+	}
+	return x + y, nil
+}
+
+// funcs gets the functions available to templates, made up of the
+// built-in functions and the Runner's Funcs.
+func (r *Runner) funcs() template.FuncMap {
+	funcs := make(template.FuncMap, len(builtinFuncs)+len(r.Funcs))
+	for k, v := range builtinFuncs {
+		funcs[k] = v
+	}
+	for k, v := range r.random().funcs() {
+		funcs[k] = v
+	}
+	for k, v := range r.Funcs {
+		funcs[k] = v
+	}
+	return funcs
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+)
+
+func TestBuiltinFuncs(t *testing.T) {
+	is := is.New(t)
+	r := New(nil, "")
+	funcs := r.funcs()
+	data := map[string]interface{}{}
+
+	s, err := interpolate("{{uuid}}", data, funcs)
+	is.NoErr(err)
+	is.True(regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(s))
+	s2, err := interpolate("{{uuid}}", data, funcs)
+	is.NoErr(err)
+	is.NotEqual(s, s2)
+
+	s, err = interpolate(`{{date "2006"}}`, data, funcs)
+	is.NoErr(err)
+	is.Equal(s, strconv.Itoa(time.Now().Year()))
+
+	s, err = interpolate(`{{now.Year}}`, data, funcs)
+	is.NoErr(err)
+	is.Equal(s, strconv.Itoa(time.Now().Year()))
+
+	for i := 0; i < 100; i++ {
+		s, err = interpolate(`{{randInt 5 10}}`, data, funcs)
+		is.NoErr(err)
+		n, err := strconv.Atoi(s)
+		is.NoErr(err)
+		is.True(n >= 5 && n < 10)
+	}
+	_, err = interpolate(`{{randInt 10 5}}`, data, funcs)
+	is.Err(err)
+
+	s, err = interpolate(`{{randString 12}}`, data, funcs)
+	is.NoErr(err)
+	is.True(regexp.MustCompile(`^[a-zA-Z0-9]{12}$`).MatchString(s))
+
+	s, err = interpolate(`{{base64 "silk:secret"}}`, data, funcs)
+	is.NoErr(err)
+	is.Equal(s, base64.StdEncoding.EncodeToString([]byte("silk:secret")))
+	s, err = interpolate(`{{base64Decode "c2lsaw=="}}`, data, funcs)
+	is.NoErr(err)
+	is.Equal(s, "silk")
+
+	s, err = interpolate(`{{fake.Email}}`, data, funcs)
+	is.NoErr(err)
+	is.True(regexp.MustCompile(`^[a-z]+\.[a-z]+\.[a-z0-9]+@example\.com$`).MatchString(s))
+	s2, err = interpolate(`{{fake.Email}}`, data, funcs)
+	is.NoErr(err)
+	is.NotEqual(s, s2)
+	s, err = interpolate(`{{fake.Name}}`, data, funcs)
+	is.NoErr(err)
+	is.True(regexp.MustCompile(`^[A-Z][a-z]+ [A-Z][a-z]+$`).MatchString(s))
+	s, err = interpolate(`{{fake.Phone}}`, data, funcs)
+	is.NoErr(err)
+	is.True(regexp.MustCompile(`^\+1-555-01[0-9]{2}$`).MatchString(s))
+	for _, name := range []string{"FirstName", "LastName", "Username", "Company", "City"} {
+		s, err = interpolate("{{fake."+name+"}}", data, funcs)
+		is.NoErr(err)
+		is.NotEqual(s, "")
+	}
+
+	r.Funcs = map[string]interface{}{
+		"shout": func(s string) string { return s + "!" },
+	}
+	s, err = interpolate(`{{shout "silk"}}`, data, r.funcs())
+	is.NoErr(err)
+	is.Equal(s, "silk!")
+
+}
+
+func TestSeed(t *testing.T) {
+	is := is.New(t)
+	values := func(seed int64) string {
+		r := New(nil, "")
+		r.Seed = seed
+		s, err := interpolate(`{{uuid}} {{randInt 0 1000}} {{randString 8}} {{fake.Email}}`, nil, r.funcs())
+		is.NoErr(err)
+		return s
+	}
+	is.Equal(values(42), values(42))
+	is.NotEqual(values(42), values(43))
+}
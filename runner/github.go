@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NewGitHubReporter makes a Reporter that writes GitHub Actions
+// workflow commands for failures (::error) and quarantined failures
+// (::warning), so they appear on the lines of the silk files in
+// pull requests. Filenames should be relative to the root of the
+// repository, so run silk from there.
+// Use MultiReporter to keep the default text output too.
+func NewGitHubReporter(w io.Writer) Reporter {
+	return &githubReporter{w: w}
+}
+
+type githubReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (g *githubReporter) Report(result *Result) {
+	var command string
+	switch result.Outcome {
+	case Failed:
+		command = "error"
+	case Quarantined:
+		command = "warning"
+	default:
+		return
+	}
+	title := result.Method + " " + result.Path
+	if result.Name != "" {
+		title += " (" + result.Name + ")"
+	}
+	message := result.Message
+	if ids := result.ids(); ids != "" {
+		message += " " + ids
+	}
+	if len(result.Details) > 0 {
+		message += "\n" + strings.Join(result.Details, "\n")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(g.w, "::%s file=%s,line=%s,title=%s::%s\n", command,
+		githubProperty(result.Filename), strconv.Itoa(result.Line), githubProperty(title), githubData(message))
+}
+
+// githubData escapes the message of a workflow command.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property of a workflow command.
+func githubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(githubData(s))
+}
@@ -0,0 +1,37 @@
+package runner_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestGitHubReporter(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	reporter := runner.NewGitHubReporter(&buf)
+	reporter.Report(&runner.Result{Outcome: runner.Passed, Filename: "users.silk.md", Line: 3})
+	reporter.Report(&runner.Result{
+		Filename: "testfiles/users.silk.md",
+		Line:     12,
+		Method:   "GET",
+		Path:     "/users/{id}",
+		Name:     "row 1, iteration 2",
+		Outcome:  runner.Failed,
+		Message:  "Data.rate doesn't match",
+		Details:  []string{"Data.rate expected float64: 100%  actual float64: 50%"},
+	})
+	reporter.Report(&runner.Result{
+		Filename:   "users.silk.md",
+		Line:       20,
+		Method:     "DELETE",
+		Path:       "/users/1",
+		Outcome:    runner.Quarantined,
+		Message:    "Status doesn't match",
+		Quarantine: "TICKET-1",
+	})
+	is.Equal(buf.String(), "::error file=testfiles/users.silk.md,line=12,title=GET /users/{id} (row 1%2C iteration 2)::Data.rate doesn't match%0AData.rate expected float64: 100%25  actual float64: 50%25\n"+
+		"::warning file=users.silk.md,line=20,title=DELETE /users/1::Status doesn't match\n")
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cheekybits/m"
+	"github.com/matryer/silk/parse"
+)
+
+// graphQLErrorsKey starts the keys of expected details about the
+// errors of GraphQL responses, like GraphQLErrors[0].message, which
+// are about the errors field of the data.
+const graphQLErrorsKey = "GraphQLErrors"
+
+// empty is the value of GraphQLErrors when there are no errors.
+const empty = "empty"
+
+// graphQLErrorsData gets the key of the Data that a GraphQLErrors
+// key is about, and whether it is one.
+func graphQLErrorsData(key string) (string, bool) {
+	if !strings.HasPrefix(key, graphQLErrorsKey) {
+		return "", false
+	}
+	rest := key[len(graphQLErrorsKey):]
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		return "", false
+	}
+	return "Data.errors" + rest, true
+}
+
+// isEmpty gets whether the value of GraphQLErrors asserts that
+// there are no errors.
+func isEmpty(key string, v *parse.Value) bool {
+	return key == graphQLErrorsKey && v.Data == empty
+}
+
+// assertGraphQLErrors asserts the errors of a GraphQL response.
+// GraphQLErrors: empty passes if the errors are missing, null or an
+// empty list, and other keys are asserted like the Data they are
+// about.
+func (r *Runner) assertGraphQLErrors(c *call, data interface{}, errData error, key string, expected *parse.Value) bool {
+	dataKey, _ := graphQLErrorsData(key)
+	if !isEmpty(key, expected) {
+		return r.assertData(c, data, errData, dataKey, expected)
+	}
+	if errData != nil {
+		c.log(key, fmt.Sprintf("expected %s  actual: failed to parse body: %s", empty, errData))
+		return false
+	}
+	actual, ok := m.GetOK(map[string]interface{}{"Data": data}, dataKey)
+	if list, isList := actual.([]interface{}); !ok || actual == nil || isList && len(list) == 0 {
+		return true
+	}
+	c.log(key, fmt.Sprintf("expected %s  actual: %s", empty, jsonString(actual)))
+	return false
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// handlerRootURL is the root URL of Runners made by NewForHandler.
+const handlerRootURL = "http://localhost"
+
+// NewForHandler makes a new Runner that makes requests to the
+// handler in-process, without listening on a port.
+//
+//	runner.NewForHandler(t, handler).RunGlob(filepath.Glob("*.silk.md"))
+//
+// Unlike a real server, requests don't get the headers the
+// http.Transport adds, like User-Agent and Accept-Encoding.
+func NewForHandler(t T, handler http.Handler) *Runner {
+	r := New(t, handlerRootURL)
+	r.RoundTripper = HandlerRoundTripper(handler)
+	return r
+}
+
+// HandlerRoundTripper gets an http.RoundTripper that serves requests
+// with the handler in-process, like httptest.NewRecorder.
+// Panics in the handler are returned as errors.
+func HandlerRoundTripper(handler http.Handler) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (res *http.Response, err error) {
+		// make the request look like one received by a server
+		serverReq := req.Clone(req.Context())
+		serverReq.RequestURI = req.URL.RequestURI()
+		serverReq.RemoteAddr = "127.0.0.1:0"
+		serverReq.Proto, serverReq.ProtoMajor, serverReq.ProtoMinor = "HTTP/1.1", 1, 1
+		if serverReq.Host == "" {
+			serverReq.Host = req.URL.Host
+		}
+		if serverReq.Body == nil {
+			serverReq.Body = http.NoBody
+		}
+		rec := httptest.NewRecorder()
+		defer func() {
+			if v := recover(); v != nil {
+				err = fmt.Errorf("handler panic: %v", v)
+			}
+		}()
+		handler.ServeHTTP(rec, serverReq)
+		res = rec.Result()
+		res.Request = req
+		return res, nil
+	})
+}
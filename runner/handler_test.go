@@ -0,0 +1,34 @@
+package runner_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestNewForHandler(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	r := runner.NewForHandler(subT, testutil.EchoDataHandler())
+	r.RunFile("../testfiles/success/data.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestNewForHandlerPanic(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	r := runner.NewForHandler(subT, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	}))
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/success/data.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "handler panic: oops"))
+}
@@ -0,0 +1,240 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HARReporter is a Reporter that records the requests that are
+// made, and their responses, so they can be written as a HAR (HTTP
+// Archive) file to inspect in browser developer tools or replay
+// with other tools. There is a page for each group.
+type HARReporter struct {
+	mu      sync.Mutex
+	results []*Result
+}
+
+// NewHARReporter makes a new HARReporter.
+// Use MultiReporter to keep the default text output too.
+func NewHARReporter() *HARReporter {
+	return &HARReporter{}
+}
+
+// Report records the result, if the request was made.
+func (r *HARReporter) Report(result *Result) {
+	if result.URL == "" {
+		return
+	}
+	r.mu.Lock()
+	r.results = append(r.results, result)
+	r.mu.Unlock()
+}
+
+// Write writes the HAR file of the requests recorded so far.
+func (r *HARReporter) Write(w io.Writer) error {
+	r.mu.Lock()
+	results := r.results
+	r.mu.Unlock()
+	var l harLog
+	l.Version = "1.2"
+	l.Creator.Name = "silk"
+	l.Creator.Version = "1"
+	l.Pages = []harPage{}
+	l.Entries = []harEntry{}
+	pageIDs := make(map[string]string)
+	for _, result := range results {
+		key := result.Filename + "#" + result.Group
+		id, ok := pageIDs[key]
+		if !ok {
+			id = "page_" + strconv.Itoa(len(pageIDs)+1)
+			pageIDs[key] = id
+			l.Pages = append(l.Pages, harPage{
+				StartedDateTime: result.Start.Format(time.RFC3339Nano),
+				ID:              id,
+				Title:           result.Group,
+			})
+		}
+		l.Entries = append(l.Entries, harEntryOf(id, result))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{"log": l})
+}
+
+// WriteFile writes the HAR file to the named file.
+func (r *HARReporter) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type harLog struct {
+	Version string `json:"version"`
+	Creator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"creator"`
+	Pages   []harPage  `json:"pages"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harPage struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	ID              string   `json:"id"`
+	Title           string   `json:"title"`
+	PageTimings     struct{} `json:"pageTimings"`
+}
+
+type harEntry struct {
+	Pageref         string      `json:"pageref"`
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Comment says where the request is in the silk files, and
+	// whether it passed.
+	Comment string `json:"comment"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harHeader  `json:"cookies"`
+	Headers     []harHeader  `json:"headers"`
+	QueryString []harHeader  `json:"queryString"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []harHeader `json:"cookies"`
+	Headers     []harHeader `json:"headers"`
+	Content     harContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harEntryOf makes the HAR entry for the result.
+func harEntryOf(pageref string, result *Result) harEntry {
+	ms := float64(result.Duration) / float64(time.Millisecond)
+	proto := result.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	e := harEntry{
+		Pageref:         pageref,
+		StartedDateTime: result.Start.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      result.Method,
+			URL:         result.URL,
+			HTTPVersion: proto,
+			Cookies:     []harHeader{},
+			Headers:     harHeaders(result.RequestHeader),
+			QueryString: []harHeader{},
+			HeadersSize: -1,
+			BodySize:    len(result.RequestBody),
+		},
+		Response: harResponse{
+			Status:      result.StatusCode,
+			StatusText:  http.StatusText(result.StatusCode),
+			HTTPVersion: proto,
+			Cookies:     []harHeader{},
+			Headers:     harHeaders(result.Header),
+			HeadersSize: -1,
+			BodySize:    len(result.ResponseBody),
+		},
+		Timings: harTimings{Send: 0, Wait: ms, Receive: 0},
+		Comment: result.Filename + ":" + strconv.Itoa(result.Line) + " " + string(result.Outcome),
+	}
+	if u, err := url.Parse(result.URL); err == nil {
+		for _, q := range harSortedKeys(u.Query()) {
+			for _, v := range u.Query()[q] {
+				e.Request.QueryString = append(e.Request.QueryString, harHeader{Name: q, Value: v})
+			}
+		}
+	}
+	if len(result.RequestBody) > 0 {
+		e.Request.PostData = &harPostData{
+			MimeType: result.RequestHeader.Get("Content-Type"),
+			Text:     result.RequestBody,
+		}
+	}
+	e.Response.Content = harContent{
+		Size:     len(result.ResponseBody),
+		MimeType: result.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(result.ResponseBody) {
+		e.Response.Content.Text = string(result.ResponseBody)
+	} else {
+		e.Response.Content.Text = base64.StdEncoding.EncodeToString(result.ResponseBody)
+		e.Response.Content.Encoding = "base64"
+	}
+	return e
+}
+
+// harHeaders gets the header as HAR headers, sorted by name.
+func harHeaders(h http.Header) []harHeader {
+	list := []harHeader{}
+	for _, k := range harSortedKeys(h) {
+		for _, v := range h[k] {
+			list = append(list, harHeader{Name: k, Value: v})
+		}
+	}
+	return list
+}
+
+func harSortedKeys(m map[string][]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
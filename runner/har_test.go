@@ -0,0 +1,67 @@
+package runner_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/convert/har"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestHARReporter(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	reporter := runner.NewHARReporter()
+	r := runner.New(t, s.URL)
+	r.Log = func(string) {}
+	r.Reporter = reporter
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	var buf bytes.Buffer
+	is.NoErr(reporter.Write(&buf))
+
+	// the HAR file converts back into silk
+	groups, err := har.Convert(&buf)
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	is.Equal(groups[0].Title, "Echo server")
+	is.True(len(groups[0].Requests) > 0)
+	is.Equal(groups[0].Requests[0].ExpectedStatus, 200)
+}
+
+func TestHARReporterHTTPVersion(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewUnstartedServer(testutil.EchoHandler())
+	s.EnableHTTP2 = true
+	s.StartTLS()
+	defer s.Close()
+	reporter := runner.NewHARReporter()
+	r := runner.New(t, s.URL)
+	r.Log = func(string) {}
+	r.RoundTripper = s.Client().Transport
+	r.Reporter = reporter
+	r.RunFile("../testfiles/host/vhost.silk.md")
+	var buf bytes.Buffer
+	is.NoErr(reporter.Write(&buf))
+
+	var archive struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					HTTPVersion string `json:"httpVersion"`
+				} `json:"request"`
+				Response struct {
+					HTTPVersion string `json:"httpVersion"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &archive))
+	is.Equal(len(archive.Log.Entries), 1)
+	is.Equal(archive.Log.Entries[0].Request.HTTPVersion, "HTTP/2.0")
+	is.Equal(archive.Log.Entries[0].Response.HTTPVersion, "HTTP/2.0")
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// headerIndexRegex matches assertions about one of the values of
+// a repeated header, like Set-Cookie[1].
+var headerIndexRegex = regexp.MustCompile(`^(.+)\[([0-9]+)\]$`)
+
+// headerIndex gets the name of the header and the index of the
+// value in keys like Set-Cookie[1], and whether the key is one.
+func headerIndex(key string) (name string, index int, ok bool) {
+	match := headerIndexRegex.FindStringSubmatch(key)
+	if match == nil {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], index, true
+}
+
+// ignoreCasePrefix prefixes header values that match regardless
+// of case, like (ignorecase) "application/json; charset=UTF-8".
+const ignoreCasePrefix = "(ignorecase)"
+
+// ignoreCase gets the value of an assertion that matches regardless
+// of case, and whether it is one.
+func ignoreCase(v *parse.Value) (string, bool) {
+	s, ok := v.Data.(string)
+	if !ok || !strings.HasPrefix(s, ignoreCasePrefix) {
+		return "", false
+	}
+	value := parse.ParseValue([]byte(strings.TrimSpace(strings.TrimPrefix(s, ignoreCasePrefix))))
+	return fmt.Sprintf("%v", value.Data), true
+}
+
+// responseDetail gets the actual value for an assertion about the
+// response, and whether it is present.
+func responseDetail(res *http.Response, key string, expected *parse.Value) (interface{}, bool) {
+	if key == "Status" {
+		return float64(res.StatusCode), true
+	}
+	if strings.HasPrefix(key, cookiePrefix) {
+		return responseCookie(res, key)
+	}
+	return responseHeader(res.Header, key, expected)
+}
+
+// responseHeader gets the actual value for an assertion about a
+// header, and whether it is present. Header names are canonicalized,
+// so content-type finds Content-Type.
+// Keys like Set-Cookie[1] get one of the values of a repeated
+// header (counting from zero), and list values (like
+// ["Accept", "Origin"]) are compared with all of the values.
+// Otherwise, the last value is used.
+func responseHeader(header http.Header, key string, expected *parse.Value) (interface{}, bool) {
+	if name, i, ok := headerIndex(key); ok {
+		values := header[http.CanonicalHeaderKey(name)]
+		if i >= len(values) {
+			return nil, false
+		}
+		return values[i], true
+	}
+	values, ok := header[http.CanonicalHeaderKey(key)]
+	if !ok || len(values) == 0 {
+		return nil, false
+	}
+	if isList(expected) {
+		list := make([]interface{}, len(values))
+		for i, value := range values {
+			list[i] = value
+		}
+		return list, true
+	}
+	return values[len(values)-1], true
+}
+
+// isList gets whether the value is a list.
+func isList(v *parse.Value) bool {
+	if v == nil {
+		return false
+	}
+	_, ok := v.Data.([]interface{})
+	return ok
+}
@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// HTMLReporter is a Reporter that collects results, to write a
+// self-contained HTML report of them.
+type HTMLReporter struct {
+	mu      sync.Mutex
+	results []*Result
+}
+
+// NewHTMLReporter makes a new HTMLReporter.
+// Use MultiReporter to keep the default text output too.
+func NewHTMLReporter() *HTMLReporter {
+	return &HTMLReporter{}
+}
+
+// Report collects the result.
+func (h *HTMLReporter) Report(result *Result) {
+	h.mu.Lock()
+	h.results = append(h.results, result)
+	h.mu.Unlock()
+}
+
+// Write writes the report of the results collected so far.
+func (h *HTMLReporter) Write(w io.Writer) error {
+	h.mu.Lock()
+	results := h.results
+	h.mu.Unlock()
+	var d time.Duration
+	for _, result := range results {
+		d += result.Duration
+	}
+	report := struct {
+		Summary *Summary
+		Groups  []*htmlGroup
+	}{
+		Summary: summarize(nil, results, d),
+	}
+	groups := make(map[string]*htmlGroup)
+	for _, result := range results {
+		key := result.Filename + "\x00" + result.Group
+		group := groups[key]
+		if group == nil {
+			group = &htmlGroup{Filename: result.Filename, Title: result.Group}
+			groups[key] = group
+			report.Groups = append(report.Groups, group)
+		}
+		if result.Outcome == Failed {
+			group.Failed = true
+		}
+		group.Results = append(group.Results, result)
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+// WriteFile writes the report to a file.
+func (h *HTMLReporter) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := h.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type htmlGroup struct {
+	Filename string
+	Title    string
+	Failed   bool
+	Results  []*Result
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"string": func(b []byte) string { return string(b) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Silk report</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+details { margin: 0.3em 0; }
+summary { cursor: pointer; padding: 0.2em; }
+pre { background: #f6f6f6; padding: 0.8em; overflow: auto; }
+.group { border-left: 4px solid #2a2; padding-left: 0.8em; }
+.group.FAIL { border-color: #c22; }
+.PASS { color: #2a2; }
+.FAIL { color: #c22; }
+.SKIP, .QUARANTINE { color: #b80; }
+.request { margin-left: 1em; }
+.meta { color: #888; font-size: 0.9em; }
+table { border-collapse: collapse; }
+td { padding: 0.1em 0.8em 0.1em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Silk report</h1>
+<p>
+	<span class="{{if .Summary.Failed}}FAIL{{else}}PASS{{end}}">{{if .Summary.Failed}}FAIL{{else}}PASS{{end}}</span>
+	{{.Summary.Files}} file(s), {{.Summary.Requests}} request(s):
+	{{.Summary.Passed}} passed, {{.Summary.Failed}} failed, {{.Summary.Skipped}} skipped{{if .Summary.Quarantined}}, {{.Summary.Quarantined}} quarantined{{end}}
+	<span class="meta">({{.Summary.Duration}})</span>
+</p>
+{{range .Groups}}
+<details class="group{{if .Failed}} FAIL{{end}}"{{if .Failed}} open{{end}}>
+<summary><strong>{{.Title}}</strong> <span class="meta">{{.Filename}}</span></summary>
+{{range .Results}}
+<details class="request"{{if eq .Outcome "FAIL"}} open{{end}}>
+<summary><span class="{{.Outcome}}">{{.Outcome}}</span> {{.Method}} {{.Path}}{{if .Name}} ({{.Name}}){{end}} <span class="meta">{{.Duration}}{{if .RequestID}}, request ID {{.RequestID}}{{end}}{{if .TraceID}}, trace ID {{.TraceID}}{{end}}</span></summary>
+{{if .Message}}<p class="{{.Outcome}}">{{.Filename}}:{{.Line}} - {{.Message}}</p>{{end}}
+{{if .Details}}<pre>{{range .Details}}{{.}}
+{{end}}</pre>{{end}}
+{{if .Assertions}}<table>
+{{range .Assertions}}<tr><td class="{{if .Passed}}PASS{{else}}FAIL{{end}}">{{if .Passed}}&#10003;{{else}}&#10007;{{end}}</td><td>{{.Text}}</td><td class="meta">line {{.Line}}</td></tr>
+{{end}}</table>{{end}}
+{{if .URL}}<details><summary>Request</summary>
+<pre>{{.Method}} {{.URL}}{{if .RequestBody}}
+
+{{.RequestBody}}{{end}}</pre>
+</details>{{end}}
+{{if .StatusCode}}<details><summary>Response</summary>
+<pre>{{.StatusCode}}
+{{range $key, $values := .Header}}{{range $values}}{{$key}}: {{.}}
+{{end}}{{end}}
+{{string .ResponseBody}}</pre>
+</details>{{end}}
+</details>
+{{end}}
+</details>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,47 @@
+package runner_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_SKIP", "true")
+	defer os.Unsetenv("SILK_SKIP")
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	html := runner.NewHTMLReporter()
+	r.Reporter = runner.MultiReporter(r.Reporter, html)
+	r.RunFile("../testfiles/success/skip.silk.md", "../testfiles/failure/echo.failure.wrongbody.silk.md")
+	is.True(subT.Failed())
+	// the text reporter still logs
+	is.True(strings.Contains(strings.Join(logs, "\n"), "FAIL: 2 file(s)"))
+
+	var buf bytes.Buffer
+	is.NoErr(html.Write(&buf))
+	report := buf.String()
+	is.True(strings.Contains(report, "2 file(s), 2 request(s):\n\t1 passed, 1 failed, 1 skipped"))
+	is.True(strings.Contains(report, `<details class="group FAIL" open>`))
+	is.True(strings.Contains(report, `<summary><strong>Echo server</strong> <span class="meta">../testfiles/failure/echo.failure.wrongbody.silk.md</span></summary>`))
+	is.True(strings.Contains(report, `<span class="SKIP">SKIP</span> GET /skipped`))
+	is.True(strings.Contains(report, `echo.failure.wrongbody.silk.md:14 - body doesn&#39;t match`))
+	is.True(strings.Contains(report, "\n-Hello silky.\n"))
+	is.True(strings.Contains(report, "\n&#43;Hello silk.\n"))
+	is.True(strings.Contains(report, `<td>* Status: 200</td>`))
+	is.True(strings.Contains(report, `<td>body</td>`))
+	is.True(strings.Contains(report, "Server: EchoHandler\n"))
+}
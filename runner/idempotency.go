@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/matryer/silk/parse"
+)
+
+// idempotencyHeader is the header that the IdempotencyKey directive
+// sets.
+const idempotencyHeader = "Idempotency-Key"
+
+// newIdempotencyKey is the value of the IdempotencyKey directive
+// that makes a fresh key for the request in each run.
+const newIdempotencyKey = "new"
+
+// idempotentKey is the assertion that the response has the same
+// status and body as the first response to a request with the same
+// Idempotency-Key in the run, like "* Idempotent: true".
+const idempotentKey = "Idempotent"
+
+// idempotency is the state of the Idempotency-Keys of a run.
+type idempotency struct {
+	// keys are the fresh keys of the requests.
+	keys map[*parse.Request]string
+	// responses are the first responses to each key.
+	responses map[string]*idempotentResponse
+}
+
+// idempotentResponse is what is compared by Idempotent assertions.
+type idempotentResponse struct {
+	status int
+	body   []byte
+}
+
+// idempotencyKey gets the Idempotency-Key of the call from its
+// IdempotencyKey directive, or empty if it doesn't have one. Fresh
+// keys are kept for the run, so the repeats of the request, and the
+// rows of its data file, use the same key.
+func (r *Runner) idempotencyKey(c *call, key string) string {
+	if key != newIdempotencyKey {
+		return key
+	}
+	state := r.idempotencyState()
+	if key, ok := state.keys[c.req]; ok {
+		return key
+	}
+	key = r.random().uuid()
+	state.keys[c.req] = key
+	return key
+}
+
+// firstResponse gets the first response to the Idempotency-Key in
+// the run, keeping the response if it is the first.
+func (r *Runner) firstResponse(key string, res *http.Response, body []byte) *idempotentResponse {
+	state := r.idempotencyState()
+	if first, ok := state.responses[key]; ok {
+		return first
+	}
+	first := &idempotentResponse{status: res.StatusCode, body: body}
+	state.responses[key] = first
+	return first
+}
+
+// idempotencyState gets the state of the Idempotency-Keys of the
+// current run, making it if there isn't one yet.
+func (r *Runner) idempotencyState() *idempotency {
+	if r.idempotency == nil {
+		r.idempotency = &idempotency{
+			keys:      make(map[*parse.Request]string),
+			responses: make(map[string]*idempotentResponse),
+		}
+	}
+	return r.idempotency
+}
+
+// assertIdempotent asserts whether the response is the same as the
+// first response to the Idempotency-Key.
+func (r *Runner) assertIdempotent(c *call, first *idempotentResponse, res *http.Response, body []byte, expected *parse.Value) bool {
+	if err := checkBool(expected); err != nil {
+		c.log(idempotentKey, err)
+		return false
+	}
+	if first == nil {
+		c.log(idempotentKey, "the request has no IdempotencyKey")
+		return false
+	}
+	same := first.status == res.StatusCode && bytes.Equal(first.body, body)
+	if same == expected.Data.(bool) {
+		return true
+	}
+	if same {
+		c.log(idempotentKey, "expected a different response  actual: the same response as the first")
+		return false
+	}
+	if first.status != res.StatusCode {
+		c.log(idempotentKey, fmt.Sprintf("expected status %d (as the first response)  actual: %d", first.status, res.StatusCode))
+		return false
+	}
+	c.log(idempotentKey, fmt.Sprintf("expected body (as the first response): %s  actual: %s", first.body, body))
+	return false
+}
@@ -0,0 +1,17 @@
+package runner
+
+import (
+	"regexp"
+
+	"github.com/matryer/silk/parse"
+)
+
+// selected gets whether the request is selected by the Run regexp,
+// which is whether it matches the title of the group, or the method
+// and path of the request, like "GET /users/{id}".
+func selected(run *regexp.Regexp, group *parse.Group, req *parse.Request) bool {
+	if run == nil {
+		return true
+	}
+	return run.MatchString(string(group.Title)) || run.MatchString(string(req.Method)+" "+string(req.Path))
+}
@@ -0,0 +1,140 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// matcher matches actual values with an expected value that is
+// more than a value to compare with, like 10.5 ± 0.01.
+type matcher interface {
+	// match gets whether the actual value matches.
+	match(actual interface{}) bool
+	// example gets a value that matches, for mock responses.
+	example() interface{}
+	String() string
+}
+
+// matcherParsers parse expected values into matchers. They return
+// a nil matcher if the value isn't for them.
+var matcherParsers = []func(s string) (matcher, error){
+	parseApprox,
+	parseTime,
+	parseDuration,
+	parseRegistered,
+}
+
+// parseMatcher gets the matcher for the expected value, or nil if
+// it is a plain value.
+func parseMatcher(v *parse.Value) (matcher, error) {
+	s, ok := v.Data.(string)
+	if !ok {
+		return nil, nil
+	}
+	for _, parse := range matcherParsers {
+		m, err := parse(s)
+		if err != nil || m != nil {
+			return m, err
+		}
+	}
+	return nil, nil
+}
+
+// assertMatcher asserts that the actual value matches.
+func (r *Runner) assertMatcher(c *call, key string, actual interface{}, m matcher) bool {
+	if !m.match(actual) {
+		c.log(key, fmt.Sprintf("expected %s  actual %T: %s", m, actual, parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))))
+		return false
+	}
+	return true
+}
+
+// approxRegex matches numbers with a tolerance, like 10.5 ± 0.01
+// or 10.5 ~= 0.01. Other values with ± or ~= in them are plain
+// strings.
+var approxRegex = regexp.MustCompile(`^\s*(` + numberPattern + `)\s*(?:±|~=)\s*(` + numberPattern + `)\s*$`)
+
+// numberPattern matches a (JSON style) number.
+const numberPattern = `-?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`
+
+// approx matches numbers within the tolerance of a value.
+type approx struct {
+	value, tolerance float64
+}
+
+func parseApprox(s string) (matcher, error) {
+	match := approxRegex.FindStringSubmatch(s)
+	if match == nil {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad number %q", match[1])
+	}
+	tolerance, err := strconv.ParseFloat(match[2], 64)
+	if err != nil || tolerance < 0 {
+		return nil, fmt.Errorf("bad tolerance %q", match[2])
+	}
+	return &approx{value: value, tolerance: tolerance}, nil
+}
+
+func (a *approx) match(actual interface{}) bool {
+	n, ok := number(actual)
+	if !ok {
+		return false
+	}
+	return n >= a.value-a.tolerance && n <= a.value+a.tolerance
+}
+
+func (a *approx) example() interface{} {
+	return a.value
+}
+
+func (a *approx) String() string {
+	return strconv.FormatFloat(a.value, 'f', -1, 64) + " ± " + strconv.FormatFloat(a.tolerance, 'f', -1, 64)
+}
+
+// number gets the actual value as a number, parsing strings (like
+// header values).
+func number(actual interface{}) (float64, bool) {
+	switch v := actual.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// registered matches values with a parse.Matcher, like uuid().
+type registered struct {
+	name string
+	m    parse.Matcher
+}
+
+func parseRegistered(s string) (matcher, error) {
+	m, ok := parse.LookupMatcher(s)
+	if !ok {
+		return nil, nil
+	}
+	return &registered{name: s, m: m}, nil
+}
+
+func (r *registered) match(actual interface{}) bool {
+	return r.m.Match(actual)
+}
+
+func (r *registered) example() interface{} {
+	return r.m.Example()
+}
+
+func (r *registered) String() string {
+	return r.name
+}
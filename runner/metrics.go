@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricsBuckets are the upper bounds (in seconds) of the buckets
+// of the request duration histograms, the Prometheus defaults.
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsReporter is a Reporter that counts the requests that are
+// run, by endpoint (the method and path as they are written in the
+// file) and outcome, and how long they take, along with the
+// outcome of each run, as Prometheus metrics. Write them to a file
+// for the node_exporter textfile collector with WriteFile, or Push
+// them to a Pushgateway, so scheduled runs can feed dashboards and
+// alerts.
+type MetricsReporter struct {
+	mu        sync.Mutex
+	requests  map[metricsKey]int
+	durations map[endpoint]*histogram
+	runs      map[Outcome]int
+	lastRun   *Summary
+	lastTime  time.Time
+}
+
+type endpoint struct {
+	method, path string
+}
+
+type metricsKey struct {
+	endpoint
+	outcome Outcome
+}
+
+// histogram counts durations in the metricsBuckets.
+type histogram struct {
+	buckets []int
+	sum     float64
+	count   int
+}
+
+// NewMetricsReporter makes a new MetricsReporter.
+// Use MultiReporter to keep the default text output too.
+func NewMetricsReporter() *MetricsReporter {
+	return &MetricsReporter{
+		requests:  make(map[metricsKey]int),
+		durations: make(map[endpoint]*histogram),
+		runs:      make(map[Outcome]int),
+	}
+}
+
+// Report counts the result, and the time it took if the request
+// was made.
+func (m *MetricsReporter) Report(result *Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := endpoint{method: result.Method, path: result.Path}
+	m.requests[metricsKey{endpoint: e, outcome: result.Outcome}]++
+	if result.URL == "" {
+		return
+	}
+	h, ok := m.durations[e]
+	if !ok {
+		h = &histogram{buckets: make([]int, len(metricsBuckets))}
+		m.durations[e] = h
+	}
+	seconds := result.Duration.Seconds()
+	for i, le := range metricsBuckets {
+		if seconds <= le {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Summarize counts the run.
+func (m *MetricsReporter) Summarize(summary *Summary) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	outcome := Passed
+	if summary.Failed > 0 {
+		outcome = Failed
+	}
+	m.runs[outcome]++
+	m.lastRun, m.lastTime = summary, time.Now()
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (m *MetricsReporter) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var buf bytes.Buffer
+	buf.WriteString("# HELP silk_requests_total Requests run by silk, by endpoint and outcome.\n")
+	buf.WriteString("# TYPE silk_requests_total counter\n")
+	keys := make([]metricsKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint.less(keys[j].endpoint)
+		}
+		return keys[i].outcome < keys[j].outcome
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "silk_requests_total{%s,outcome=%s} %d\n", k.labels(), metricsLabel(strings.ToLower(string(k.outcome))), m.requests[k])
+	}
+	buf.WriteString("# HELP silk_request_duration_seconds How long requests took, by endpoint.\n")
+	buf.WriteString("# TYPE silk_request_duration_seconds histogram\n")
+	endpoints := make([]endpoint, 0, len(m.durations))
+	for e := range m.durations {
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].less(endpoints[j])
+	})
+	for _, e := range endpoints {
+		h := m.durations[e]
+		for i, le := range metricsBuckets {
+			fmt.Fprintf(&buf, "silk_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", e.labels(), strconv.FormatFloat(le, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(&buf, "silk_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", e.labels(), h.count)
+		fmt.Fprintf(&buf, "silk_request_duration_seconds_sum{%s} %s\n", e.labels(), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&buf, "silk_request_duration_seconds_count{%s} %d\n", e.labels(), h.count)
+	}
+	buf.WriteString("# HELP silk_runs_total Runs of silk files, by outcome.\n")
+	buf.WriteString("# TYPE silk_runs_total counter\n")
+	for _, outcome := range []Outcome{Passed, Failed} {
+		fmt.Fprintf(&buf, "silk_runs_total{outcome=%s} %d\n", metricsLabel(strings.ToLower(string(outcome))), m.runs[outcome])
+	}
+	if m.lastRun != nil {
+		success := 1
+		if m.lastRun.Failed > 0 {
+			success = 0
+		}
+		buf.WriteString("# HELP silk_last_run_success Whether the last run passed (1) or failed (0).\n")
+		buf.WriteString("# TYPE silk_last_run_success gauge\n")
+		fmt.Fprintf(&buf, "silk_last_run_success %d\n", success)
+		buf.WriteString("# HELP silk_last_run_duration_seconds How long the last run took.\n")
+		buf.WriteString("# TYPE silk_last_run_duration_seconds gauge\n")
+		fmt.Fprintf(&buf, "silk_last_run_duration_seconds %s\n", strconv.FormatFloat(m.lastRun.Duration.Seconds(), 'g', -1, 64))
+		buf.WriteString("# HELP silk_last_run_timestamp_seconds When the last run finished.\n")
+		buf.WriteString("# TYPE silk_last_run_timestamp_seconds gauge\n")
+		fmt.Fprintf(&buf, "silk_last_run_timestamp_seconds %d\n", m.lastTime.Unix())
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteFile writes the metrics to the named file, replacing it all
+// at once, so the textfile collector never reads half of it. Its
+// name should end in .prom.
+func (m *MetricsReporter) WriteFile(filename string) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), ".silk-metrics-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := m.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// temporary files are only readable by their owner
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// Push pushes the metrics to the Prometheus Pushgateway at the URL,
+// like http://pushgateway:9091, replacing those of the job.
+func (m *MetricsReporter) Push(gateway, job string) error {
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(gateway, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequest(http.MethodPut, u, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("pushgateway: %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (e endpoint) less(other endpoint) bool {
+	if e.path != other.path {
+		return e.path < other.path
+	}
+	return e.method < other.method
+}
+
+func (e endpoint) labels() string {
+	return "method=" + metricsLabel(e.method) + ",path=" + metricsLabel(e.path)
+}
+
+// metricsLabel quotes a label value, escaping it as the Prometheus
+// text format does.
+func metricsLabel(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
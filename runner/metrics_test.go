@@ -0,0 +1,65 @@
+package runner_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestMetricsReporter(t *testing.T) {
+	is := is.New(t)
+	reporter := runner.NewMetricsReporter()
+	reporter.Report(&runner.Result{Method: "GET", Path: "/users/{id}", Outcome: runner.Passed, URL: "http://localhost/users/1", Duration: 20 * time.Millisecond})
+	reporter.Report(&runner.Result{Method: "GET", Path: "/users/{id}", Outcome: runner.Failed, URL: "http://localhost/users/2", Duration: 2 * time.Second})
+	reporter.Report(&runner.Result{Method: "DELETE", Path: "/users/{id}", Outcome: runner.Skipped})
+	reporter.Summarize(&runner.Summary{Passed: 1, Failed: 1, Skipped: 1, Duration: 3 * time.Second})
+	var buf bytes.Buffer
+	is.NoErr(reporter.Write(&buf))
+	metrics := buf.String()
+	for _, line := range []string{
+		`silk_requests_total{method="DELETE",path="/users/{id}",outcome="skip"} 1`,
+		`silk_requests_total{method="GET",path="/users/{id}",outcome="fail"} 1`,
+		`silk_requests_total{method="GET",path="/users/{id}",outcome="pass"} 1`,
+		`silk_request_duration_seconds_bucket{method="GET",path="/users/{id}",le="0.025"} 1`,
+		`silk_request_duration_seconds_bucket{method="GET",path="/users/{id}",le="2.5"} 2`,
+		`silk_request_duration_seconds_bucket{method="GET",path="/users/{id}",le="+Inf"} 2`,
+		`silk_request_duration_seconds_sum{method="GET",path="/users/{id}"} 2.02`,
+		`silk_request_duration_seconds_count{method="GET",path="/users/{id}"} 2`,
+		`silk_runs_total{outcome="fail"} 1`,
+		`silk_last_run_success 0`,
+		`silk_last_run_duration_seconds 3`,
+	} {
+		is.True(strings.Contains(metrics, line+"\n"))
+	}
+	// skipped requests take no time
+	is.False(strings.Contains(metrics, `silk_request_duration_seconds_count{method="DELETE"`))
+
+	dir, err := ioutil.TempDir("", "silk-metrics")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "silk.prom")
+	is.NoErr(reporter.WriteFile(filename))
+	b, err := ioutil.ReadFile(filename)
+	is.NoErr(err)
+	is.Equal(string(b), metrics)
+
+	var pushed, path, contentType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		pushed, path, contentType = string(b), r.Method+" "+r.URL.Path, r.Header.Get("Content-Type")
+	}))
+	defer s.Close()
+	is.NoErr(reporter.Push(s.URL, "api checks"))
+	is.Equal(pushed, metrics)
+	is.Equal(path, "PUT /metrics/job/api checks")
+	is.Equal(contentType, "text/plain; version=0.0.4")
+}
@@ -0,0 +1,30 @@
+package runner
+
+import "net/http"
+
+// Middleware wraps a RoundTripper, for example to retry requests,
+// record metrics or add authentication.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is a function that implements http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls fn(req).
+func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// Use adds middleware to the Runner.
+// Middleware is called in the order it is added, with the last one
+// calling the RoundTripper.
+func (r *Runner) Use(middleware ...Middleware) {
+	r.Middleware = append(r.Middleware, middleware...)
+}
+
+// transport wraps the transport in the Middleware.
+func (r *Runner) transport(transport http.RoundTripper) http.RoundTripper {
+	for i := len(r.Middleware) - 1; i >= 0; i-- {
+		transport = r.Middleware[i](transport)
+	}
+	return transport
+}
@@ -0,0 +1,38 @@
+package runner_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var calls []string
+	middleware := func(name string) runner.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return runner.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				res, err := next.RoundTrip(req)
+				calls = append(calls, name+" after")
+				return res, err
+			})
+		}
+	}
+	r.Use(middleware("one"), middleware("two"))
+	r.Use(middleware("three"))
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	is.False(subT.Failed())
+	is.Equal(calls, []string{
+		"one before", "two before", "three before",
+		"three after", "two after", "one after",
+	})
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// multipartBoundary gets the boundary of a multipart Content-Type,
+// like multipart/mixed, and whether it is one.
+func multipartBoundary(contentType string) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return "", false
+	}
+	return params["boundary"], params["boundary"] != ""
+}
+
+// part is a part of a multipart body.
+type part struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// readParts reads the parts of a multipart body.
+func readParts(body []byte, boundary string) ([]part, error) {
+	var parts []part
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part{header: p.Header, body: b})
+	}
+}
+
+// responseData gets the data of the response body for assertions.
+// The data of multipart bodies is a list of their parts, with their
+// Headers, Body, and Data if their body can be parsed, so a part
+// is asserted like Data[1].Data.id. Other bodies are parsed by
+// ParseBody.
+func (r *Runner) responseData(header http.Header, body []byte) (interface{}, error) {
+	boundary, ok := multipartBoundary(header.Get("Content-Type"))
+	if !ok {
+		return r.ParseBody(bytes.NewReader(body))
+	}
+	parts, err := readParts(body, boundary)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]interface{}, len(parts))
+	for i, p := range parts {
+		headers := make(map[string]interface{}, len(p.header))
+		for k := range p.header {
+			headers[k] = p.header.Get(k)
+		}
+		d := map[string]interface{}{"Headers": headers, "Body": string(p.body)}
+		if partData, err := r.ParseBody(bytes.NewReader(p.body)); err == nil {
+			d["Data"] = partData
+		}
+		data[i] = d
+	}
+	return data, nil
+}
+
+// assertParts asserts that the parts of a multipart body match the
+// parts of the expected body, which has its own boundary (taken
+// from its first line), since the response's is often random. The
+// headers of each expected part must be in the actual part, and
+// their bodies are compared like whole bodies.
+func (r *Runner) assertParts(c *call, actual, expected []byte, boundary string) bool {
+	actualParts, err := readParts(actual, boundary)
+	if err != nil {
+		c.log("body: failed to read parts:", err)
+		return false
+	}
+	first := strings.TrimSpace(strings.SplitN(strings.TrimLeft(string(expected), "\r\n"), "\n", 2)[0])
+	if !strings.HasPrefix(first, "--") {
+		c.log("body: expected body isn't multipart (it should start with --boundary)")
+		return false
+	}
+	expectedParts, err := readParts(expected, strings.TrimPrefix(first, "--"))
+	if err != nil {
+		c.log("body: failed to read expected parts:", err)
+		return false
+	}
+	if len(actualParts) != len(expectedParts) {
+		c.log(fmt.Sprintf("body: expected %d part(s)  actual %d", len(expectedParts), len(actualParts)))
+		return false
+	}
+	for i, expected := range expectedParts {
+		actual := actualParts[i]
+		for k := range expected.header {
+			if a, e := actual.header.Get(k), expected.header.Get(k); a != e {
+				c.log(fmt.Sprintf("part %d: %s expected %q  actual %q", i+1, k, e, a))
+				return false
+			}
+		}
+		if bytes.Equal(actual.body, expected.body) {
+			continue
+		}
+		if !r.ExactBody && bodiesMatch(contentKind(actual.header.Get("Content-Type"), actual.body), actual.body, expected.body) {
+			continue
+		}
+		c.log(fmt.Sprintf("part %d: body differs (-expected +actual):", i+1))
+		c.details = append(c.details, diffLines(strings.Split(string(expected.body), "\n"), strings.Split(string(actual.body), "\n"))...)
+		return false
+	}
+	return true
+}
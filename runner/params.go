@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/matryer/silk/parse"
+)
+
+// ParamValues gets the values of a parameter. Lists, like
+// * ?id=[1,2], are a value for each item, so they are sent as
+// ?id=1&id=2, the same as repeating the parameter.
+func ParamValues(detail *parse.Detail) []string {
+	list, ok := detail.Value.Data.([]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%v", detail.Value.Data)}
+	}
+	values := make([]string, len(list))
+	for i, item := range list {
+		values[i] = fmt.Sprintf("%v", item)
+	}
+	return values
+}
@@ -0,0 +1,40 @@
+package runner
+
+import "fmt"
+
+// Policy is what the Runner does when a request fails.
+type Policy int
+
+const (
+	// FailFast stops at the first failing request. It is the
+	// default.
+	FailFast Policy = iota
+	// FinishGroup finishes the group of the first failing request,
+	// and then stops.
+	FinishGroup
+	// RunAll runs every request, and fails at the end if any
+	// failed.
+	RunAll
+)
+
+// ParsePolicy parses the name of a Policy: fail-fast, finish-group
+// or run-all.
+func ParsePolicy(s string) (Policy, error) {
+	switch s {
+	case "", "fail-fast":
+		return FailFast, nil
+	case "finish-group":
+		return FinishGroup, nil
+	case "run-all":
+		return RunAll, nil
+	}
+	return FailFast, fmt.Errorf("unknown policy %q (expected fail-fast, finish-group or run-all)", s)
+}
+
+// policy gets the Policy of the Runner.
+func (r *Runner) policy() Policy {
+	if r.ContinueOnFailure {
+		return RunAll
+	}
+	return r.OnFailure
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// defaultPollInterval is how long to wait between attempts when
+// the PollUntil directive doesn't say.
+const defaultPollInterval = time.Second
+
+var errPollUntil = errors.New(`expected a timeout and an optional interval, like "10s, 500ms"`)
+
+// parsePollUntil gets the timeout and interval of a PollUntil
+// directive.
+func parsePollUntil(v *parse.Value) (timeout, interval time.Duration, err error) {
+	parts := strings.Split(fmt.Sprintf("%v", v.Data), ",")
+	if len(parts) > 2 {
+		return 0, 0, errPollUntil
+	}
+	timeout, err = time.ParseDuration(strings.TrimSpace(parts[0]))
+	if err != nil || timeout <= 0 {
+		return 0, 0, errPollUntil
+	}
+	interval = defaultPollInterval
+	if len(parts) == 2 {
+		interval, err = time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || interval <= 0 {
+			return 0, 0, errPollUntil
+		}
+	}
+	return timeout, interval, nil
+}
+
+func checkPollUntil(v *parse.Value) error {
+	_, _, err := parsePollUntil(v)
+	return err
+}
+
+// poll performs the call until it passes, or the timeout of the
+// PollUntil directive passes, waiting for the interval between
+// attempts. Only the last attempt reports a failure.
+// Gets whether it passed.
+func (r *Runner) poll(c *call) bool {
+	helperOf(c.t).Helper()
+	line := directive(c.req.Details, directivePollUntil)
+	if line == nil {
+		return r.call(c)
+	}
+	timeout, interval, err := parsePollUntil(line.Detail().Value)
+	if err != nil {
+		r.fail(c, line.Number, directivePollUntil+":", err)
+		return false
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		last := !time.Now().Add(interval).Before(deadline) || r.context().Err() != nil
+		attempt := &call{t: c.t, subtest: c.subtest, group: c.group, req: c.req, vars: c.vars, local: c.local, name: c.name, polling: !last}
+		if r.call(attempt) {
+			return true
+		}
+		if last {
+			return false
+		}
+		r.Verbose(indent, "polling again in", interval)
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-r.context().Done():
+		}
+		timer.Stop()
+	}
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+const randChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// random makes the random values of a run, from its seed, so that
+// the run can be reproduced.
+type random struct {
+	seed int64
+	mu   sync.Mutex
+	rand *rand.Rand
+	// count makes fake emails and usernames unique, even if
+	// their random parts are the same.
+	count uint64
+}
+
+func newRandom(seed int64) *random {
+	return &random{seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+// random gets the random values of the current run, starting them
+// from the Seed (or a new seed) if there aren't any yet.
+func (r *Runner) random() *random {
+	if r.rnd == nil {
+		seed := r.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		r.rnd = newRandom(seed)
+	}
+	return r.rnd
+}
+
+// funcs gets the template functions that make random values.
+//
+//	uuid          - a new random (version 4) UUID
+//	randInt 1 100 - a random int in the range [min, max)
+//	randString 10 - a random alphanumeric string of length n
+//	fake.Email    - fake data, like a unique email (see faker)
+func (rnd *random) funcs() template.FuncMap {
+	return template.FuncMap{
+		"uuid":       rnd.uuid,
+		"randInt":    rnd.randInt,
+		"randString": rnd.randString,
+		"fake":       func() faker { return faker{rnd} },
+	}
+}
+
+func (rnd *random) intn(n int) int {
+	rnd.mu.Lock()
+	defer rnd.mu.Unlock()
+	return rnd.rand.Intn(n)
+}
+
+// next gets the next number of the count.
+func (rnd *random) next() uint64 {
+	rnd.mu.Lock()
+	defer rnd.mu.Unlock()
+	rnd.count++
+	return rnd.count
+}
+
+// shuffle gets the groups in a random order.
+func (rnd *random) shuffle(groups []*parse.Group) []*parse.Group {
+	shuffled := append([]*parse.Group(nil), groups...)
+	rnd.mu.Lock()
+	defer rnd.mu.Unlock()
+	rnd.rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func (rnd *random) uuid() string {
+	var b [16]byte
+	rnd.mu.Lock()
+	rnd.rand.Read(b[:])
+	rnd.mu.Unlock()
+	return formatUUID(b)
+}
+
+func (rnd *random) randInt(min, max int) (int, error) {
+	if max <= min {
+		return 0, fmt.Errorf("randInt: max (%d) must be greater than min (%d)", max, min)
+	}
+	return min + rnd.intn(max-min), nil
+}
+
+func (rnd *random) randString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randChars[rnd.intn(len(randChars))]
+	}
+	return string(b)
+}
@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// waitForRateLimit waits until the request may be made, so that
+// no more than RateLimit requests (or the number given by the
+// Throttle directive of the group or request) are made per second.
+func (r *Runner) waitForRateLimit(c *call) error {
+	rate := r.RateLimit
+	for _, lines := range []parse.Lines{c.group.Details, c.req.Details} {
+		if line := directive(lines, directiveThrottle); line != nil {
+			rate, _ = line.Detail().Value.Data.(float64)
+		}
+	}
+	if rate <= 0 {
+		r.lastRequest = time.Now()
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+	if wait := time.Until(r.lastRequest.Add(interval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.context().Done():
+			return r.context().Err()
+		}
+	}
+	r.lastRequest = time.Now()
+	return nil
+}
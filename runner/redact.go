@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// redactValues remembers the values of the headers and JSON fields
+// named in Redact, so they are redacted from what the call logs
+// and reports.
+func (r *Runner) redactValues(c *call, header http.Header, body []byte) {
+	if len(r.Redact) == 0 {
+		return
+	}
+	names := make(map[string]bool, len(r.Redact))
+	for _, name := range r.Redact {
+		names[name] = true
+		key := http.CanonicalHeaderKey(name)
+		for _, value := range header[key] {
+			c.addRedacted(value)
+		}
+		switch key {
+		case "Cookie":
+			for _, cookie := range (&http.Request{Header: http.Header{key: header[key]}}).Cookies() {
+				c.addRedacted(cookie.Value)
+			}
+		case "Set-Cookie":
+			for _, cookie := range (&http.Response{Header: http.Header{key: header[key]}}).Cookies() {
+				c.addRedacted(cookie.Value)
+			}
+		}
+	}
+	var data interface{}
+	if json.Unmarshal(body, &data) == nil {
+		c.redactFields(names, data, false)
+	}
+}
+
+// redactFields remembers the values of the fields in the data with
+// the names, including everything inside them.
+func (c *call) redactFields(names map[string]bool, data interface{}, all bool) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			c.redactFields(names, v, all || names[k])
+		}
+	case []interface{}:
+		for _, v := range d {
+			c.redactFields(names, v, all)
+		}
+	case nil:
+	default:
+		if all {
+			c.addRedacted(fmt.Sprintf("%v", d))
+		}
+	}
+}
+
+// addRedacted adds a value to redact from the output of the call.
+// Every value is redacted, however short, even though short values
+// may also hide unrelated parts of the output.
+// Longer values are redacted first, so that values inside them
+// don't leave parts of them behind.
+func (c *call) addRedacted(value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	for _, secret := range c.secrets {
+		if secret == value {
+			return
+		}
+	}
+	c.secrets = append(c.secrets, value)
+	sort.SliceStable(c.secrets, func(i, j int) bool {
+		return len(c.secrets[i]) > len(c.secrets[j])
+	})
+}
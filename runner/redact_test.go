@@ -0,0 +1,51 @@
+package runner_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestRedact(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "session-456"})
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": 1, "user": {"password": "hunter22-hash", "pin": "x9z"}}`)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	var verbose []string
+	r.Verbose = func(args ...interface{}) {
+		verbose = append(verbose, fmt.Sprintln(args...))
+	}
+	r.PrintCurl = true
+	r.Redact = []string{"authorization", "Set-Cookie", "password", "pin"}
+	r.RunFile("../testfiles/redact/redact.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	verbosestr := strings.Join(verbose, "")
+	is.True(strings.Contains(verbosestr, "Authorization: "))
+	is.True(strings.Contains(logstr, "Authorization: [REDACTED]"))
+	is.True(strings.Contains(logstr, `Data.user.password expected string: "wrong-password"  actual string: "[REDACTED]"`))
+	result := r.Summary().Results[0]
+	for _, secret := range []string{"abc-token-123", "hunter22", "session-456", "x9z"} {
+		is.False(strings.Contains(logstr, secret))
+		is.False(strings.Contains(verbosestr, secret))
+		is.False(strings.Contains(result.RequestBody, secret))
+		is.False(strings.Contains(string(result.ResponseBody), secret))
+		is.False(strings.Contains(result.RequestHeader.Get("Authorization"), secret))
+		is.False(strings.Contains(result.Header.Get("Set-Cookie"), secret))
+	}
+}
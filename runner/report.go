@@ -0,0 +1,296 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Outcome is the outcome of a request.
+type Outcome string
+
+const (
+	// Passed means every assertion passed.
+	Passed Outcome = "PASS"
+	// Failed means the request failed.
+	Failed Outcome = "FAIL"
+	// Skipped means the request was skipped.
+	Skipped Outcome = "SKIP"
+	// Quarantined means the request failed, but it is quarantined
+	// by the Quarantine directive, so it doesn't fail the run.
+	Quarantined Outcome = "QUARANTINE"
+)
+
+// Result is the result of running a request.
+type Result struct {
+	Filename string
+	Group    string
+	Method   string
+	Path     string
+	// Name describes the run of the request, for example the data
+	// row that it uses. It is empty for requests that run once.
+	Name    string
+	Outcome Outcome
+	// Line is the line of the assertion or directive that caused
+	// the request to fail or be skipped, or the request heading.
+	Line int
+	// RequestLine is the line of the request heading.
+	RequestLine int
+	// Message says why the request failed or was skipped.
+	Message string
+	// Quarantine is the ticket of the Quarantine directive, if the
+	// request is quarantined.
+	Quarantine string
+	// Details explain the failure, for example a diff of the body.
+	Details []string
+	// Start is when the request started, and Duration how long
+	// it took.
+	Start    time.Time
+	Duration time.Duration
+
+	// URL, RequestHeader and RequestBody describe the request, if
+	// it was made.
+	URL           string
+	RequestHeader http.Header
+	RequestBody   string
+	// RequestID is the ID sent in the RequestIDHeader, if the
+	// Runner has one, and TraceID the ID of the trace, if the Runner
+	// traces requests.
+	RequestID string
+	TraceID   string
+	// Proto, StatusCode, Header and ResponseBody describe the
+	// response, if there was one.
+	Proto        string
+	StatusCode   int
+	Header       http.Header
+	ResponseBody []byte
+	// Unreachable is whether the request failed because the server
+	// couldn't be reached, or didn't respond, rather than because of
+	// its response.
+	Unreachable bool
+	// Assertions are the assertions that were checked, in order.
+	// A request stops at the first assertion that fails.
+	Assertions []Assertion
+}
+
+// ids gets the IDs that find the request in the logs and traces of
+// the server, like "(request ID 3d0d67bb-..., trace ID 4bf92f35...)",
+// or empty if it has none.
+func (result *Result) ids() string {
+	var ids []string
+	if result.RequestID != "" {
+		ids = append(ids, "request ID "+result.RequestID)
+	}
+	if result.TraceID != "" {
+		ids = append(ids, "trace ID "+result.TraceID)
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(ids, ", ") + ")"
+}
+
+// Assertion is the outcome of an assertion about a response.
+type Assertion struct {
+	Line int
+	// Text is the assertion, as it is in the file, or "body" for
+	// the expected body.
+	Text   string
+	Passed bool
+}
+
+// Reporter reports the results of requests.
+type Reporter interface {
+	// Report is called with the result of each request as it
+	// finishes.
+	Report(*Result)
+}
+
+// ReporterFunc is a function that is a Reporter.
+type ReporterFunc func(*Result)
+
+// Report calls fn(result).
+func (fn ReporterFunc) Report(result *Result) {
+	fn(result)
+}
+
+// Summarizer is implemented by Reporters that report a summary
+// at the end of a run.
+type Summarizer interface {
+	Summarize(*Summary)
+}
+
+// Summary summarizes the results of running requests.
+type Summary struct {
+	// Files is the number of files run.
+	Files int
+	// Requests is the number of requests made.
+	Requests    int
+	Passed      int
+	Failed      int
+	Skipped     int
+	Quarantined int
+	Duration    time.Duration
+	// Seed is the seed of the random values of the run. See
+	// Runner.Seed.
+	Seed int64
+	// Results are the results of each request, including their
+	// durations.
+	Results []*Result
+}
+
+// summarize makes a Summary of the results of running the groups.
+func summarize(groups []*parse.Group, results []*Result, d time.Duration) *Summary {
+	files := make(map[string]bool)
+	for _, group := range groups {
+		files[group.Filename] = true
+	}
+	summary := &Summary{
+		Duration: d,
+		Results:  results,
+	}
+	for _, result := range results {
+		files[result.Filename] = true
+		switch result.Outcome {
+		case Passed:
+			summary.Passed++
+		case Failed:
+			summary.Failed++
+		case Skipped:
+			summary.Skipped++
+		case Quarantined:
+			summary.Quarantined++
+		}
+	}
+	summary.Files = len(files)
+	summary.Requests = summary.Passed + summary.Failed + summary.Quarantined
+	return summary
+}
+
+// NewTextReporter makes a Reporter that logs failed and skipped
+// requests, and a summary of each run, as text.
+// If color is true, the text is colored with ANSI escape codes.
+func NewTextReporter(log func(string), color bool) Reporter {
+	return &textReporter{log: log, color: color}
+}
+
+type textReporter struct {
+	log   func(string)
+	color bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+func (t *textReporter) Report(result *Result) {
+	if result.Outcome == Passed {
+		return
+	}
+	for _, line := range result.Details {
+		t.log(t.colorDetail(line))
+	}
+	args := []interface{}{"---", t.paint(outcomeColor(result.Outcome), string(result.Outcome)+":"), result.Method, result.Path}
+	if result.Name != "" {
+		args = append(args, "("+result.Name+")")
+	}
+	args = append(args, "\n", result.Filename+":"+strconv.Itoa(result.Line), "-", result.Message)
+	if ids := result.ids(); ids != "" {
+		args = append(args, ids)
+	}
+	t.log(sprint(args...))
+}
+
+func (t *textReporter) Summarize(summary *Summary) {
+	outcome := Passed
+	if summary.Failed > 0 {
+		outcome = Failed
+	}
+	quarantined := ""
+	if summary.Quarantined > 0 {
+		quarantined = fmt.Sprintf(", %d quarantined", summary.Quarantined)
+	}
+	t.log(fmt.Sprintf("%s %d file(s), %d request(s): %d passed, %d failed, %d skipped%s (%s, seed %d)",
+		t.paint(outcomeColor(outcome), string(outcome)+":"),
+		summary.Files, summary.Requests, summary.Passed, summary.Failed, summary.Skipped, quarantined,
+		summary.Duration.Round(time.Millisecond), summary.Seed))
+	for _, result := range summary.Results {
+		if result.Outcome == Quarantined {
+			t.log(sprint(indent, t.paint(ansiYellow, "quarantined:"), result.Method, result.Path, "("+result.Quarantine+")", result.Filename+":"+strconv.Itoa(result.Line)))
+		}
+	}
+}
+
+// colorDetail colors the lines of diffs.
+func (t *textReporter) colorDetail(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return t.paint(ansiCyan, line)
+	case strings.HasPrefix(line, "-"):
+		return t.paint(ansiRed, line)
+	case strings.HasPrefix(line, "+"):
+		return t.paint(ansiGreen, line)
+	}
+	return line
+}
+
+func (t *textReporter) paint(color, s string) string {
+	if !t.color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func outcomeColor(outcome Outcome) string {
+	switch outcome {
+	case Passed:
+		return ansiGreen
+	case Skipped, Quarantined:
+		return ansiYellow
+	}
+	return ansiRed
+}
+
+// ColorEnabled gets whether output to f should be colored: when f
+// is a terminal, and the NO_COLOR environment variable isn't set.
+func ColorEnabled(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// MultiReporter makes a Reporter that reports to each of the
+// reporters, including their summaries.
+func MultiReporter(reporters ...Reporter) Reporter {
+	return multiReporter(reporters)
+}
+
+type multiReporter []Reporter
+
+func (m multiReporter) Report(result *Result) {
+	for _, reporter := range m {
+		reporter.Report(result)
+	}
+}
+
+func (m multiReporter) Summarize(summary *Summary) {
+	for _, reporter := range m {
+		if summarizer, ok := reporter.(Summarizer); ok {
+			summarizer.Summarize(summary)
+		}
+	}
+}
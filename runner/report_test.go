@@ -0,0 +1,186 @@
+package runner_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestReporter(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_SKIP", "true")
+	defer os.Unsetenv("SILK_SKIP")
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var results []*runner.Result
+	r.Reporter = runner.ReporterFunc(func(result *runner.Result) {
+		results = append(results, result)
+	})
+	r.RunFile("../testfiles/success/skip.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(results), 2)
+	is.Equal(results[0].Outcome, runner.Skipped)
+	is.Equal(results[0].Method, "GET")
+	is.Equal(results[0].Path, "/skipped")
+	is.Equal(results[0].Group, "Skip")
+	is.Equal(results[0].Line, 5)
+	is.Equal(results[0].Message, `${SILK_SKIP} == "true"`)
+	is.Equal(results[1].Outcome, runner.Passed)
+	is.Equal(results[1].Path, "/not-skipped")
+	is.Equal(results[1].Line, 11)
+	is.True(results[1].Duration > 0)
+	is.False(results[1].Start.IsZero())
+	is.True(results[1].RequestHeader != nil)
+
+	results = nil
+	r.RunFile("../testfiles/failure/echo.failure.wrongbody.silk.md")
+	is.True(subT.Failed())
+	is.Equal(len(results), 1)
+	is.Equal(results[0].Outcome, runner.Failed)
+	is.Equal(results[0].Line, 14)
+	is.Equal(results[0].Message, "body doesn't match")
+	is.True(len(results[0].Details) > 0)
+}
+
+func TestTextReporterColor(t *testing.T) {
+	is := is.New(t)
+	var logs []string
+	reporter := runner.NewTextReporter(func(s string) {
+		logs = append(logs, s)
+	}, true)
+	reporter.Report(&runner.Result{Outcome: runner.Passed})
+	is.Equal(len(logs), 0)
+	reporter.Report(&runner.Result{
+		Filename: "comments.silk.md",
+		Method:   "GET",
+		Path:     "/comments",
+		Outcome:  runner.Failed,
+		Line:     12,
+		Message:  "body doesn't match",
+		Details:  []string{"body differs (-expected +actual):", "@@ -1,1 +1,1 @@", "-silky", "+silk"},
+	})
+	is.Equal(logs[1], "\x1b[36m@@ -1,1 +1,1 @@\x1b[0m")
+	is.Equal(logs[2], "\x1b[31m-silky\x1b[0m")
+	is.Equal(logs[3], "\x1b[32m+silk\x1b[0m")
+	is.True(strings.HasPrefix(logs[4], "--- \x1b[31mFAIL:\x1b[0m GET /comments"))
+	is.True(strings.Contains(logs[4], "comments.silk.md:12 - body doesn't match"))
+}
+
+func TestTextReporterIDs(t *testing.T) {
+	is := is.New(t)
+	var logs []string
+	reporter := runner.NewTextReporter(func(s string) {
+		logs = append(logs, s)
+	}, false)
+	reporter.Report(&runner.Result{
+		Filename:  "comments.silk.md",
+		Method:    "GET",
+		Path:      "/comments",
+		Outcome:   runner.Failed,
+		Line:      12,
+		Message:   "Status doesn't match",
+		RequestID: "3d0d67bb",
+		TraceID:   "4bf92f35",
+	})
+	is.True(strings.Contains(logs[0], "comments.silk.md:12 - Status doesn't match (request ID 3d0d67bb, trace ID 4bf92f35)"))
+}
+
+func TestColorEnabled(t *testing.T) {
+	is := is.New(t)
+	f, err := ioutil.TempFile("", "silk")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	is.False(runner.ColorEnabled(f))
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+	is.False(runner.ColorEnabled(os.Stdout))
+}
+
+func TestSummary(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_SKIP", "true")
+	defer os.Unsetenv("SILK_SKIP")
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/success/skip.silk.md", "../testfiles/failure/echo.failure.wrongbody.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "FAIL: 2 file(s), 2 request(s): 1 passed, 1 failed, 1 skipped ("))
+
+	summary := r.Summary()
+	is.Equal(summary.Files, 2)
+	is.Equal(summary.Requests, 2)
+	is.Equal(summary.Passed, 1)
+	is.Equal(summary.Failed, 1)
+	is.Equal(summary.Skipped, 1)
+	is.Equal(len(summary.Results), 3)
+	is.Equal(summary.Duration, summary.Results[0].Duration+summary.Results[1].Duration+summary.Results[2].Duration)
+}
+
+func TestTranscript(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	var buf bytes.Buffer
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Transcript = &buf
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	is.False(subT.Failed())
+	is.True(strings.Contains(buf.String(), " PASS ../testfiles/success/echo.success.silk.md:"))
+	is.True(strings.Contains(buf.String(), "> GET "+s.URL+"/echo"))
+	is.True(strings.Contains(buf.String(), "< 200 OK\n"))
+}
+
+func TestTranscriptReporter(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	start := time.Date(2016, 3, 1, 10, 0, 0, 0, time.UTC)
+	reporter := runner.NewTranscriptReporter(&buf)
+	reporter.Report(&runner.Result{
+		Filename:      "comments.silk.md",
+		Method:        "POST",
+		Path:          "/comments",
+		Outcome:       runner.Failed,
+		Line:          12,
+		Message:       "Status doesn't match",
+		Start:         start,
+		Duration:      42 * time.Millisecond,
+		URL:           "http://localhost:8080/comments",
+		RequestHeader: http.Header{"Content-Type": {"application/json"}},
+		RequestBody:   `{"name":"Mat"}`,
+		StatusCode:    500,
+		Header:        http.Header{"Content-Type": {"image/png"}},
+		ResponseBody:  []byte{0x89, 'P', 'N', 'G', 0},
+	})
+	is.Equal(buf.String(), "=== 2016-03-01T10:00:00Z FAIL comments.silk.md:12 POST /comments 42ms\n"+
+		"Status doesn't match\n"+
+		"> POST http://localhost:8080/comments\n"+
+		"> Content-Type: application/json\n"+
+		">\n"+
+		"> {\"name\":\"Mat\"}\n"+
+		"< 500 Internal Server Error\n"+
+		"< Content-Type: image/png\n"+
+		"<\n"+
+		"< (5 bytes of binary data)\n"+
+		"\n")
+}
@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newRequestID makes a request ID, a random (version 4) UUID. They
+// don't come from the Seed, so runs with the same Seed still have
+// their own request IDs in the logs of the server.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return formatUUID(b)
+}
+
+// formatUUID formats random bytes as a version 4 UUID.
+func formatUUID(b [16]byte) string {
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
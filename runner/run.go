@@ -2,15 +2,22 @@ package runner
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"reflect"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"text/template"
+	"time"
 
 	"github.com/cheekybits/m"
 	"github.com/matryer/silk/parse"
@@ -29,9 +36,14 @@ type T interface {
 type Runner struct {
 	t       T
 	rootURL string
+	// socket is the path of the unix domain socket to connect to,
+	// if the root URL is a unix:// URL.
+	socket string
 	// RoundTripper is the transport to use when making requests.
 	// By default it is http.DefaultTransport.
 	RoundTripper http.RoundTripper
+	// Middleware wraps the RoundTripper. See Use.
+	Middleware []Middleware
 	// ParseBody is the function to use to attempt to parse
 	// response bodies to make data avaialble for assertions.
 	ParseBody func(r io.Reader) (interface{}, error)
@@ -41,12 +53,189 @@ type Runner struct {
 	Verbose func(...interface{})
 	// NewRequest makes a new http.Request. By default, uses http.NewRequest.
 	NewRequest func(method, urlStr string, body io.Reader) (*http.Request, error)
+	// BeforeRequest is called with each request before it is made,
+	// and may modify it. Returning an error fails the request.
+	BeforeRequest func(*http.Request) error
+	// AfterResponse is called with each response before assertions
+	// are made. Returning an error fails the request.
+	AfterResponse func(*http.Response) error
+	// Header are headers sent with every request. Headers in the
+	// request with the same name replace them.
+	Header http.Header
+	// Vars are variables available to every request. Captured
+	// variables with the same name take precedence.
+	Vars map[string]interface{}
+	// Tags selects which requests to run by their tags.
+	// Tags are separated by spaces or commas, and those prefixed
+	// with ! are excluded, for example "smoke !slow".
+	// By default, all requests are run.
+	Tags string
+	// Run selects which requests to run by a regular expression,
+	// which is matched against the titles of groups (selecting all
+	// of their requests), and the method and path of requests,
+	// like "GET /users/{id}". By default, all requests are run.
+	Run string
+	// Secrets provides the values of ${secret:name} references in
+	// request paths, headers, parameters and bodies. Secret values
+	// are redacted from logs and reports.
+	Secrets SecretProvider
+	// Redact are the names of headers (like Authorization or
+	// Set-Cookie) and JSON fields (like password) whose values are
+	// redacted from logs, diffs and reports, in requests and
+	// responses.
+	Redact []string
+	// Asserters are assertions written in Go, which silk files
+	// call with the Assert detail. See Register.
+	Asserters []Asserter
+	// Databases are the databases that SQL steps query, by name.
+	// The drivers are imported by the program that uses the Runner.
+	Databases map[string]*sql.DB
+	// Consumers get the messages that CONSUME steps wait for, by
+	// the name of their broker.
+	Consumers map[string]Consumer
+	// Funcs are additional functions available to templates.
+	// Built-in functions with the same name are replaced.
+	Funcs template.FuncMap
+	// EscapePathVars escapes the values of {name} references in the
+	// paths of requests as path segments, so values with slashes or
+	// spaces stay in one segment. {{.name}} references are inserted
+	// as they are either way, and the pathEscape and queryEscape
+	// template functions escape values deliberately.
+	EscapePathVars bool
+	// RequestIDHeader is the header, like X-Request-ID, to send a
+	// new request ID in with every request, so that failures can be
+	// found in the logs of the server. Requests that set the header
+	// keep their own value. The ID is in the Result, and failures
+	// mention it. Empty means no request IDs.
+	RequestIDHeader string
+	// Trace starts a trace for every request, with a W3C Trace
+	// Context traceparent header, so the traces of failures can be
+	// found in the tracing backend of the server. The request is the
+	// root span (silk doesn't export spans). Requests that have a
+	// traceparent header, for example from the Header, keep it. The
+	// trace ID is in the Result, and failures mention it.
+	Trace bool
+	// PrintCurl logs the curl command equivalent to each request
+	// before it is made, so it can be reproduced by hand.
+	PrintCurl bool
+	// Reporter reports the result of each request. By default,
+	// failures are logged to Log, colored if stdout is a terminal.
+	// If it is also a Summarizer, it is given a Summary at the end
+	// of each RunFile and RunGroup.
+	Reporter Reporter
+	// ContinueOnFailure keeps running the remaining requests and
+	// groups after a request fails, and fails the test at the end
+	// of RunFile or RunGroup instead.
+	// If a Setup request fails, the rest of its group is still
+	// skipped.
+	ContinueOnFailure bool
+	// OnFailure is what to do when a request fails: stop (FailFast,
+	// the default), finish its group and then stop (FinishGroup),
+	// or run everything and fail at the end (RunAll, which is the
+	// same as ContinueOnFailure).
+	// If a Setup request fails, the rest of its group is skipped
+	// whatever the policy.
+	OnFailure Policy
+	// Proxy is the URL of the proxy to make requests through, like
+	// http://proxy:3128 or socks5://localhost:1080, or "direct" to
+	// connect directly. By default, the RoundTripper decides, and
+	// http.DefaultTransport uses the HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY environment variables.
+	// Groups and requests may set their own with a Proxy directive.
+	// Proxies need the RoundTripper to be an *http.Transport.
+	Proxy string
+	// ConnectTo is the address (IP:port, or an IP to keep the port
+	// of the URL) to connect to instead of the host of the URL,
+	// for example to test a server before DNS points to it.
+	// The Host header and TLS server name still come from the URL.
+	// Groups and requests may set their own with a ConnectTo directive.
+	// It needs the RoundTripper to be an *http.Transport.
+	ConnectTo string
+	// ExactBody compares response bodies with expected bodies
+	// byte for byte. By default, they are compared according to
+	// the Content-Type of the response: JSON bodies by the data
+	// they contain, binary bodies byte for byte, and other bodies
+	// as text, line by line, ignoring differences in spaces.
+	ExactBody bool
+	// RateLimit is the most requests to make per second, to avoid
+	// tripping the rate limits of APIs or overwhelming shared
+	// environments. Zero means no limit. Groups and requests may
+	// set their own with the Throttle directive.
+	RateLimit float64
+	// Timeout is how long each request can take, including reading
+	// its response, before it fails. Zero means no timeout.
+	Timeout time.Duration
+	// Transcript, if set, is written a transcript of every request
+	// and response, with their headers and bodies, whatever the
+	// outcome and however verbose the run is. Secrets are redacted.
+	Transcript io.Writer
+	// Session is how widely the values captured by requests, and
+	// the cookies set by responses, are shared between groups.
+	// By default, captured values are kept within their group and
+	// cookies aren't kept. File sessions start again with each
+	// RunFile and RunGroup, and run sessions last as long as the
+	// Runner.
+	Session Session
+	// Seed seeds the random values of templates, like uuid, randInt
+	// and fake, so that a failing run can be reproduced by running
+	// it again with the same Seed. Each RunFile and RunGroup starts
+	// again from the Seed. Zero means a new seed for each run.
+	// The seed of a run is in its Summary.
+	Seed int64
+	// DryRun logs every request (its method, URL, headers and
+	// body) instead of making it, and reports it as skipped, to
+	// review what the files will do before running them against
+	// a real environment. Values that requests would capture are
+	// shown as placeholders, like {userID}.
+	DryRun bool
+	// Shuffle runs the groups in a random order (from the Seed),
+	// to find groups that depend on others without saying so with
+	// the After directive. Groups still run after those they
+	// depend on.
+	Shuffle bool
+	// Step, if set, is called with each request (as text, like
+	// dry runs print it) before it is made, and decides whether to
+	// make it, skip it, or stop the run, to step through a file
+	// when debugging it.
+	Step func(request string) StepAction
+
+	// results are the results of every request run.
+	results []*Result
+	// failed is whether a request has failed since the test was
+	// last failed, when ContinueOnFailure is set.
+	failed bool
+	// run is the compiled Run regexp of the current run.
+	run *regexp.Regexp
+	// ctx is the context of the current run.
+	ctx context.Context
+	// transports are the customized transports made from the
+	// RoundTripper.
+	transports map[transportKey]*http.Transport
+	// lastRequest is when the last request was made, for the
+	// RateLimit.
+	lastRequest time.Time
+	// sessions are the shared state of the groups, by filename (or
+	// "" for SessionRun).
+	sessions map[string]*session
+	// rnd makes the random values of the current run.
+	rnd *random
+	// aborted is whether Step stopped the current run.
+	aborted bool
+	// idempotency is the state of the Idempotency-Keys of the
+	// current run.
+	idempotency *idempotency
+	// snippets are the snippets of the current run, by filename
+	// and name.
+	snippets map[string]map[string]*parse.Request
 }
 
 // New makes a new Runner with the given testing T target and the
 // root URL.
+// Root URLs like unix:///var/run/app.sock make requests over the
+// unix domain socket, which needs the RoundTripper to be an
+// *http.Transport.
 func New(t T, URL string) *Runner {
-	return &Runner{
+	r := &Runner{
 		t:            t,
 		rootURL:      URL,
 		RoundTripper: http.DefaultTransport,
@@ -61,24 +250,38 @@ func New(t T, URL string) *Runner {
 		},
 		ParseBody:  ParseJSONBody,
 		NewRequest: http.NewRequest,
+		Vars:       make(map[string]interface{}),
+	}
+	r.Reporter = NewTextReporter(func(s string) {
+		r.Log(s)
+	}, ColorEnabled(os.Stdout))
+	if strings.HasPrefix(URL, unixScheme) {
+		r.socket = strings.TrimPrefix(URL, unixScheme)
+		r.rootURL = unixRootURL
 	}
+	return r
 }
 
 func (r *Runner) log(args ...interface{}) {
+	r.Log(sprint(args...))
+}
+
+func sprint(args ...interface{}) string {
 	var strs []string
 	for _, arg := range args {
 		strs = append(strs, fmt.Sprint(arg))
 	}
 	strs = append(strs, " ")
-	r.Log(strings.Join(strs, " "))
+	return strings.Join(strs, " ")
 }
 
 // RunGlob is a helper that runs the files returned by filepath.Glob.
-//     runner.RunGlob(filepath.Glob("pattern"))
+//
+//	runner.RunGlob(filepath.Glob("pattern"))
 func (r *Runner) RunGlob(files []string, err error) {
+	helperOf(r.t).Helper()
 	if err != nil {
-		r.t.Log("silk:", err)
-		r.t.FailNow()
+		fatalf(r.t, func(s string) { r.t.Log(s) }, "silk: %v", err)
 		return
 	}
 	r.RunFile(files...)
@@ -86,99 +289,640 @@ func (r *Runner) RunGlob(files []string, err error) {
 
 // RunFile parses and runs the specified file(s).
 func (r *Runner) RunFile(filenames ...string) {
+	helperOf(r.t).Helper()
+	r.RunFileContext(context.Background(), filenames...)
+}
+
+// RunFileContext parses and runs the specified file(s), making
+// requests with the context. When the context is cancelled or
+// its deadline passes, the request being made fails and no more
+// requests are made.
+func (r *Runner) RunFileContext(ctx context.Context, filenames ...string) {
+	helperOf(r.t).Helper()
 	groups, err := parse.ParseFile(filenames...)
 	if err != nil {
-		r.log(err)
+		fatalf(r.t, r.Log, "%v", err)
 		return
 	}
-	r.RunGroup(groups...)
+	r.RunGroupContext(ctx, groups...)
 }
 
 // RunGroup runs a parse.Group.
 // Consider RunFile instead.
 func (r *Runner) RunGroup(groups ...*parse.Group) {
+	helperOf(r.t).Helper()
+	r.RunGroupContext(context.Background(), groups...)
+}
+
+// RunGroupContext runs a parse.Group, making requests with the
+// context. See RunFileContext.
+func (r *Runner) RunGroupContext(ctx context.Context, groups ...*parse.Group) {
+	helperOf(r.t).Helper()
+	r.ctx = ctx
+	defer func() {
+		r.ctx = nil
+	}()
+	if r.Session == SessionFile {
+		r.sessions = nil
+	}
+	r.rnd = nil
+	r.idempotency = nil
+	r.snippets = snippets(groups)
+	r.aborted = false
+	seed := r.random().seed
+	start := time.Now()
+	n := len(r.results)
+	// summarize even if a failure stops the test
+	defer func() {
+		if summarizer, ok := r.Reporter.(Summarizer); ok {
+			summary := summarize(groups, r.results[n:], time.Since(start))
+			summary.Seed = seed
+			summarizer.Summarize(summary)
+		}
+		if r.failed {
+			r.failed = false
+			r.t.FailNow()
+		}
+	}()
+	if r.Shuffle {
+		r.Log(fmt.Sprintf("shuffle: seed %d", seed))
+		groups = r.random().shuffle(groups)
+	}
+	groups, err := orderGroups(groups)
+	if err != nil {
+		fatalf(r.t, r.Log, "%v", err)
+		return
+	}
+	r.run = nil
+	if r.Run != "" {
+		if r.run, err = regexp.Compile(r.Run); err != nil {
+			fatalf(r.t, r.Log, "run: %v", err)
+			return
+		}
+	}
+	// passed is whether every group with each title passed
+	passed := make(map[string]bool, len(groups))
 	for _, group := range groups {
-		r.runGroup(group)
+		passed[string(group.Title)] = true
 	}
+	_, subtests := r.t.(subtester)
+	for _, group := range groups {
+		if r.stopped() {
+			return
+		}
+		if dep := failedDependency(group, passed); dep != "" {
+			passed[string(group.Title)] = false
+			r.skipGroup(group, dep+" failed")
+			continue
+		}
+		ok := r.runGroup(group)
+		if !ok {
+			passed[string(group.Title)] = false
+		}
+		// a failed subtest doesn't stop the test, so stop here
+		if !ok && (subtests && r.policy() == FailFast || r.policy() == FinishGroup) {
+			return
+		}
+	}
+}
+
+// Summary gets a Summary of every request run by the Runner.
+// Its Duration is the sum of the request durations, and its Seed
+// is the seed of the last run.
+func (r *Runner) Summary() *Summary {
+	var d time.Duration
+	for _, result := range r.results {
+		d += result.Duration
+	}
+	summary := summarize(nil, r.results, d)
+	summary.Seed = r.random().seed
+	return summary
+}
+
+// subtester is implemented by T types that can run subtests,
+// like *testing.T.
+type subtester interface {
+	Run(name string, f func(t *testing.T)) bool
 }
 
-func (r *Runner) runGroup(group *parse.Group) {
+// runGroup runs the group, as a subtest if the T supports them.
+// Gets whether it passed.
+func (r *Runner) runGroup(group *parse.Group) bool {
+	helperOf(r.t).Helper()
+	if st, ok := r.t.(subtester); ok {
+		return st.Run(string(group.Title), func(t *testing.T) {
+			t.Helper()
+			r.runGroupT(t, group)
+		})
+	}
+	return r.runGroupT(r.t, group)
+}
+
+// runGroupT runs the group, reporting failures to t.
+// Gets whether it passed.
+func (r *Runner) runGroupT(t T, group *parse.Group) bool {
+	helperOf(t).Helper()
 	//r.log("===", group.Filename+":", string(group.Title))
+	var requests []*parse.Request
 	for _, req := range group.Requests {
-		r.runRequest(group, req)
+		if isSnippet(req) {
+			continue
+		}
+		if r.Tags != "" && !matchTags(r.Tags, requestTags(group, req)) {
+			r.Verbose(string(req.Method), string(req.Path), "(not selected by tags)")
+			continue
+		}
+		if !selected(r.run, group, req) {
+			r.Verbose(string(req.Method), string(req.Path), "(not selected by run)")
+			continue
+		}
+		requests = append(requests, req)
+	}
+	if len(requests) == 0 && len(group.Requests) > 0 {
+		return true
+	}
+	// variables captured by requests in this group, or its session
+	vars := make(map[string]interface{})
+	if s := r.session(group); s != nil {
+		vars = s.vars
+	}
+	passed := true
+	defer func() {
+		helperOf(t).Helper()
+		// teardown runs regardless of failures
+		for _, req := range group.Teardown {
+			r.runRequestT(t, group, req, vars)
+		}
+	}()
+	for _, req := range group.Setup {
+		if !r.runRequestT(t, group, req, vars) {
+			return false
+		}
+	}
+	_, subtests := t.(subtester)
+	for _, req := range requests {
+		if r.stopped() {
+			return false
+		}
+		if r.runRequestT(t, group, req, vars) {
+			continue
+		}
+		passed = false
+		if subtests && r.policy() == FailFast {
+			break
+		}
+	}
+	return passed
+}
+
+// runRequestT runs the request, as a subtest if t supports them.
+// Gets whether it passed.
+func (r *Runner) runRequestT(t T, group *parse.Group, req *parse.Request, vars map[string]interface{}) bool {
+	helperOf(t).Helper()
+	if st, ok := t.(subtester); ok {
+		return st.Run(string(req.Method)+" "+string(req.Path), func(t *testing.T) {
+			t.Helper()
+			r.runRequest(&call{t: t, subtest: true, group: group, req: req, vars: vars})
+		})
+	}
+	// quarantined requests and expected failures don't fail
+	// their group, so check what was reported
+	n := len(r.results)
+	r.runRequest(&call{t: t, group: group, req: req, vars: vars})
+	for _, result := range r.results[n:] {
+		if result.Outcome == Failed {
+			return false
+		}
+	}
+	return true
+}
+
+// runRequest runs the request of the call, and gets whether it
+// passed.
+func (r *Runner) runRequest(c *call) bool {
+	helperOf(c.t).Helper()
+	group, req, vars := c.group, c.req, c.vars
+	if line := directive(req.Details, directiveUse); line != nil {
+		return r.useSnippet(c, line)
+	}
+	if line := directive(req.Details, directiveSkipIf); line != nil {
+		cond, err := interpolate(fmt.Sprintf("%v", line.Detail().Value.Data), r.templateData(c), r.funcs())
+		if err != nil {
+			r.fail(c, line.Number, directiveSkipIf+":", err)
+			return false
+		}
+		skip, err := evalCondition(cond)
+		if err != nil {
+			r.fail(c, line.Number, directiveSkipIf+":", err)
+			return false
+		}
+		if skip {
+			r.skip(c, line.Number, cond)
+			return true
+		}
+	}
+	line := directive(req.Details, directiveDataFile)
+	if line == nil {
+		return r.repeat(c)
 	}
+	// run the request once for each row in the data file
+	filename := fmt.Sprintf("%v", line.Detail().Value.Data)
+	rows, err := loadDataFile(filepath.Join(filepath.Dir(group.Filename), filename))
+	if err != nil {
+		r.fail(c, line.Number, directiveDataFile+":", err)
+		return false
+	}
+	passed := true
+	for i, row := range rows {
+		rowVars := copyVars(vars, row)
+		if !r.repeat(&call{t: c.t, subtest: c.subtest, group: group, req: req, vars: rowVars, local: row, name: fmt.Sprintf("row %d", i+1)}) {
+			passed = false
+		}
+		// values captured from the row are available to later
+		// requests, but the row itself isn't
+		keepCaptures(vars, rowVars, row)
+	}
+	return passed
+}
+
+// repeat performs the call the number of times specified by
+// the Repeat directive, stopping at the first failure.
+// Gets whether every call passed.
+func (r *Runner) repeat(c *call) bool {
+	helperOf(c.t).Helper()
+	line := directive(c.req.Details, directiveRepeat)
+	if line == nil {
+		return r.poll(c)
+	}
+	if err := checkPositiveInt(line.Detail().Value); err != nil {
+		r.fail(c, line.Number, directiveRepeat+":", err)
+		return false
+	}
+	n := line.Detail().Value.Data.(float64)
+	// captured variables are kept between iterations, and are
+	// available to later requests
+	vars := copyVars(c.vars, nil)
+	defer keepCaptures(c.vars, vars, map[string]interface{}{iterationVar: nil})
+	for i := 1; i <= int(n); i++ {
+		vars[iterationVar] = i
+		name := fmt.Sprintf("iteration %d", i)
+		if c.name != "" {
+			name = c.name + ", " + name
+		}
+		local := copyVars(c.local, map[string]interface{}{iterationVar: i})
+		if !r.poll(&call{t: c.t, subtest: c.subtest, group: c.group, req: c.req, vars: vars, local: local, name: name}) {
+			return false
+		}
+	}
+	return true
+}
+
+// call is a single execution of a request.
+type call struct {
+	// t is where failures are reported.
+	t T
+	// subtest is whether t is a subtest for the request.
+	subtest bool
+	group   *parse.Group
+	req     *parse.Request
+	// vars are the variables available to the call, and where
+	// captured values are stored.
+	vars map[string]interface{}
+	// local are the variables that only belong to the call, like
+	// the row of a data file and the iteration, which are also
+	// in vars.
+	local map[string]interface{}
+	// name optionally describes the call, for example the
+	// data row that it uses.
+	name string
+	// polling is whether the call is an attempt of a PollUntil
+	// request that will be made again if it fails, so failures
+	// aren't reported.
+	polling bool
+	// start is when the call started.
+	start time.Time
+	// details are logged when the call fails, to explain why.
+	details []string
+	// url, requestHeader and requestBody describe the request
+	// that was made, and response and responseBody the response.
+	url           string
+	requestHeader http.Header
+	requestBody   string
+	response      *http.Response
+	responseBody  []byte
+	// assertions are the assertions made about the response.
+	assertions []Assertion
+	// unreachable is whether the request couldn't reach the server.
+	unreachable bool
+	// requestID is the ID sent in the RequestIDHeader.
+	requestID string
+	// traceID is the ID of the trace of the traceparent header, when
+	// the Runner traces requests.
+	traceID string
+	// secrets are the values to redact from what the call logs
+	// and reports: secrets used by the call, and the values of the
+	// Redact headers and fields.
+	secrets []string
 }
 
-func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
+// log adds a line to the details of the call.
+func (c *call) log(args ...interface{}) {
+	c.details = append(c.details, sprint(args...))
+}
+
+// call performs the call, and gets whether it passed.
+func (r *Runner) call(c *call) bool {
+	helperOf(c.t).Helper()
+	switch string(c.req.Method) {
+	case parse.MethodExec:
+		return r.exec(c)
+	case parse.MethodSQL:
+		return r.query(c)
+	case parse.MethodConsume:
+		return r.consume(c)
+	}
+	if !r.DryRun {
+		if err := r.waitForRateLimit(c); err != nil {
+			r.fail(c, c.req.Number, err)
+			return false
+		}
+	}
+	c.start = time.Now()
+	req := c.req
 	m := string(req.Method)
-	p := string(req.Path)
+	tplData := r.templateData(c)
+	funcs := r.funcs()
+	p, err := r.interpolatePath(string(req.Path), tplData, funcs)
+	if err == nil {
+		p, err = r.revealSecrets(c, p)
+	}
+	if err != nil {
+		r.fail(c, req.Number, "invalid request:", err)
+		return false
+	}
 	var body io.Reader
+	var bodyStr string
+	if req.BodyLang == langBase64 {
+		// binary bodies are sent as they are
+		b, err := RequestBody(req)
+		if err != nil {
+			r.fail(c, req.Body.Number(), "invalid request:", err)
+			return false
+		}
+		bodyStr = string(b)
+	} else {
+		bodyStr, err = interpolate(req.Body.String(), tplData, funcs)
+		if err == nil {
+			bodyStr, err = r.revealSecrets(c, bodyStr)
+		}
+		if err != nil {
+			r.fail(c, req.Number, "invalid request:", err)
+			return false
+		}
+	}
 	if len(req.Body) > 0 {
-		body = req.Body.Reader()
+		body = strings.NewReader(bodyStr)
+	}
+	if len(req.Script) > 0 {
+		extra := map[string]interface{}{"Method": m, "Path": p, "Body": bodyStr}
+		if line, err := r.runScript(c, req.Script, extra); err != nil {
+			r.fail(c, line, "script:", err)
+			return false
+		}
+		// the path and body are already made, so only the
+		// headers and parameters can use the variables
+		tplData = r.templateData(c)
 	}
 
-	absPath := r.rootURL + p
-	r.Verbose(string(req.Method), absPath)
+	rootURL := r.rootURL
+	if fm := c.group.FrontMatter; fm != nil && fm.URL != "" {
+		rootURL = strings.TrimSuffix(fm.URL, "/")
+	}
+	absPath := rootURL + p
+	if isAbsoluteURL(p) {
+		// like a Location captured from an earlier response
+		absPath = p
+	}
+	if c.name != "" {
+		r.Verbose(string(req.Method), c.redact(absPath), "("+c.name+")")
+	} else {
+		r.Verbose(string(req.Method), c.redact(absPath))
+	}
+	r.logVars(c)
 
 	// make request
 	httpReq, err := r.NewRequest(m, absPath, body)
 	if err != nil {
-		r.log("invalid request: ", err)
-		r.t.FailNow()
-		return
+		r.fail(c, req.Number, "invalid request:", err)
+		return false
+	}
+	var continued bool
+	ctx := r.context()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	httpReq = httpReq.WithContext(traceContinue(ctx, &continued))
+	if expectContinue(c) > 0 {
+		httpReq.Header.Set("Expect", "100-continue")
 	}
 	// set body
-	bodyLen := len(req.Body.String())
+	bodyLen := len(bodyStr)
 	httpReq.Header.Add("Content-Length", strconv.Itoa(bodyLen))
 	r.Verbose(indent, "Content-Length:", bodyLen)
+	// headers and parameters are logged once the values to
+	// redact from them are known
+	var verbose []string
 	// set request headers
 	for _, line := range req.Details {
 		detail := line.Detail()
-		r.Verbose(indent, detail.String())
-		httpReq.Header.Add(detail.Key, fmt.Sprintf("%v", detail.Value.Data))
+		if IsDirective(detail.Key) {
+			continue
+		}
+		verbose = append(verbose, detail.String())
+		val, err := interpolate(fmt.Sprintf("%v", detail.Value.Data), tplData, funcs)
+		if err == nil {
+			val, err = r.revealSecrets(c, val)
+		}
+		if err != nil {
+			r.fail(c, line.Number, detail.Key+":", err)
+			return false
+		}
+		if http.CanonicalHeaderKey(detail.Key) == "Host" {
+			// net/http ignores a Host header
+			httpReq.Host = val
+			continue
+		}
+		httpReq.Header.Add(detail.Key, val)
+	}
+	if req.BodyLang == langXML {
+		setXMLHeaders(httpReq.Header, bodyStr)
+	}
+	var idempotencyKey string
+	if line := directive(req.Details, directiveIdempotencyKey); line != nil {
+		key, err := interpolate(fmt.Sprintf("%v", line.Detail().Value.Data), tplData, funcs)
+		if err != nil {
+			r.fail(c, line.Number, directiveIdempotencyKey+":", err)
+			return false
+		}
+		idempotencyKey = r.idempotencyKey(c, key)
+		httpReq.Header.Set(idempotencyHeader, idempotencyKey)
+	}
+	if fm := c.group.FrontMatter; fm != nil {
+		for k, v := range fm.Headers {
+			if _, ok := httpReq.Header[http.CanonicalHeaderKey(k)]; !ok {
+				httpReq.Header.Set(k, v)
+			}
+		}
+	}
+	for k, vs := range r.Header {
+		if _, ok := httpReq.Header[http.CanonicalHeaderKey(k)]; ok {
+			continue
+		}
+		for _, v := range vs {
+			httpReq.Header.Add(k, v)
+		}
+	}
+	if r.RequestIDHeader != "" {
+		c.requestID = httpReq.Header.Get(r.RequestIDHeader)
+		if c.requestID == "" {
+			c.requestID = newRequestID()
+			httpReq.Header.Set(r.RequestIDHeader, c.requestID)
+		}
+	}
+	if r.Trace {
+		c.traceID = traceparent(httpReq.Header)
+	}
+	if s := r.session(c.group); s != nil {
+		for _, cookie := range s.jar.Cookies(httpReq.URL) {
+			httpReq.AddCookie(cookie)
+		}
 	}
 	// set parameters
 	q := httpReq.URL.Query()
 	for _, line := range req.Params {
 		detail := line.Detail()
-		r.Verbose(indent, detail.String())
-		q.Add(detail.Key, fmt.Sprintf("%v", detail.Value.Data))
+		verbose = append(verbose, detail.String())
+		for _, val := range ParamValues(detail) {
+			val, err := interpolate(val, tplData, funcs)
+			if err == nil {
+				val, err = r.revealSecrets(c, val)
+			}
+			if err != nil {
+				r.fail(c, line.Number, detail.Key+":", err)
+				return false
+			}
+			q.Add(detail.Key, val)
+		}
 	}
 	httpReq.URL.RawQuery = q.Encode()
+	if line := directive(req.Details, directiveRawQuery); line != nil {
+		raw, err := interpolate(fmt.Sprintf("%v", line.Detail().Value.Data), tplData, funcs)
+		if err == nil {
+			raw, err = r.revealSecrets(c, raw)
+		}
+		if err != nil {
+			r.fail(c, line.Number, directiveRawQuery+":", err)
+			return false
+		}
+		if len(q) > 0 {
+			raw += "&" + httpReq.URL.RawQuery
+		}
+		httpReq.URL.RawQuery = raw
+	}
+
+	if r.BeforeRequest != nil {
+		if err := r.BeforeRequest(httpReq); err != nil {
+			r.fail(c, req.Number, "before request:", err)
+			return false
+		}
+	}
+	r.redactValues(c, httpReq.Header, []byte(bodyStr))
+	for _, line := range verbose {
+		r.Verbose(indent, c.redact(line))
+	}
+	if r.PrintCurl {
+		r.Log(c.redact(curl(httpReq, bodyStr)))
+	}
+	c.url, c.requestHeader, c.requestBody = httpReq.URL.String(), httpReq.Header, bodyStr
+	if !r.step(c, httpReq, bodyStr) {
+		return true
+	}
+	if r.DryRun {
+		r.dryRun(c, httpReq, bodyStr)
+		return true
+	}
 
 	// perform request
-	httpRes, err := r.RoundTripper.RoundTrip(httpReq)
+	transport, err := r.roundTripper(c)
 	if err != nil {
-		r.log(err)
-		r.t.FailNow()
-		return
+		r.fail(c, req.Number, err)
+		return false
+	}
+	sent := time.Now()
+	httpRes, err := transport.RoundTrip(httpReq)
+	if err != nil {
+		c.unreachable = true
+		r.fail(c, req.Number, err)
+		return false
 	}
 	defer httpRes.Body.Close()
-
-	// collect response details
-	responseDetails := make(map[string]interface{})
-	for k, vs := range httpRes.Header {
-		for _, v := range vs {
-			responseDetails[k] = v
-		}
+	if s := r.session(c.group); s != nil {
+		s.jar.SetCookies(httpReq.URL, httpRes.Cookies())
 	}
 
-	// set other details
-	responseDetails["Status"] = float64(httpRes.StatusCode)
-
-	actualBody, err := ioutil.ReadAll(httpRes.Body)
+	actualBody, stream, err := readStream(httpRes.Body, sent)
 	if err != nil {
-		r.log("failed to read body: ", err)
-		r.t.FailNow()
-		return
+		r.fail(c, req.Number, fmt.Sprintf("the body didn't end cleanly, after %d chunk(s):", stream.chunks), err)
+		return false
+	}
+	c.response, c.responseBody = httpRes, actualBody
+	var first *idempotentResponse
+	if idempotencyKey != "" {
+		first = r.firstResponse(idempotencyKey, httpRes, actualBody)
+	}
+	r.redactValues(c, httpRes.Header, actualBody)
+	if line, err := r.saveBody(c, actualBody, tplData, funcs); err != nil {
+		r.fail(c, line, directiveSaveBody+":", err)
+		return false
+	}
+
+	if r.AfterResponse != nil {
+		// let the hook read the body too
+		httpRes.Body = ioutil.NopCloser(bytes.NewReader(actualBody))
+		if err := r.AfterResponse(httpRes); err != nil {
+			r.fail(c, req.Number, "after response:", err)
+			return false
+		}
 	}
 
 	// assert the body
-	if len(req.ExpectedBody) > 0 {
+	expectedBodySrc, bodyLine, err := ExpectedBody(c.group, req)
+	if err != nil {
+		r.fail(c, bodyLine, err)
+		return false
+	}
+	if expectedBodySrc != nil && m == http.MethodHead {
+		r.fail(c, bodyLine, errHeadBody)
+		return false
+	}
+	if expectedBodySrc != nil {
+		expectedBody := string(expectedBodySrc)
+		// binary bodies are compared as they are
+		if !binary(expectedBodySrc) && req.ExpectedBodyLang != langBase64 {
+			expectedBody, err = interpolate(expectedBody, tplData, funcs)
+			if err != nil {
+				r.fail(c, bodyLine, "body:", err)
+				return false
+			}
+		}
 		// check body against expected body
-		if !r.assertBody(actualBody, req.ExpectedBody.Join()) {
-			r.fail(group, req, req.ExpectedBody.Number(), "- body doesn't match")
-			return
+		ok := r.assertBody(c, actualBody, []byte(expectedBody))
+		c.assertions = append(c.assertions, Assertion{Line: bodyLine, Text: "body", Passed: ok})
+		if !ok {
+			r.fail(c, bodyLine, "body doesn't match")
+			return false
 		}
 	}
 
@@ -188,84 +932,366 @@ func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
 	var errData error
 	if len(req.ExpectedDetails) > 0 {
 		for _, line := range req.ExpectedDetails {
+			line, err := interpolateLine(line, tplData, funcs)
+			if err != nil {
+				r.fail(c, line.Number, err)
+				return false
+			}
 			detail := line.Detail()
+			if IsResponseDirective(detail.Key) {
+				continue
+			}
+			var ok bool
 			if strings.HasPrefix(detail.Key, "Data") {
 				parseDataOnce.Do(func() {
-					data, errData = r.ParseBody(bytes.NewReader(actualBody))
+					data, errData = r.responseData(httpRes.Header, actualBody)
 				})
-				if !r.assertData(data, errData, detail.Key, detail.Value) {
-					r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
-					return
-				}
-				continue
+				ok = r.assertData(c, data, errData, detail.Key, detail.Value)
+			} else if detail.Key == firstByteKey || detail.Key == chunksKey {
+				ok = r.assertStream(c, detail.Key, stream, detail.Value)
+			} else if detail.Key == bodySizeKey {
+				ok = r.assertSize(c, detail.Key, bodySize(httpReq, httpRes, actualBody), detail.Value)
+			} else if detail.Key == idempotentKey {
+				ok = r.assertIdempotent(c, first, httpRes, actualBody, detail.Value)
+			} else if detail.Key == continueKey {
+				ok = r.assertContinue(c, continued, detail.Value)
+			} else if detail.Key == corsKey {
+				ok = r.assertCORS(c, httpReq, httpRes, detail.Value)
+			} else if detail.Key == assertKey {
+				ok = r.assertCustom(c, fmt.Sprintf("%v", detail.Value.Data), httpRes, actualBody, func() (interface{}, error) {
+					parseDataOnce.Do(func() {
+						data, errData = r.responseData(httpRes.Header, actualBody)
+					})
+					return data, errData
+				})
+			} else if _, isErrors := graphQLErrorsData(detail.Key); isErrors {
+				parseDataOnce.Do(func() {
+					data, errData = r.responseData(httpRes.Header, actualBody)
+				})
+				ok = r.assertGraphQLErrors(c, data, errData, detail.Key, detail.Value)
+			} else if path, isXPath := xpathKey(detail.Key); isXPath {
+				ok = r.assertXPath(c, detail.Key, path, actualBody, detail.Value)
+			} else if isAbsent(detail.Value) {
+				ok = r.assertAbsent(c, detail.Key, httpRes)
+			} else if actual, present := responseDetail(httpRes, detail.Key, detail.Value); !present {
+				c.log(detail.Key, fmt.Sprintf("expected %s: %s  actual %T: %s", detail.Value.Type(), detail, actual, "(missing)"))
+			} else {
+				ok = r.assertDetail(c, detail.Key, actual, detail.Value)
 			}
-			var actual interface{}
-			var present bool
-			if actual, present = responseDetails[detail.Key]; !present {
-				r.log(detail.Key, fmt.Sprintf("expected %s: %s  actual %T: %s", detail.Value.Type(), detail, actual, "(missing)"))
-				r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
-				return
+			c.assertions = append(c.assertions, Assertion{Line: line.Number, Text: strings.TrimSpace(string(line.Bytes)), Passed: ok})
+			if !ok {
+				r.fail(c, line.Number, detail.Key+" doesn't match")
+				return false
 			}
-			if !r.assertDetail(detail.Key, actual, detail.Value) {
-				r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
-				return
+		}
+	}
+	for _, block := range req.ExpectedData {
+		src, err := interpolate(block.Lines.String(), tplData, funcs)
+		if err != nil {
+			r.fail(c, block.Number, block.Key+":", err)
+			return false
+		}
+		parseDataOnce.Do(func() {
+			data, errData = r.responseData(httpRes.Header, actualBody)
+		})
+		ok := r.assertDataBlock(c, data, errData, block, []byte(src))
+		c.assertions = append(c.assertions, Assertion{Line: block.Number, Text: "```json " + block.Key, Passed: ok})
+		if !ok {
+			r.fail(c, block.Number, block.Key+" doesn't match")
+			return false
+		}
+	}
+	if line, err := r.captureResponse(c, httpRes, actualBody, func() (interface{}, error) {
+		parseDataOnce.Do(func() {
+			data, errData = r.responseData(httpRes.Header, actualBody)
+		})
+		return data, errData
+	}); err != nil {
+		r.fail(c, line, directiveCapture+":", err)
+		return false
+	}
+	if len(req.ResponseScript) > 0 {
+		parseDataOnce.Do(func() {
+			data, errData = r.responseData(httpRes.Header, actualBody)
+		})
+		extra := map[string]interface{}{"Status": httpRes.StatusCode, "Body": string(actualBody)}
+		if errData == nil {
+			extra["Data"] = data
+		}
+		if line, err := r.runScript(c, req.ResponseScript, extra); err != nil {
+			r.fail(c, line, "script:", err)
+			return false
+		}
+	}
+	if reason := expectFail(req); reason != "" {
+		r.reportFailure(c, req.Number, "passed, but expected to fail:", reason)
+		return false
+	}
+	r.report(c, Passed, req.Number)
+	return true
+}
+
+// isAbsoluteURL gets whether the path of a request is an absolute
+// URL, which is requested as it is rather than from the root URL.
+func isAbsoluteURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// context gets the context of the current run.
+func (r *Runner) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+func (r *Runner) fail(c *call, line int, args ...interface{}) {
+	helperOf(c.t).Helper()
+	if c.polling {
+		return
+	}
+	if quarantine(c.group, c.req) != "" {
+		// reported, but doesn't fail the run
+		r.report(c, Quarantined, line, args...)
+		return
+	}
+	if reason := expectFail(c.req); reason != "" {
+		r.report(c, Passed, line, append([]interface{}{"failed as expected (" + reason + "):"}, args...)...)
+		return
+	}
+	r.reportFailure(c, line, args...)
+}
+
+// reportFailure reports that the call failed, and fails the test.
+func (r *Runner) reportFailure(c *call, line int, args ...interface{}) {
+	helperOf(c.t).Helper()
+	result := r.report(c, Failed, line, args...)
+	format, fargs := "%s:%d: %s %s - %s", []interface{}{result.Filename, result.Line, result.Method, result.Path, result.Message}
+	if ids := result.ids(); ids != "" {
+		format, fargs = format+" %s", append(fargs, ids)
+	}
+	// subtests fail on their own, without stopping the test
+	if r.policy() != FailFast && !c.subtest {
+		if !errorf(c.t, format, fargs...) {
+			// fail at the end instead
+			r.failed = true
+		}
+		return
+	}
+	// the Reporter has already logged the failure
+	fatalf(c.t, nil, format, fargs...)
+}
+
+func (r *Runner) skip(c *call, line int, args ...interface{}) {
+	r.report(c, Skipped, line, args...)
+	if skipper, ok := c.t.(interface {
+		SkipNow()
+	}); ok && c.subtest {
+		skipper.SkipNow()
+	}
+}
+
+// report reports the outcome of the call to the Reporter, and
+// gets the Result.
+func (r *Runner) report(c *call, outcome Outcome, line int, args ...interface{}) *Result {
+	result := &Result{
+		Filename:    c.group.Filename,
+		Group:       string(c.group.Title),
+		Method:      string(c.req.Method),
+		Path:        string(c.req.Path),
+		Name:        c.name,
+		Outcome:     outcome,
+		Line:        line,
+		RequestLine: c.req.Number,
+		Details:     c.details,
+		Quarantine:  quarantine(c.group, c.req),
+
+		Start:         c.start,
+		URL:           c.url,
+		RequestHeader: c.requestHeader,
+		RequestBody:   c.requestBody,
+		RequestID:     c.requestID,
+		TraceID:       c.traceID,
+		Unreachable:   c.unreachable,
+		ResponseBody:  c.responseBody,
+		Assertions:    c.assertions,
+	}
+	if c.response != nil {
+		result.Proto = c.response.Proto
+		result.StatusCode = c.response.StatusCode
+		result.Header = c.response.Header
+	}
+	if len(args) > 0 {
+		result.Message = strings.TrimSpace(sprint(args...))
+	}
+	if len(c.secrets) > 0 {
+		result.Message = c.redact(result.Message)
+		result.URL = c.redact(result.URL)
+		result.RequestBody = c.redact(result.RequestBody)
+		result.ResponseBody = []byte(c.redact(string(result.ResponseBody)))
+		details := make([]string, len(result.Details))
+		for i, detail := range result.Details {
+			details[i] = c.redact(detail)
+		}
+		result.Details = details
+		result.RequestHeader = c.redactHeader(result.RequestHeader)
+		result.Header = c.redactHeader(result.Header)
+	}
+	if !c.start.IsZero() {
+		result.Duration = time.Since(c.start)
+	}
+	r.results = append(r.results, result)
+	if r.Reporter != nil {
+		r.Reporter.Report(result)
+	}
+	if r.Transcript != nil {
+		writeTranscript(r.Transcript, result)
+	}
+	return result
+}
+
+func (r *Runner) assertBody(c *call, actual, expected []byte) bool {
+	if bytes.Equal(actual, expected) {
+		return true
+	}
+	if boundary, ok := multipartBoundary(c.response.Header.Get("Content-Type")); ok {
+		return r.assertParts(c, actual, expected, boundary)
+	}
+	if !r.ExactBody {
+		kind := contentKind(c.response.Header.Get("Content-Type"), actual)
+		if bodiesMatch(kind, actual, expected) {
+			return true
+		}
+	}
+	if binary(expected) || binary(actual) {
+		c.log(describeBinaryDiff(expected, actual))
+		return false
+	}
+	// describe differences in the data when both are JSON
+	var expectedData, actualData interface{}
+	if json.Unmarshal(expected, &expectedData) == nil && json.Unmarshal(actual, &actualData) == nil {
+		if diffs := diffData("Data", expectedData, actualData); len(diffs) > 0 {
+			c.log("body data differs:")
+			for _, diff := range diffs {
+				c.log(indent, diff)
 			}
+			return false
 		}
 	}
+	c.log("body differs (-expected +actual):")
+	c.log("```diff")
+	c.details = append(c.details, diffLines(strings.Split(string(expected), "\n"), strings.Split(string(actual), "\n"))...)
+	c.log("```")
+	return false
+}
+
+// absent is the value of headers that must not be present,
+// as in "* X-Debug: (absent)".
+const absent = "(absent)"
+
+// isAbsent gets whether the value asserts that the header is
+// not present.
+func isAbsent(v *parse.Value) bool {
+	return v.Data == absent
+}
+
+// null and missing are the values of Data fields that must be
+// present with a null value, and that must not be present at all,
+// as in "* Data.deleted_at: (null)" and "* Data.password: (missing)".
+const (
+	null    = "(null)"
+	missing = "(missing)"
+)
 
+// isNull gets whether the value asserts that the field is present
+// and null.
+func isNull(v *parse.Value) bool {
+	return v.Data == null
 }
 
-func (r *Runner) fail(group *parse.Group, req *parse.Request, line int, args ...interface{}) {
-	logargs := []interface{}{"--- FAIL:", string(req.Method), string(req.Path), "\n", group.Filename + ":" + strconv.FormatInt(int64(line), 10)}
-	r.log(append(logargs, args...)...)
-	r.t.FailNow()
+// isMissing gets whether the value asserts that the field is not
+// present.
+func isMissing(v *parse.Value) bool {
+	return v.Data == missing
 }
 
-func (r *Runner) assertBody(actual, expected []byte) bool {
-	if !reflect.DeepEqual(actual, expected) {
-		r.log("body expected:")
-		r.log("```")
-		r.log(string(expected))
-		r.log("```")
-		r.log("actual:")
-		r.log("```")
-		r.log(string(actual))
-		r.log("```")
+func (r *Runner) assertAbsent(c *call, key string, res *http.Response) bool {
+	if actual, present := responseDetail(res, key, nil); present {
+		c.log(key, fmt.Sprintf("expected %s  actual %T: %s", absent, actual, parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))))
 		return false
 	}
 	return true
 }
 
-func (r *Runner) assertDetail(key string, actual interface{}, expected *parse.Value) bool {
-	if actual != expected.Data {
+func (r *Runner) assertDetail(c *call, key string, actual interface{}, expected *parse.Value) bool {
+	if value, ok := ignoreCase(expected); ok {
+		if !strings.EqualFold(fmt.Sprintf("%v", actual), value) {
+			c.log(key, fmt.Sprintf("expected %s  actual %T: %s", expected.Data, actual, parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))))
+			return false
+		}
+		return true
+	}
+	if m, err := parseMatcher(expected); err != nil {
+		c.log(key, err)
+		return false
+	} else if m != nil {
+		return r.assertMatcher(c, key, actual, m)
+	}
+	if !expected.Equal(actual) {
 		actualVal := parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))
-		r.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualVal))
+		if list, ok := actual.([]interface{}); ok {
+			actualVal = &parse.Value{Data: list}
+		}
+		c.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualVal))
 		return false
 	}
+	capture(c.vars, expected, actual)
 	return true
 }
 
-func (r *Runner) assertData(data interface{}, errData error, key string, expected *parse.Value) bool {
+func (r *Runner) assertData(c *call, data interface{}, errData error, key string, expected *parse.Value) bool {
 	if errData != nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: failed to parse body: %s", expected.Type(), expected, errData))
+		c.log(key, fmt.Sprintf("expected %s: %s  actual: failed to parse body: %s", expected.Type(), expected, errData))
 		return false
 	}
 	if data == nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: no data", expected.Type(), expected))
+		c.log(key, fmt.Sprintf("expected %s: %s  actual: no data", expected.Type(), expected))
 		return false
 	}
 	actual, ok := m.GetOK(map[string]interface{}{"Data": data}, key)
+	switch {
+	case isMissing(expected) && ok:
+		c.log(key, fmt.Sprintf("expected %s  actual %T: %s", missing, actual, parse.Value{Data: actual}))
+		return false
+	case isMissing(expected):
+		return true
+	case isNull(expected) && !ok:
+		c.log(key, fmt.Sprintf("expected %s  actual: %s", null, missing))
+		return false
+	case isNull(expected) && actual != nil:
+		c.log(key, fmt.Sprintf("expected %s  actual %T: %s", null, actual, parse.Value{Data: actual}))
+		return false
+	case isNull(expected):
+		return true
+	}
 	if !ok && expected.Data != nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: (missing)", expected.Type(), expected))
+		c.log(key, fmt.Sprintf("expected %s: %s  actual: (missing)", expected.Type(), expected))
 		return false
 	}
 	if !ok && expected.Data == nil {
 		return true
 	}
+	if m, err := parseMatcher(expected); err != nil {
+		c.log(key, err)
+		return false
+	} else if m != nil {
+		return r.assertMatcher(c, key, actual, m)
+	}
 	if !expected.Equal(actual) {
 		actualVal := parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))
-		r.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualVal))
+		c.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualVal))
 		return false
 	}
+	capture(c.vars, expected, actual)
 	return true
 }
@@ -1,11 +1,19 @@
 package runner_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cheekybits/is"
 	"github.com/matryer/silk/parse"
@@ -41,6 +49,46 @@ func TestRunFileSuccess(t *testing.T) {
 	is.False(subT.Failed())
 }
 
+func TestCustomMethods(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	var methods []string
+	echo := testutil.EchoHandler()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		echo.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/methods.silk.md")
+	is.False(subT.Failed())
+	is.Equal(methods, []string{"PROPFIND", "PURGE"})
+}
+
+func TestRepeatedParams(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/params.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestRawQuery(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, r.URL.RawQuery)
+	}))
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.Vars["id"] = 7
+	r.RunFile("../testfiles/success/rawquery.silk.md")
+	is.False(subT.Failed())
+}
+
 func TestData(t *testing.T) {
 	is := is.New(t)
 	subT := &testT{}
@@ -51,6 +99,266 @@ func TestData(t *testing.T) {
 	is.False(subT.Failed())
 }
 
+func TestCapture(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/capture.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestTemplate(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.Vars["userID"] = 42
+	r.Vars["token"] = "abc123"
+	r.Vars["limit"] = 10
+	r.Vars["name"] = "Silk"
+	r.RunFile("../testfiles/success/template.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestTemplateMissingVar(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/success/template.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "invalid request:"))
+}
+
+func TestDataFile(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/datafile.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestDataFileFailure(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/datafile.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.False(strings.Contains(logstr, "(row 1)"))
+	is.True(strings.Contains(logstr, "--- FAIL: GET /users/{id} (row 2)"))
+	is.True(strings.Contains(logstr, "--- FAIL: GET /users/{id} (row 3)"))
+}
+
+func TestDataFileCapture(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/datafilecapture.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestDataFileEmpty(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/datafile.empty.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "DataFile: no rows"))
+}
+
+func TestRepeat(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/repeat.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestRepeatCapture(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/repeatcapture.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestRepeatFailure(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/repeat.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "--- FAIL: GET /pages/{iteration} (iteration 3)"))
+	is.False(strings.Contains(logstr, "(iteration 4)"))
+}
+
+func TestSkipIf(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_SKIP", "true")
+	defer os.Unsetenv("SILK_SKIP")
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/success/skip.silk.md")
+	is.False(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "--- SKIP: GET /skipped"))
+	is.True(strings.Contains(logstr, `skip.silk.md:5 - ${SILK_SKIP} == "true"`))
+	is.False(strings.Contains(logstr, "/not-skipped"))
+}
+
+func TestTags(t *testing.T) {
+	is := is.New(t)
+	for tags, expected := range map[string][]string{
+		"":             {"GET /users", "GET /users/1", "DELETE /users/1", "GET /comments"},
+		"smoke":        {"GET /users", "GET /users/1", "DELETE /users/1", "GET /comments"},
+		"smoke !slow":  {"GET /users"},
+		"comments":     {"GET /comments"},
+		"!destructive": {"GET /users", "GET /users/1", "GET /comments"},
+	} {
+		subT := &testT{}
+		s := httptest.NewServer(testutil.EchoDataHandler())
+		r := runner.New(subT, s.URL)
+		var requests []string
+		r.RoundTripper = runner.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.Method+" "+req.URL.Path)
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		r.Tags = tags
+		r.RunFile("../testfiles/success/tags.silk.md")
+		s.Close()
+		is.False(subT.Failed())
+		is.Equal(requests, expected)
+	}
+}
+
+func TestSetupTeardown(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var requests []string
+	r.RoundTripper = runner.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	r.RunFile("../testfiles/success/setup.silk.md")
+	is.False(subT.Failed())
+	is.Equal(requests, []string{"POST /users", "GET /users/1", "PUT /users/1", "DELETE /users/1"})
+}
+
+func TestSetupTeardownFailure(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.Log = func(s string) {}
+	var requests []string
+	r.RoundTripper = runner.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	r.RunFile("../testfiles/failure/setup.failure.silk.md")
+	is.True(subT.Failed())
+	is.Equal(requests, []string{
+		"POST /users", "DELETE /users/1",
+		"GET /comments/1", "GET /comments/2", "DELETE /comments",
+	})
+}
+
+func TestHooks(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.BeforeRequest = func(req *http.Request) error {
+		req.Header.Set("X-Signature", "signed:"+req.URL.Path)
+		return nil
+	}
+	var bodies []string
+	r.AfterResponse = func(res *http.Response) error {
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, string(b))
+		return nil
+	}
+	r.RunFile("../testfiles/success/hooks.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(bodies), 1)
+	is.True(strings.Contains(bodies[0], `"X-Signature":"signed:/hooks"`))
+}
+
+func TestHooksError(t *testing.T) {
+	is := is.New(t)
+	for _, hooks := range []func(r *runner.Runner){
+		func(r *runner.Runner) {
+			r.BeforeRequest = func(*http.Request) error { return errors.New("bad request") }
+		},
+		func(r *runner.Runner) {
+			r.AfterResponse = func(*http.Response) error { return errors.New("bad response") }
+		},
+	} {
+		subT := &testT{}
+		s := httptest.NewServer(testutil.EchoDataHandler())
+		r := runner.New(subT, s.URL)
+		var logs []string
+		r.Log = func(s string) {
+			logs = append(logs, s)
+		}
+		hooks(r)
+		r.RunFile("../testfiles/success/hooks.silk.md")
+		s.Close()
+		is.True(subT.Failed())
+		is.True(strings.Contains(strings.Join(logs, "\n"), "bad "))
+	}
+}
+
 func TestRunFileSuccessNoBody(t *testing.T) {
 	is := is.New(t)
 	subT := &testT{}
@@ -76,12 +384,10 @@ func TestFailureWrongBody(t *testing.T) {
 	r.RunGroup(g...)
 	is.True(subT.Failed())
 	logstr := strings.Join(logs, "\n")
-	is.True(strings.Contains(logstr, "body expected:"))
-	is.True(strings.Contains(logstr, "GET /echo"))
-	is.True(strings.Contains(logstr, "Hello silky."))
-	is.True(strings.Contains(logstr, "actual:"))
-	is.True(strings.Contains(logstr, "GET /echo"))
-	is.True(strings.Contains(logstr, "Hello silk."))
+	is.True(strings.Contains(logstr, "body differs (-expected +actual):"))
+	is.True(strings.Contains(logstr, "\n GET /echo\n"))
+	is.True(strings.Contains(logstr, "\n-Hello silky.\n"))
+	is.True(strings.Contains(logstr, "\n+Hello silk.\n"))
 	is.True(strings.Contains(logstr, "--- FAIL: GET /echo"))
 	is.True(strings.Contains(logstr, "../testfiles/failure/echo.failure.wrongbody.silk.md:14 - body doesn't match"))
 }
@@ -117,23 +423,1172 @@ func TestGlob(t *testing.T) {
 	is.True(subT.Failed())
 }
 
-type testT struct {
-	log    []string
-	failed bool
+func TestHeader(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.Header = http.Header{"X-Another-Header": {"default"}, "X-Default": {"default"}}
+	var headers []http.Header
+	r.RoundTripper = runner.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		headers = append(headers, req.Header)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/echo.nobody.success.silk.md")
+	is.Equal(len(headers), 1)
+	is.Equal(headers[0].Get("X-Default"), "default")
+	// headers in the request replace the defaults
+	is.Equal(headers[0]["X-Another-Header"], []string{"value"})
 }
 
-func (t *testT) FailNow() {
-	t.failed = true
+func TestExpectedBodyFile(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/golden/golden.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/golden/wrong.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `-* User-Agent: "silk"`))
+	is.True(strings.Contains(logstr, "wrong.silk.md:7 - body doesn't match"))
 }
 
-func (t *testT) Failed() bool {
-	return t.failed
+func TestBinaryBody(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/binary/binary.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/binary/wrong.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "binary body differs at byte 7: expected 9 bytes, actual 8 bytes"))
 }
 
-func (t *testT) LogString() string {
-	return strings.Join(t.log, "\n")
+func TestBodySize(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/bodysize.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/echo.failure.bodysize.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "BodySize expected < 10  actual: "))
 }
 
-func (t *testT) Log(args ...interface{}) {
-	t.log = append(t.log, fmt.Sprint(args...))
+func TestAbsent(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/absent.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/echo.failure.absent.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `Server expected (absent)  actual string: "EchoHandler"`))
+}
+
+func TestRepeatedHeaders(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "a=1")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/headers.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/headers.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `Vary expected []interface {}: ["Accept"]  actual []interface {}: ["Accept","Origin"]`))
+}
+
+func TestHeaderCase(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/headercase.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/echo.failure.headercase.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `Server expected (ignorecase) "echo"  actual string: "EchoHandler"`))
+}
+
+func TestCookies(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/", Secure: true, HttpOnly: true, SameSite: http.SameSiteStrictMode, MaxAge: 3600})
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Expires: time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)})
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/cookies.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/cookies.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Cookie.theme.HttpOnly expected bool: true  actual bool: false"))
+}
+
+func TestContentTypeBody(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"tags":["a","b"],"name":"Silk","id":1.0}`)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, "Hello world\nagain\n")
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/content/content.silk.md")
+	is.False(subT.Failed())
+
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.ExactBody = true
+	r.RunFile("../testfiles/content/content.silk.md")
+	is.True(subT.Failed())
+}
+
+func TestApprox(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/approx.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/approx.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.total expected 10.5 ± 0.001  actual float64: 10.504"))
+}
+
+func TestMatchers(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/matchers.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/matchers.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.id expected uuid()  actual string: \"not-a-uuid\""))
+}
+
+func TestTime(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Vars["now"] = time.Now().Format(time.RFC3339)
+	r.RunFile("../testfiles/success/time.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/time.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `Data.body.created_at expected (time) after 2024-01-01  actual string: "2020-01-01T00:00:00Z"`))
+}
+
+func TestDuration(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/duration.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/duration.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.ttl expected <= 30m  actual float64: 7200"))
+}
+
+func TestNullMissing(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/null.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.ContinueOnFailure = true
+	r.RunFile("../testfiles/failure/null.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.deleted_at expected (null)  actual: (missing)"))
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.password expected (missing)  actual <nil>: null"))
+}
+
+func TestDataBlock(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/datablock/datablock.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/datablock/wrong.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Data.body.user.id: expected 2  actual 1"))
+}
+
+func TestSaveBody(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "silk")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	src, err := ioutil.ReadFile("../testfiles/save/save.silk.md")
+	is.NoErr(err)
+	filename := filepath.Join(dir, "save", "save.silk.md")
+	is.NoErr(os.MkdirAll(filepath.Dir(filename), 0755))
+	is.NoErr(ioutil.WriteFile(filename, src, 0644))
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Vars["id"] = 1
+	r.RunFile(filename)
+	is.False(subT.Failed())
+	body, err := ioutil.ReadFile(filepath.Join(dir, "save", "out", "user-1.txt"))
+	is.NoErr(err)
+	is.True(strings.HasPrefix(string(body), "GET /users/1\n"))
+
+	// the file must be inside the directory of the silk file
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.Vars["id"] = "/../../../escaped"
+	r.RunFile(filename)
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "SaveBody: path must be inside the directory of the silk file"))
+	_, err = os.Stat(filepath.Join(dir, "escaped.txt"))
+	is.True(os.IsNotExist(err))
+}
+
+func TestFrontMatter(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("X-Tenant") != "acme" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": 7}`)
+	}))
+	defer s.Close()
+	groups, err := parse.ParseFile("../testfiles/frontmatter/frontmatter.silk.md")
+	is.NoErr(err)
+	// the file's url takes precedence over the Runner's
+	groups[0].FrontMatter.URL = s.URL
+	subT := &testT{}
+	r := runner.New(subT, "http://localhost:1")
+	r.Log = func(string) {}
+	// the file's headers take precedence over the Runner's
+	r.Header = http.Header{"X-Tenant": {"initech"}}
+	r.RunGroup(groups...)
+	is.False(subT.Failed())
+	is.Equal(paths, []string{"/invoices/7", "/invoices/7/lines"})
+
+	paths = nil
+	r = runner.New(subT, "http://localhost:1")
+	r.Log = func(string) {}
+	r.Tags = "!billing"
+	r.RunGroup(groups...)
+	is.False(subT.Failed())
+	is.Equal(len(paths), 0)
+}
+
+func TestSnippets(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-Client")+" "+string(b))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/orders/drafts" {
+			w.WriteHeader(http.StatusAccepted)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write(b)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/snippets/snippets.silk.md")
+	is.False(subT.Failed())
+	// the snippet isn't run itself
+	is.Equal(requests, []string{
+		`/orders silk {"sku": "abc", "qty": 2}`,
+		`/orders tests {"sku": "xyz", "qty": 5}`,
+		`/orders/drafts silk {"sku": "abc", "qty": 1}`,
+	})
+
+	// the arguments only belong to the request
+	requests = nil
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	groups, err := parse.Parse("snippets.silk.md", strings.NewReader("# Orders\n\n## POST /orders\n\n* Snippet: createOrder\n\n```json\n{\"sku\": \"{{.sku}}\"}\n```\n\n## POST /orders\n\n* Use: createOrder sku=abc\n\n## GET /orders/{sku}\n\n## GET /orders\n\n* Use: listOrders\n"))
+	is.NoErr(err)
+	r.RunGroup(groups...)
+	is.True(subT.Failed())
+	is.Equal(len(requests), 2)
+	is.Equal(requests[1], "/orders/{sku}  ")
+	is.Equal(r.Summary().Results[2].Message, "Use: unknown snippet listOrders")
+}
+
+func TestTimeout(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	r.RunFile("../testfiles/success/bodysize.silk.md")
+	is.True(subT.Failed())
+	is.True(time.Since(start) < time.Second)
+	is.True(r.Summary().Results[0].Unreachable)
+}
+
+func TestRateLimit(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	start := time.Now()
+	r.RunFile("../testfiles/ratelimit/ratelimit.silk.md")
+	is.False(subT.Failed())
+	// three requests at 20 per second are 50ms apart
+	is.True(time.Since(start) >= 100*time.Millisecond)
+
+	data := httptest.NewServer(testutil.EchoDataHandler())
+	defer data.Close()
+	r = runner.New(subT, data.URL)
+	r.Log = func(string) {}
+	r.RateLimit = 100
+	start = time.Now()
+	r.RunFile("../testfiles/success/repeat.silk.md")
+	is.False(subT.Failed())
+	is.True(time.Since(start) >= 20*time.Millisecond)
+
+	// a RateLimit header is sent, and asserted, like any other
+	header := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("RateLimit") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("RateLimit", "limit=10")
+	}))
+	defer header.Close()
+	r = runner.New(subT, header.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/ratelimit/header.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestPollUntil(t *testing.T) {
+	is := is.New(t)
+	var n int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n++
+		state := "pending"
+		if n >= 3 {
+			state = "done"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"state": %q}`, state)
+	}))
+	defer s.Close()
+	var logs []string
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/poll/poll.silk.md")
+	is.False(subT.Failed())
+	is.Equal(n, 3)
+	is.False(strings.Contains(strings.Join(logs, "\n"), "doesn't match"))
+
+	// only the last attempt fails
+	n = 0
+	r.RunFile("../testfiles/poll/poll.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(n > 3)
+	is.Equal(strings.Count(strings.Join(logs, "\n"), "Data.state doesn't match"), 1)
+}
+
+func TestAfter(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	status := http.StatusCreated
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			w.WriteHeader(status)
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/after/after.silk.md")
+	is.False(subT.Failed())
+	is.Equal(requests, []string{"POST /users", "GET /users"})
+
+	// groups are skipped when a group they run after fails
+	requests = nil
+	status = http.StatusInternalServerError
+	r = runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.ContinueOnFailure = true
+	r.RunFile("../testfiles/after/after.silk.md")
+	is.True(subT.Failed())
+	is.Equal(requests, []string{"POST /users"})
+	summary := r.Summary()
+	is.Equal(summary.Failed, 1)
+	is.Equal(summary.Skipped, 1)
+}
+
+type testT struct {
+	log    []string
+	failed bool
+}
+
+func (t *testT) FailNow() {
+	t.failed = true
+}
+
+func (t *testT) Failed() bool {
+	return t.failed
+}
+
+func (t *testT) LogString() string {
+	return strings.Join(t.log, "\n")
+}
+
+func (t *testT) Log(args ...interface{}) {
+	t.log = append(t.log, fmt.Sprint(args...))
+}
+
+func TestSession(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s3cr3t"})
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"token":"abc123"}`)
+			return
+		}
+		if cookie, err := r.Cookie("sid"); err != nil || cookie.Value != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/account" && r.Header.Get("Authorization") != "Bearer abc123" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer s.Close()
+	run := func(session runner.Session, filenames ...string) *runner.Summary {
+		subT := &testT{}
+		r := runner.New(subT, s.URL)
+		r.Log = func(string) {}
+		r.ContinueOnFailure = true
+		r.Session = session
+		r.RunFile(filenames...)
+		return r.Summary()
+	}
+	// groups don't share state by default
+	summary := run(runner.SessionGroup, "../testfiles/session/login.silk.md")
+	is.Equal(summary.Failed, 1)
+
+	summary = run(runner.SessionFile, "../testfiles/session/login.silk.md", "../testfiles/session/account.silk.md")
+	is.Equal(summary.Passed, 2)
+	is.Equal(summary.Failed, 1)
+
+	summary = run(runner.SessionRun, "../testfiles/session/login.silk.md", "../testfiles/session/account.silk.md")
+	is.Equal(summary.Passed, 3)
+	is.Equal(summary.Failed, 0)
+}
+
+func TestVariableScopes(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Name", strings.TrimPrefix(r.URL.Path, "/names/"))
+		w.Header().Set("X-Next", "group")
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	var logs []string
+	r.Verbose = func(args ...interface{}) {
+		logs = append(logs, fmt.Sprintln(args...))
+	}
+	r.Vars["name"] = "global"
+	r.Vars["tenant"] = "acme"
+	r.RunFile("../testfiles/scope/scope.silk.md")
+	is.False(subT.Failed())
+	log := strings.Join(logs, "")
+	is.True(strings.Contains(log, "name = global (global)"))
+	is.True(strings.Contains(log, "name = group (group)"))
+	is.True(strings.Contains(log, "name = Mat (request)"))
+	is.True(strings.Contains(log, "tenant = acme (global)"))
+}
+
+func TestDryRun(t *testing.T) {
+	is := is.New(t)
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.DryRun = true
+	r.RunFile("../testfiles/dryrun/dryrun.silk.md")
+	is.False(subT.Failed())
+	is.Equal(requests, 0)
+	log := strings.Join(logs, "\n")
+	is.True(strings.Contains(log, "POST "+s.URL+"/users\nContent-Type: application/json\n\n{\"name\": \"Mat\"}"))
+	is.True(strings.Contains(log, "GET "+s.URL+"/users/{userID}?fields=name"))
+	summary := r.Summary()
+	is.Equal(summary.Skipped, 2)
+}
+
+func TestOnFailure(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+	for _, test := range []struct {
+		policy   runner.Policy
+		requests []string
+	}{
+		{policy: runner.FinishGroup, requests: []string{"/fail", "/first"}},
+		{policy: runner.RunAll, requests: []string{"/fail", "/first", "/second"}},
+	} {
+		requests = nil
+		subT := &testT{}
+		r := runner.New(subT, s.URL)
+		r.Log = func(string) {}
+		r.OnFailure = test.policy
+		r.RunFile("../testfiles/policy/policy.silk.md")
+		is.True(subT.Failed())
+		is.Equal(requests, test.requests)
+	}
+}
+
+func TestRunPattern(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+	}))
+	defer s.Close()
+	for _, test := range []struct {
+		run      string
+		requests string
+	}{
+		{run: "", requests: "/fail /first /second"},
+		{run: "^First$", requests: "/fail /first"},
+		{run: "GET /(first|second)", requests: "/first /second"},
+		{run: "nothing", requests: ""},
+	} {
+		requests = nil
+		subT := &testT{}
+		r := runner.New(subT, s.URL)
+		r.Log = func(string) {}
+		r.Run = test.run
+		r.RunFile("../testfiles/policy/policy.silk.md")
+		is.Equal(strings.Join(requests, " "), test.requests)
+	}
+
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Run = "("
+	r.RunFile("../testfiles/policy/policy.silk.md")
+	is.True(subT.Failed())
+}
+
+func TestShuffle(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+	}))
+	defer s.Close()
+	run := func(seed int64) string {
+		requests = nil
+		subT := &testT{}
+		r := runner.New(subT, s.URL)
+		var logs []string
+		r.Log = func(s string) {
+			logs = append(logs, s)
+		}
+		r.Shuffle = true
+		r.Seed = seed
+		r.RunFile("../testfiles/shuffle/shuffle.silk.md")
+		is.False(subT.Failed())
+		is.Equal(logs[0], fmt.Sprintf("shuffle: seed %d", seed))
+		order := strings.Join(requests, " ")
+		// groups still run after their dependencies
+		is.True(strings.Index(order, "/5") < strings.Index(order, "/6"))
+		return order
+	}
+	is.Equal(run(1), run(1))
+	orders := make(map[string]bool)
+	for seed := int64(1); seed <= 10; seed++ {
+		orders[run(seed)] = true
+	}
+	is.True(len(orders) > 1)
+}
+
+func TestQuarantine(t *testing.T) {
+	is := is.New(t)
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/quarantine/quarantine.silk.md")
+	is.False(subT.Failed())
+	is.Equal(requests, []string{"/broken", "/working"})
+	summary := r.Summary()
+	is.Equal(summary.Passed, 1)
+	is.Equal(summary.Failed, 0)
+	is.Equal(summary.Quarantined, 1)
+	is.Equal(summary.Results[0].Outcome, runner.Quarantined)
+	is.Equal(summary.Results[0].Quarantine, "TICKET-123")
+	log := strings.Join(logs, "\n")
+	is.True(strings.Contains(log, "PASS: 1 file(s), 2 request(s): 1 passed, 0 failed, 0 skipped, 1 quarantined"))
+	is.True(strings.Contains(log, "quarantined: GET /broken (TICKET-123) ../testfiles/quarantine/quarantine.silk.md:9"))
+}
+
+func TestExpectFail(t *testing.T) {
+	is := is.New(t)
+	total := "10"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"total":`+total+`}`)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/expectfail/expectfail.silk.md")
+	is.False(subT.Failed())
+	summary := r.Summary()
+	is.Equal(summary.Passed, 2)
+	is.True(strings.HasPrefix(summary.Results[0].Message, "failed as expected (TICKET-456 totals are rounded):"))
+
+	// the bug is fixed
+	total = "10.5"
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/expectfail/expectfail.silk.md")
+	is.True(subT.Failed())
+	summary = r.Summary()
+	is.Equal(summary.Failed, 1)
+	is.Equal(summary.Results[0].Message, "passed, but expected to fail: TICKET-456 totals are rounded")
+}
+
+func TestScript(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/script/script.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/script/bad.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "script: next:"))
+}
+
+func TestExec(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/exec/exec.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/exec/fail.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "exit code 2"))
+}
+
+func TestSOAP(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("SOAPAction") == `"urn:GetUser"` && r.Header.Get("Content-Type") == "text/xml; charset=utf-8":
+		case r.Header.Get("SOAPAction") == "" && r.Header.Get("Content-Type") == `application/soap+xml; charset=utf-8; action="urn:GetUser"`:
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", strings.Split(r.Header.Get("Content-Type"), "; action")[0])
+		io.WriteString(w, `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetUserResponse>
+      <user status="active">
+        <id>7</id>
+        <name>Mat</name>
+        <tags><tag>user</tag><tag>admin</tag></tags>
+      </user>
+    </GetUserResponse>
+  </soap:Body>
+</soap:Envelope>`)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/soap/soap.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(body), "secrets") {
+			io.WriteString(w, `{"data": null, "errors": [{"message": "not allowed", "extensions": {"code": "FORBIDDEN"}}]}`)
+			return
+		}
+		io.WriteString(w, `{"data": {"user": {"name": "Mat"}}, "errors": []}`)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/graphql/graphql.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/graphql/graphql.failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `GraphQLErrors expected empty  actual: [{"extensions":{"code":"FORBIDDEN"},"message":"not allowed"}]`))
+}
+
+func TestMultipart(t *testing.T) {
+	is := is.New(t)
+	name := "Mat"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mw := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+		p, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+		io.WriteString(p, `{"name": "`+name+`", "id": 1}`)
+		p, _ = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
+		io.WriteString(p, "done")
+		mw.Close()
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/multipart/multipart.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	name = "David"
+	r.RunFile("../testfiles/multipart/multipart.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "part 1: body differs"))
+}
+
+func TestStream(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			// ends before the promised length
+			w.Header().Set("Content-Length", "100")
+			io.WriteString(w, "abc")
+			return
+		}
+		for i := 0; i < 3; i++ {
+			time.Sleep(20 * time.Millisecond)
+			io.WriteString(w, "abc")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/stream/stream.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/stream/slow.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "FirstByte expected < 1ms  actual string:"))
+
+	logs = nil
+	r.RunFile("../testfiles/stream/broken.silk.md")
+	is.True(strings.Contains(strings.Join(logs, "\n"), "the body didn't end cleanly, after 1 chunk(s): unexpected EOF"))
+}
+
+func TestCaptureDirective(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/users":
+			w.Header().Set("Location", "/users/42")
+			if r.URL.Query().Get("absolute") == "true" {
+				w.Header().Set("Location", "http://"+r.Host+"/users/42")
+			}
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id": 42}`)
+		case r.Method == "GET" && r.URL.Path == "/users/42":
+			io.WriteString(w, `{"id": 42, "status": 201}`)
+		case r.Method == "DELETE" && r.URL.Path == "/users/42":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/capture/capture.silk.md")
+	is.False(subT.Failed())
+	r.RunFile("../testfiles/capture/absolute.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/capture/missing.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Capture: X-Missing is (missing)"))
+}
+
+func TestHead(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "13")
+		io.WriteString(w, "Hello, world!")
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/head.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	g, err := parse.Parse("head.silk.md", strings.NewReader("# HEAD\n## HEAD /things\n===\n```\nHello, world!\n```\n"))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "HEAD responses have no body"))
+}
+
+func TestCORS(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") == "https://app.example.com" {
+			w.Header().Set("Access-Control-Allow-Origin", "https://app.example.com")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, PUT")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Token")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/cors/cors.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/cors/failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "CORS expected allowed  actual: DELETE is not in Access-Control-Allow-Methods, x-other is not in Access-Control-Allow-Headers"))
+}
+
+func TestExpectContinue(t *testing.T) {
+	is := is.New(t)
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Expect") != "100-continue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("size") == "large" {
+			// reject without reading the body, so no 100 Continue
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/continue/continue.silk.md")
+	is.False(subT.Failed())
+	is.Equal(bodies, []string{"a small file"})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	is := is.New(t)
+	var keys []string
+	var orders int
+	responses := make(map[string]string)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		keys = append(keys, key)
+		w.Header().Set("Content-Type", "application/json")
+		if res, ok := responses[key]; ok && r.URL.Query().Get("ignore") != "true" {
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, res)
+			return
+		}
+		orders++
+		res := fmt.Sprintf(`{"id": %d}`, orders)
+		responses[key] = res
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, res)
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/idempotency/idempotency.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(keys), 5)
+	is.Equal(keys[0], keys[1])
+	is.True(keys[0] != keys[2])
+	is.Equal(len(keys[0]), 36)
+	is.Equal(keys[3], "order-abc")
+	is.Equal(keys[4], "order-abc")
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/idempotency/failure.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Idempotent expected body (as the first response)"))
+}
+
+func TestRequestID(t *testing.T) {
+	is := is.New(t)
+	var ids []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.RequestIDHeader = "X-Request-ID"
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/requestid/requestid.silk.md")
+	is.True(subT.Failed())
+	is.Equal(len(ids), 3)
+	is.Equal(len(ids[0]), 36)
+	is.Equal(ids[1], "my-request")
+	is.True(ids[0] != ids[2])
+	is.True(strings.Contains(strings.Join(logs, "\n"), "Status doesn't match (request ID "+ids[2]+")"))
+}
+
+func TestTrace(t *testing.T) {
+	is := is.New(t)
+	var traceparents []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+	}))
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Trace = true
+	var results []*runner.Result
+	r.Reporter = runner.ReporterFunc(func(result *runner.Result) {
+		results = append(results, result)
+	})
+	r.RunFile("../testfiles/requestid/trace.silk.md")
+	is.True(subT.Failed())
+	is.Equal(len(traceparents), 2)
+	is.Equal(traceparents[0], "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	is.Equal(results[0].TraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+	parts := strings.Split(traceparents[1], "-")
+	is.Equal(len(parts), 4)
+	is.Equal(len(parts[1]), 32)
+	is.Equal(len(parts[2]), 16)
+	is.Equal(results[1].TraceID, parts[1])
 }
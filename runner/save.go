@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var errSaveBodyPath = errors.New("path must be inside the directory of the silk file")
+
+// saveBody writes the response body to the file given by the
+// SaveBody directive, if there is one. The filename may use
+// variables, and is relative to the silk file, which it must not
+// leave, since captured values could otherwise write anywhere.
+// Gets the line of the directive, and any error.
+func (r *Runner) saveBody(c *call, body []byte, data map[string]interface{}, funcs template.FuncMap) (int, error) {
+	line := directive(c.req.ExpectedDetails, directiveSaveBody)
+	if line == nil {
+		line = directive(c.req.Details, directiveSaveBody)
+	}
+	if line == nil {
+		return 0, nil
+	}
+	filename, err := interpolate(fmt.Sprintf("%v", line.Detail().Value.Data), data, funcs)
+	if err != nil {
+		return line.Number, err
+	}
+	filename = filepath.Clean(filename)
+	if filepath.IsAbs(filename) || filename == ".." || strings.HasPrefix(filename, ".."+string(filepath.Separator)) {
+		return line.Number, errSaveBodyPath
+	}
+	filename = filepath.Join(filepath.Dir(c.group.Filename), filename)
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return line.Number, err
+	}
+	if err := ioutil.WriteFile(filename, body, 0644); err != nil {
+		return line.Number, err
+	}
+	r.Verbose("saved body to", filename)
+	return line.Number, nil
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Variables are resolved from the narrowest scope that has them:
+//
+//	request       the row of a data file, and the iteration
+//	group         values captured by earlier requests in the group
+//	file          values captured in the file, with SessionFile
+//	run           values captured in the run, with SessionRun
+//	front matter  the vars in the front matter of the file
+//	global        the Runner's Vars, from config files and vars files
+//
+// so a data file column shadows a captured value with the same
+// name, which shadows a front matter or global variable. Captured values are
+// stored in the group, file or run scope depending on the Session,
+// never the request scope, so they are available to later
+// requests.
+const (
+	scopeRequest     = "request"
+	scopeFrontMatter = "front matter"
+	scopeGlobal      = "global"
+)
+
+// capturedScope gets the name of the scope captured values are
+// stored in.
+func (r *Runner) capturedScope() string {
+	switch r.Session {
+	case SessionFile:
+		return "file"
+	case SessionRun:
+		return "run"
+	}
+	return "group"
+}
+
+// resolve gets the value of the variable available to the call, and
+// the name of the scope it comes from.
+func (r *Runner) resolve(c *call, name string) (interface{}, string, bool) {
+	if v, ok := c.local[name]; ok {
+		return v, scopeRequest, true
+	}
+	if v, ok := c.vars[name]; ok {
+		return v, r.capturedScope(), true
+	}
+	if fm := c.group.FrontMatter; fm != nil {
+		if v, ok := fm.Vars[name]; ok {
+			return v, scopeFrontMatter, true
+		}
+	}
+	if v, ok := r.Vars[name]; ok {
+		return v, scopeGlobal, true
+	}
+	return nil, "", false
+}
+
+// templateVarRegex matches the variables used by a template action,
+// like userID in {{.userID}} and users in {{.users.admin.id}}.
+var templateVarRegex = regexp.MustCompile(`(?:^|[^\w.\])])\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// actionRegex matches template actions.
+var actionRegex = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// varNames gets the sorted names of the variables referred to by s,
+// either by {name} or in a template.
+func varNames(s string) []string {
+	seen := make(map[string]bool)
+	for _, loc := range varRefRegex.FindAllStringSubmatchIndex(s, -1) {
+		if loc[0] > 0 && (s[loc[0]-1] == '$' || s[loc[0]-1] == '{') {
+			// environment variables, and {{end}} and the like
+			continue
+		}
+		seen[s[loc[2]:loc[3]]] = true
+	}
+	for _, action := range actionRegex.FindAllString(s, -1) {
+		for _, m := range templateVarRegex.FindAllStringSubmatch(action, -1) {
+			seen[m[1]] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// logVars logs the value of each variable the request uses, and the
+// scope it was resolved from, to explain which value was used when
+// variables with the same name are in different scopes.
+func (r *Runner) logVars(c *call) {
+	req := c.req
+	texts := []string{string(req.Path), req.Body.String(), req.ExpectedBody.String()}
+	for _, lines := range []parse.Lines{req.Details, req.Params, req.ExpectedDetails} {
+		for _, line := range lines {
+			texts = append(texts, string(line.Bytes))
+		}
+	}
+	for _, name := range varNames(strings.Join(texts, "\n")) {
+		v, scope, ok := r.resolve(c, name)
+		if !ok {
+			continue
+		}
+		r.Verbose(indent, c.redact(fmt.Sprintf("%s = %v (%s)", name, v, scope)))
+	}
+}
@@ -0,0 +1,15 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestVarNames(t *testing.T) {
+	is := is.New(t)
+	is.Equal(varNames("/users/{id}/orders/{{.orderID}}"), []string{"id", "orderID"})
+	is.Equal(varNames(`{{.users.admin.id}} {{index .items 0}} {{if eq .a "x"}}{{end}}`), []string{"a", "items", "users"})
+	// environment variables and plain text aren't variables
+	is.Equal(varNames("${HOME} .name {}"), []string{})
+}
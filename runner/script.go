@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/matryer/silk/parse"
+)
+
+// statementRegex matches the statements of scripts, like
+// signature = hmacSHA256 "key" .Body
+var statementRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.+)$`)
+
+// statement sets the variable Name to the value of Expr, which is a
+// template pipeline.
+type statement struct {
+	Line int
+	Name string
+	Expr string
+}
+
+// parseScript gets the statements of a script. Blank lines, and
+// lines starting with #, are ignored.
+func parseScript(lines parse.Lines) ([]statement, error) {
+	var statements []statement
+	for _, line := range lines {
+		s := strings.TrimSpace(string(line.Bytes))
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		match := statementRegex.FindStringSubmatch(s)
+		if match == nil {
+			return nil, &parse.ErrLine{N: line.Number, Err: fmt.Errorf("bad statement %q (expected name = expression)", s)}
+		}
+		statements = append(statements, statement{Line: line.Number, Name: match[1], Expr: strings.TrimSpace(match[2])})
+	}
+	return statements, nil
+}
+
+// runScript runs the statements of the script, setting variables
+// in the call's vars, and gets the line of the statement that
+// fails. The expressions have the variables, and the extra data
+// (like .Body), available to them.
+func (r *Runner) runScript(c *call, lines parse.Lines, extra map[string]interface{}) (int, error) {
+	statements, err := parseScript(lines)
+	if err != nil {
+		errLine := err.(*parse.ErrLine)
+		return errLine.N, errLine.Err
+	}
+	funcs := r.funcs()
+	for _, s := range statements {
+		expr, err := r.revealSecrets(c, s.Expr)
+		if err != nil {
+			return s.Line, err
+		}
+		val, err := eval(expr, copyVars(r.templateData(c), extra), funcs)
+		if err != nil {
+			return s.Line, fmt.Errorf("%s: %v", s.Name, err)
+		}
+		c.vars[s.Name] = val
+		r.Verbose(indent, c.redact(fmt.Sprintf("%s = %v", s.Name, val)))
+	}
+	return 0, nil
+}
+
+// eval gets the value of the template pipeline, keeping its type
+// rather than formatting it.
+func eval(expr string, data map[string]interface{}, funcs template.FuncMap) (interface{}, error) {
+	var result interface{}
+	funcs = copyFuncs(funcs)
+	funcs["scriptResult"] = func(v interface{}) string {
+		result = v
+		return ""
+	}
+	tpl, err := template.New("script").Option("missingkey=error").Funcs(funcs).Parse("{{scriptResult (" + expr + ")}}")
+	if err != nil {
+		return nil, err
+	}
+	if err := tpl.Execute(&bytes.Buffer{}, data); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func copyFuncs(funcs template.FuncMap) template.FuncMap {
+	c := make(template.FuncMap, len(funcs)+1)
+	for k, v := range funcs {
+		c[k] = v
+	}
+	return c
+}
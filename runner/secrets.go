@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SecretProvider gets the values of secrets, which are referred
+// to in silk files as ${secret:name}.
+type SecretProvider interface {
+	Secret(name string) (string, error)
+}
+
+// SecretProviderFunc is a function that is a SecretProvider.
+type SecretProviderFunc func(name string) (string, error)
+
+// Secret calls fn(name).
+func (fn SecretProviderFunc) Secret(name string) (string, error) {
+	return fn(name)
+}
+
+// secretRefRegex matches ${secret:name} references.
+var secretRefRegex = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// redacted replaces the values of secrets in logs and reports.
+const redacted = "[REDACTED]"
+
+var errNoSecretProvider = errors.New("no SecretProvider for ${secret:...} references")
+
+// revealSecrets replaces ${secret:name} references in s with the
+// values of the secrets, and remembers them so they can be
+// redacted from what the call logs and reports.
+func (r *Runner) revealSecrets(c *call, s string) (string, error) {
+	if !strings.Contains(s, "${secret:") {
+		return s, nil
+	}
+	if r.Secrets == nil {
+		return "", errNoSecretProvider
+	}
+	var err error
+	s = secretRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		var value string
+		value, err = r.Secrets.Secret(secretRefRegex.FindStringSubmatch(ref)[1])
+		c.addRedacted(value)
+		return value
+	})
+	return s, err
+}
+
+// redact replaces the values of secrets revealed by the call in s.
+func (c *call) redact(s string) string {
+	for _, secret := range c.secrets {
+		s = strings.Replace(s, secret, redacted, -1)
+	}
+	return s
+}
+
+// redactHeader gets a copy of the header with the secrets
+// redacted from its values.
+func (c *call) redactHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	header := make(http.Header, len(h))
+	for k, vs := range h {
+		for _, v := range vs {
+			header.Add(k, c.redact(v))
+		}
+	}
+	return header
+}
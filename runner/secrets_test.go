@@ -0,0 +1,70 @@
+package runner_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestSecrets(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.PrintCurl = true
+	var names []string
+	r.Secrets = runner.SecretProviderFunc(func(name string) (string, error) {
+		names = append(names, name)
+		return "s3cr3t", nil
+	})
+	r.RunFile("../testfiles/secrets/secrets.silk.md")
+	is.True(subT.Failed())
+	is.Equal(names, []string{"api_key", "api_key"})
+	logstr := strings.Join(logs, "\n")
+	is.False(strings.Contains(logstr, "s3cr3t"))
+	is.True(strings.Contains(logstr, "Bearer [REDACTED]"))
+	summary := r.Summary()
+	is.Equal(len(summary.Results), 1)
+	result := summary.Results[0]
+	is.False(strings.Contains(result.URL, "s3cr3t"))
+	is.False(strings.Contains(string(result.ResponseBody), "s3cr3t"))
+}
+
+func TestSecretsErrors(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/secrets/secrets.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "no SecretProvider"))
+
+	subT = &testT{}
+	r = runner.New(subT, s.URL)
+	logs = nil
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.Secrets = runner.SecretProviderFunc(func(name string) (string, error) {
+		return "", errors.New("no such secret")
+	})
+	r.RunFile("../testfiles/secrets/secrets.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "no such secret"))
+}
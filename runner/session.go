@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Session is how widely the state of a run, the values captured by
+// requests and the cookies set by responses, is shared between
+// groups.
+type Session int
+
+const (
+	// SessionGroup keeps captured values within their group, and
+	// doesn't keep cookies. It is the default.
+	SessionGroup Session = iota
+	// SessionFile shares captured values and cookies between the
+	// groups in each file, so a group can log in for the groups
+	// after it.
+	SessionFile
+	// SessionRun shares captured values and cookies between every
+	// group the Runner runs, whichever file it is in.
+	SessionRun
+)
+
+// ParseSession parses the name of a Session: group, file or run.
+func ParseSession(s string) (Session, error) {
+	switch s {
+	case "", "group":
+		return SessionGroup, nil
+	case "file":
+		return SessionFile, nil
+	case "run":
+		return SessionRun, nil
+	}
+	return SessionGroup, fmt.Errorf("unknown session %q (expected group, file or run)", s)
+}
+
+// session is the state shared between the groups of a file, or of
+// a run.
+type session struct {
+	vars map[string]interface{}
+	jar  http.CookieJar
+}
+
+// session gets the session of the group, or nil if its state isn't
+// shared.
+func (r *Runner) session(group *parse.Group) *session {
+	var key string
+	switch r.Session {
+	case SessionFile:
+		key = group.Filename
+	case SessionRun:
+	default:
+		return nil
+	}
+	if s, ok := r.sessions[key]; ok {
+		return s
+	}
+	// only fails with invalid options
+	jar, _ := cookiejar.New(nil)
+	s := &session{vars: make(map[string]interface{}), jar: jar}
+	if r.sessions == nil {
+		r.sessions = make(map[string]*session)
+	}
+	r.sessions[key] = s
+	return s
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// bodySizeKey is the assertion about the length of the response
+// body in bytes, like "* BodySize: < 10240".
+const bodySizeKey = "BodySize"
+
+// comparisonOps are the operators of comparisons, longest first.
+var comparisonOps = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+var errBadComparison = errors.New("expected a number, optionally after <, <=, >, >=, == or !=")
+
+// comparison is a comparison with a number, like "< 10240".
+type comparison struct {
+	op string
+	n  float64
+}
+
+// parseComparison parses a value like 1024 or "< 10240".
+func parseComparison(v *parse.Value) (*comparison, error) {
+	if n, ok := v.Data.(float64); ok {
+		return &comparison{op: "==", n: n}, nil
+	}
+	s := strings.TrimSpace(fmt.Sprintf("%v", v.Data))
+	op := "=="
+	for _, o := range comparisonOps {
+		if strings.HasPrefix(s, o) {
+			op, s = o, strings.TrimSpace(s[len(o):])
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, errBadComparison
+	}
+	return &comparison{op: op, n: n}, nil
+}
+
+// match gets whether n satisfies the comparison.
+func (c *comparison) match(n float64) bool {
+	switch c.op {
+	case "<":
+		return n < c.n
+	case "<=":
+		return n <= c.n
+	case ">":
+		return n > c.n
+	case ">=":
+		return n >= c.n
+	case "!=":
+		return n != c.n
+	}
+	return n == c.n
+}
+
+func (c *comparison) String() string {
+	return c.op + " " + strconv.FormatFloat(c.n, 'f', -1, 64)
+}
+
+var errHeadBody = errors.New("HEAD responses have no body, assert the Content-Length header or BodySize instead")
+
+// bodySize gets the size of the body of the response, or for HEAD
+// requests, which have no body, the size of the body that a GET
+// request would get, from the Content-Length header.
+func bodySize(req *http.Request, res *http.Response, body []byte) int {
+	if req.Method == http.MethodHead && res.ContentLength >= 0 {
+		return int(res.ContentLength)
+	}
+	return len(body)
+}
+
+// assertSize asserts the size of the body.
+func (r *Runner) assertSize(c *call, key string, size int, expected *parse.Value) bool {
+	cmp, err := parseComparison(expected)
+	if err != nil {
+		c.log(key, err)
+		return false
+	}
+	if !cmp.match(float64(size)) {
+		c.log(key, fmt.Sprintf("expected %s  actual: %d", cmp, size))
+		return false
+	}
+	return true
+}
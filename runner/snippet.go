@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+var errUse = errors.New("expected the name of a snippet, and any name=value arguments")
+
+// parseUse gets the name of the snippet, and the arguments, of the
+// value of a Use directive, like createOrder qty=2 sku=abc.
+func parseUse(s string) (string, map[string]interface{}, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", nil, errUse
+	}
+	args := make(map[string]interface{}, len(fields)-1)
+	for _, arg := range fields[1:] {
+		i := strings.Index(arg, "=")
+		if i < 1 {
+			return "", nil, errUse
+		}
+		args[arg[:i]] = arg[i+1:]
+	}
+	return fields[0], args, nil
+}
+
+func checkUse(v *parse.Value) error {
+	_, _, err := parseUse(fmt.Sprintf("%v", v.Data))
+	return err
+}
+
+// isSnippet gets whether the request is a snippet, which is only
+// run by requests that use it.
+func isSnippet(req *parse.Request) bool {
+	return directive(req.Details, directiveSnippet) != nil
+}
+
+// snippets gets the snippets of the groups, by filename and name.
+func snippets(groups []*parse.Group) map[string]map[string]*parse.Request {
+	found := make(map[string]map[string]*parse.Request)
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			line := directive(req.Details, directiveSnippet)
+			if line == nil {
+				continue
+			}
+			if found[group.Filename] == nil {
+				found[group.Filename] = make(map[string]*parse.Request)
+			}
+			found[group.Filename][strings.TrimSpace(fmt.Sprintf("%v", line.Detail().Value.Data))] = req
+		}
+	}
+	return found
+}
+
+// useSnippet runs the request of the call with the snippet its Use
+// directive names, and its arguments as variables.
+func (r *Runner) useSnippet(c *call, line *parse.Line) bool {
+	helperOf(c.t).Helper()
+	name, args, err := parseUse(fmt.Sprintf("%v", line.Detail().Value.Data))
+	if err != nil {
+		r.fail(c, line.Number, directiveUse+":", err)
+		return false
+	}
+	snippet, ok := r.snippets[c.group.Filename][name]
+	if !ok {
+		r.fail(c, line.Number, directiveUse+": unknown snippet", name)
+		return false
+	}
+	// captured values are available to later requests, but the
+	// arguments aren't
+	vars := copyVars(c.vars, args)
+	defer keepCaptures(c.vars, vars, args)
+	return r.runRequest(&call{t: c.t, subtest: c.subtest, group: c.group, req: withSnippet(snippet, c.req), vars: vars, local: copyVars(c.local, args), name: c.name})
+}
+
+// withSnippet makes a copy of the request with the details,
+// parameters, bodies and scripts of the snippet that the request
+// doesn't have itself.
+func withSnippet(snippet, req *parse.Request) *parse.Request {
+	merged := *req
+	merged.Details = mergeLines(snippet.Details, req.Details, directiveSnippet, directiveUse)
+	merged.Params = mergeLines(snippet.Params, req.Params)
+	merged.ExpectedDetails = mergeLines(snippet.ExpectedDetails, req.ExpectedDetails)
+	if len(req.Body) == 0 {
+		merged.Body, merged.BodyLang = snippet.Body, snippet.BodyLang
+	}
+	if len(req.Script) == 0 {
+		merged.Script = snippet.Script
+	}
+	if len(req.ExpectedBody) == 0 && len(req.ExpectedData) == 0 {
+		merged.ExpectedBody, merged.ExpectedBodyLang = snippet.ExpectedBody, snippet.ExpectedBodyLang
+		merged.ExpectedData = snippet.ExpectedData
+	}
+	if len(req.ResponseScript) == 0 {
+		merged.ResponseScript = snippet.ResponseScript
+	}
+	return &merged
+}
+
+// mergeLines gets the lines of the snippet whose keys aren't in the
+// lines of the request, followed by the lines of the request,
+// leaving out the omitted keys. Captures are kept from both.
+func mergeLines(snippet, req parse.Lines, omit ...string) parse.Lines {
+	omitted := make(map[string]bool, len(omit))
+	for _, key := range omit {
+		omitted[key] = true
+	}
+	set := make(map[string]bool, len(req))
+	for _, line := range req {
+		set[http.CanonicalHeaderKey(line.Detail().Key)] = true
+	}
+	var merged parse.Lines
+	for _, line := range snippet {
+		key := line.Detail().Key
+		if omitted[key] || set[http.CanonicalHeaderKey(key)] && key != directiveCapture {
+			continue
+		}
+		merged = append(merged, line)
+	}
+	for _, line := range req {
+		if !omitted[line.Detail().Key] {
+			merged = append(merged, line)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// langXML is the language of XML bodies, like SOAP envelopes.
+const langXML = "xml"
+
+// soapActionKey is the header of the action of SOAP 1.1 requests.
+const soapActionKey = "SOAPAction"
+
+// Namespaces of SOAP envelopes.
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// setXMLHeaders sets the Content-Type of an xml body, unless the
+// request has one, and sends its SOAPAction the way the version of
+// SOAP of the envelope expects: quoted in the SOAPAction header
+// for SOAP 1.1, and as the action parameter of the Content-Type for
+// SOAP 1.2.
+func setXMLHeaders(h http.Header, body string) {
+	if h.Get("Content-Type") != "" {
+		return
+	}
+	action := strings.Trim(h.Get(soapActionKey), `"`)
+	if soapNamespace(body) == soap12Namespace {
+		h.Del(soapActionKey)
+		contentType := "application/soap+xml; charset=utf-8"
+		if action != "" {
+			contentType += "; action=" + strconv.Quote(action)
+		}
+		h.Set("Content-Type", contentType)
+		return
+	}
+	if action != "" {
+		h.Set(soapActionKey, strconv.Quote(action))
+	}
+	h.Set("Content-Type", "text/xml; charset=utf-8")
+}
+
+// soapNamespace gets the namespace of the Envelope that is the root
+// element of the body, or "" if it isn't a SOAP envelope.
+func soapNamespace(body string) string {
+	d := xml.NewDecoder(strings.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if start.Name.Local != "Envelope" {
+				return ""
+			}
+			return start.Name.Space
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+// rowsKey is the key of the expected detail of SQL steps that
+// asserts the number of rows.
+const rowsKey = "Rows"
+
+// query runs the SQL in the body of an SQL step against the
+// database in its path, and asserts the number of Rows and their
+// Data, which is a list of rows with the columns as fields.
+// Values are put into the SQL as text by templates, so only use
+// values that are trusted.
+func (r *Runner) query(c *call) bool {
+	helperOf(c.t).Helper()
+	c.start = time.Now()
+	req := c.req
+	tplData := r.templateData(c)
+	funcs := r.funcs()
+	name, err := interpolate(string(req.Path), tplData, funcs)
+	if err != nil {
+		r.fail(c, req.Number, "invalid query:", err)
+		return false
+	}
+	query, err := interpolate(req.Body.String(), tplData, funcs)
+	if err == nil {
+		query, err = r.revealSecrets(c, query)
+	}
+	if err != nil {
+		r.fail(c, req.Number, "invalid query:", err)
+		return false
+	}
+	r.Verbose(string(req.Method), name)
+	r.Verbose(indent, c.redact(query))
+	r.logVars(c)
+	c.requestBody = query
+	if r.DryRun {
+		r.Log(c.redact(fmt.Sprintf("%s %s\n\n%s", req.Method, name, query)))
+		r.skip(c, req.Number, "dry run")
+		return true
+	}
+	db, ok := r.Databases[name]
+	if !ok {
+		r.fail(c, req.Number, fmt.Sprintf("unknown database %q", name))
+		return false
+	}
+	rows, err := db.QueryContext(r.context(), query)
+	if err != nil {
+		r.fail(c, req.Number, err)
+		return false
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		r.fail(c, req.Number, err)
+		return false
+	}
+	data := []interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			r.fail(c, req.Number, err)
+			return false
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = columnValue(values[i])
+		}
+		data = append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		r.fail(c, req.Number, err)
+		return false
+	}
+	r.Verbose(indent, rowsKey+":", len(data))
+	c.responseBody = []byte(jsonString(data))
+	values := map[string]interface{}{rowsKey: float64(len(data))}
+	if _, ok := r.assertStep(c, values, data, nil); !ok {
+		return false
+	}
+	if reason := expectFail(req); reason != "" {
+		r.reportFailure(c, req.Number, "passed, but expected to fail:", reason)
+		return false
+	}
+	r.report(c, Passed, req.Number)
+	return true
+}
+
+// columnValue gets the value of a column as it would be in JSON,
+// so it is asserted like the data of responses.
+func columnValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
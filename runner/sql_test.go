@@ -0,0 +1,86 @@
+package runner_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func init() {
+	sql.Register("silktest", usersDriver{})
+}
+
+// usersDriver is a database/sql driver with a table of users, whose
+// queries get the users named in them.
+type usersDriver struct{}
+
+func (usersDriver) Open(string) (driver.Conn, error) { return usersConn{}, nil }
+
+type usersConn struct{}
+
+func (usersConn) Prepare(query string) (driver.Stmt, error) { return usersStmt(query), nil }
+func (usersConn) Close() error                              { return nil }
+func (usersConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type usersStmt string
+
+func (usersStmt) Close() error                               { return nil }
+func (usersStmt) NumInput() int                              { return 0 }
+func (usersStmt) Exec([]driver.Value) (driver.Result, error) { return driver.ResultNoRows, nil }
+
+func (s usersStmt) Query([]driver.Value) (driver.Rows, error) {
+	rows := &usersRows{}
+	for i, name := range []string{"Mat", "David"} {
+		if strings.Contains(string(s), "'"+name+"'") {
+			rows.rows = append(rows.rows, []driver.Value{int64(i + 1), []byte(name)})
+		}
+	}
+	return rows, nil
+}
+
+type usersRows struct {
+	rows [][]driver.Value
+}
+
+func (*usersRows) Columns() []string { return []string{"id", "name"} }
+func (*usersRows) Close() error      { return nil }
+
+func (r *usersRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	db, err := sql.Open("silktest", "")
+	is.NoErr(err)
+	defer db.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Databases = map[string]*sql.DB{"main": db}
+	r.RunFile("../testfiles/sql/sql.silk.md")
+	is.False(subT.Failed())
+
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.Databases = nil
+	r.RunFile("../testfiles/sql/sql.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), `unknown database "main"`))
+}
@@ -0,0 +1,43 @@
+package runner
+
+import "net/http"
+
+// StepAction is what the Runner's Step decides to do with a request.
+type StepAction int
+
+const (
+	// StepRun makes the request.
+	StepRun StepAction = iota
+	// StepSkip skips the request, and reports it as skipped.
+	StepSkip
+	// StepAbort skips the request, and stops the run without
+	// making any more requests, including teardown requests.
+	StepAbort
+)
+
+// step asks the Step function what to do with the request, and
+// gets whether to make it.
+func (r *Runner) step(c *call, req *http.Request, body string) bool {
+	if r.aborted {
+		return false
+	}
+	if r.Step == nil {
+		return true
+	}
+	switch r.Step(c.redact(requestText(req, body))) {
+	case StepSkip:
+		r.skip(c, c.req.Number, "skipped by step")
+		return false
+	case StepAbort:
+		r.skip(c, c.req.Number, "aborted")
+		r.aborted = true
+		return false
+	}
+	return true
+}
+
+// stopped is whether the run has been cancelled or aborted, so no
+// more requests should be made.
+func (r *Runner) stopped() bool {
+	return r.context().Err() != nil || r.aborted
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Keys of the assertions about how the response body arrived, like
+// "* FirstByte: < 500ms" and "* Chunks: >= 3".
+const (
+	firstByteKey = "FirstByte"
+	chunksKey    = "Chunks"
+)
+
+// stream is how a response body arrived.
+type stream struct {
+	// firstByte is the time from sending the request to the first
+	// byte of the body.
+	firstByte time.Duration
+	// chunks is the number of pieces the body arrived in.
+	chunks int
+}
+
+// readStream reads the body as it arrives, rather than all at once,
+// so that how it arrived can be asserted. Pieces that arrive
+// together are read together, so they are one chunk.
+func readStream(body io.Reader, sent time.Time) ([]byte, stream, error) {
+	var buf bytes.Buffer
+	var s stream
+	b := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(b)
+		if n > 0 {
+			if s.chunks == 0 {
+				s.firstByte = time.Since(sent)
+			}
+			s.chunks++
+			buf.Write(b[:n])
+		}
+		if err == io.EOF {
+			return buf.Bytes(), s, nil
+		}
+		if err != nil {
+			return buf.Bytes(), s, err
+		}
+	}
+}
+
+// assertStream asserts the FirstByte or Chunks of the stream.
+func (r *Runner) assertStream(c *call, key string, s stream, expected *parse.Value) bool {
+	if key == chunksKey {
+		cmp, err := parseComparison(expected)
+		if err != nil {
+			c.log(key, err)
+			return false
+		}
+		if !cmp.match(float64(s.chunks)) {
+			c.log(key, fmt.Sprintf("expected %s  actual: %d", cmp, s.chunks))
+			return false
+		}
+		return true
+	}
+	m, err := parseDuration(fmt.Sprintf("%v", expected.Data))
+	if err != nil || m == nil {
+		c.log(key, "expected a comparison with a duration, like < 500ms")
+		return false
+	}
+	if s.chunks == 0 {
+		c.log(key, fmt.Sprintf("expected %s  actual: no body", m))
+		return false
+	}
+	return r.assertMatcher(c, key, s.firstByte.String(), m)
+}
@@ -0,0 +1,43 @@
+package runner_test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestSubtests(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	// run a failing test, capturing what it prints
+	stdout := os.Stdout
+	out, err := ioutil.TempFile("", "silk")
+	is.NoErr(err)
+	defer os.Remove(out.Name())
+	os.Stdout = out
+	var r *runner.Runner
+	ok := testing.RunTests(func(pat, str string) (bool, error) { return true, nil }, []testing.InternalTest{{
+		Name: "silk",
+		F: func(t *testing.T) {
+			r = runner.New(t, s.URL)
+			r.Log = func(string) {}
+			r.RunFile("../testfiles/failure/echo.failure.wrongheader.silk.md", "../testfiles/success/echo.success.silk.md")
+		},
+	}})
+	os.Stdout = stdout
+	out.Close()
+	is.False(ok)
+	b, err := ioutil.ReadFile(out.Name())
+	is.NoErr(err)
+	output := string(b)
+	is.True(strings.Contains(output, "--- FAIL: silk/Echo_server/GET_/echo"))
+	// the first failure stops the test
+	is.Equal(len(r.Summary().Results), 1)
+}
@@ -0,0 +1,64 @@
+package runner
+
+import "fmt"
+
+// errorer, fataler and helper are optional methods of T types,
+// which *testing.T has. When T has them, the runner uses them to
+// report failures.
+type errorer interface {
+	Errorf(format string, args ...interface{})
+}
+
+type fataler interface {
+	Fatalf(format string, args ...interface{})
+}
+
+type helper interface {
+	Helper()
+}
+
+// nopHelper is the helper of T types that aren't helpers.
+type nopHelper struct{}
+
+func (nopHelper) Helper() {}
+
+// helperOf gets t as a helper, so that every function between a
+// test and a failure can call
+//
+//	helperOf(t).Helper()
+//
+// to have failures reported at the line of the test that ran the
+// files, rather than inside the runner.
+func helperOf(t T) helper {
+	if h, ok := t.(helper); ok {
+		return h
+	}
+	return nopHelper{}
+}
+
+// errorf marks t as failed, and reports the message with Errorf
+// if t has it.
+// Gets false if t can't be marked as failed without stopping it.
+func errorf(t T, format string, args ...interface{}) bool {
+	helperOf(t).Helper()
+	e, ok := t.(errorer)
+	if !ok {
+		return false
+	}
+	e.Errorf(format, args...)
+	return true
+}
+
+// fatalf reports the message with Fatalf if t has it, or with
+// log (if it isn't nil), and stops t.
+func fatalf(t T, log func(string), format string, args ...interface{}) {
+	helperOf(t).Helper()
+	if f, ok := t.(fataler); ok {
+		f.Fatalf(format, args...)
+		return
+	}
+	if log != nil {
+		log(fmt.Sprintf(format, args...))
+	}
+	t.FailNow()
+}
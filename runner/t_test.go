@@ -0,0 +1,80 @@
+package runner_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+// richT is a runner.T with the optional Errorf, Fatalf and
+// Helper methods.
+type richT struct {
+	calls []string
+}
+
+func (t *richT) FailNow() {
+	t.calls = append(t.calls, "FailNow")
+	runtime.Goexit()
+}
+
+func (t *richT) Log(args ...interface{}) {
+	t.calls = append(t.calls, "Log: "+fmt.Sprint(args...))
+}
+
+func (t *richT) Errorf(format string, args ...interface{}) {
+	t.calls = append(t.calls, "Errorf: "+fmt.Sprintf(format, args...))
+}
+
+func (t *richT) Fatalf(format string, args ...interface{}) {
+	t.calls = append(t.calls, "Fatalf: "+fmt.Sprintf(format, args...))
+	runtime.Goexit()
+}
+
+func (t *richT) Helper() {}
+
+func TestRichT(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	run := func(continueOnFailure bool, files ...string) []string {
+		rt := &richT{}
+		r := runner.New(rt, s.URL)
+		r.Log = func(string) {}
+		r.ContinueOnFailure = continueOnFailure
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.RunFile(files...)
+		}()
+		<-done
+		return rt.calls
+	}
+	is.Equal(run(false, "../testfiles/failure/echo.failure.wrongheader.silk.md", "../testfiles/failure/echo.failure.wrongbody.silk.md"), []string{
+		"Fatalf: ../testfiles/failure/echo.failure.wrongheader.silk.md:22: GET /echo - Content-Type doesn't match",
+	})
+	is.Equal(run(true, "../testfiles/failure/echo.failure.wrongheader.silk.md", "../testfiles/failure/echo.failure.wrongbody.silk.md"), []string{
+		"Errorf: ../testfiles/failure/echo.failure.wrongheader.silk.md:22: GET /echo - Content-Type doesn't match",
+		"Errorf: ../testfiles/failure/echo.failure.wrongbody.silk.md:14: GET /echo - body doesn't match",
+	})
+	is.Equal(run(false, "../testfiles/missing.silk.md"), []string{
+		"Fatalf: open ../testfiles/missing.silk.md: no such file or directory",
+	})
+}
+
+func TestFailureLocation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of testdata in short mode")
+	}
+	is := is.New(t)
+	out, err := exec.Command("go", "test", "./testdata/location").CombinedOutput()
+	is.Err(err) // the test fails on purpose
+	// the failure is reported at the call to RunFile
+	is.True(strings.Contains(string(out), "location_test.go:18: ../../../testfiles/failure/echo.failure.wrongheader.silk.md:"))
+}
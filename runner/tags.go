@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// requestTags gets all tags for the request, including those
+// from its group and the front matter of its file.
+func requestTags(group *parse.Group, req *parse.Request) []string {
+	var tags []string
+	if group.FrontMatter != nil {
+		tags = append(tags, group.FrontMatter.Tags...)
+	}
+	tags = append(tags, group.Tags...)
+	tags = append(tags, tagsDirective(group.Details)...)
+	tags = append(tags, req.Tags...)
+	tags = append(tags, tagsDirective(req.Details)...)
+	return tags
+}
+
+// tagsDirective gets the tags from a Tags directive.
+func tagsDirective(lines parse.Lines) []string {
+	line := directive(lines, directiveTags)
+	if line == nil {
+		return nil
+	}
+	var tags []string
+	for _, tag := range splitTags(fmt.Sprintf("%v", line.Detail().Value.Data)) {
+		tags = append(tags, strings.TrimPrefix(tag, "@"))
+	}
+	return tags
+}
+
+func splitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// matchTags gets whether the tags are selected by the expression.
+// Tags in the expression are separated by spaces or commas, those
+// prefixed with ! are excluded.
+// If there are any included tags, at least one must be present.
+func matchTags(expr string, tags []string) bool {
+	has := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		has[tag] = true
+	}
+	included, includes := false, false
+	for _, term := range splitTags(expr) {
+		term = strings.TrimPrefix(term, "@")
+		if strings.HasPrefix(term, "!") {
+			if has[strings.TrimPrefix(term[1:], "@")] {
+				return false
+			}
+			continue
+		}
+		includes = true
+		if has[term] {
+			included = true
+		}
+	}
+	return !includes || included
+}
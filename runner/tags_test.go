@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestMatchTags(t *testing.T) {
+	is := is.New(t)
+	tags := []string{"smoke", "slow"}
+	is.True(matchTags("", tags))
+	is.True(matchTags("smoke", tags))
+	is.True(matchTags("@smoke", tags))
+	is.True(matchTags("fast, smoke", tags))
+	is.True(matchTags("!destructive", tags))
+	is.False(matchTags("fast", tags))
+	is.False(matchTags("!slow", tags))
+	is.False(matchTags("!@slow", tags))
+	is.False(matchTags("smoke !slow", tags))
+	is.False(matchTags("smoke", nil))
+	is.True(matchTags("!smoke", nil))
+}
@@ -0,0 +1,19 @@
+package location_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+// TestLocation fails on purpose, so that TestFailureLocation can
+// check where the failure is reported.
+func TestLocation(t *testing.T) {
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(t, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../../../testfiles/failure/echo.failure.wrongheader.silk.md")
+}
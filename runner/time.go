@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeRegex matches values that assert timestamps, like
+// (time) within 5s of now, or (time RFC1123) after 2024-01-01.
+var timeRegex = regexp.MustCompile(`^\(time(?:\s+([^)]+))?\)\s*(.*)$`)
+
+// timeLayouts are the names of layouts that may be used instead of
+// writing them out.
+var timeLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"HTTP":        http.TimeFormat,
+}
+
+// layoutUnix is the layout of timestamps in seconds since 1970.
+const layoutUnix = "unix"
+
+var errBadTimeRelation = errors.New(`expected "within 5s of now", "after ..." or "before ..."`)
+
+// timeMatcher matches timestamps in a layout, optionally in
+// relation to another time.
+type timeMatcher struct {
+	expected string
+	layout   string
+	// relation is "within", "after", "before" or "" if any
+	// valid timestamp matches.
+	relation string
+	within   time.Duration
+	// ref is the time to compare with, or zero for now.
+	ref time.Time
+}
+
+func parseTime(s string) (matcher, error) {
+	match := timeRegex.FindStringSubmatch(s)
+	if match == nil {
+		return nil, nil
+	}
+	m := &timeMatcher{expected: s, layout: time.RFC3339}
+	if layout := strings.TrimSpace(match[1]); layout != "" {
+		if unquoted, err := strconv.Unquote(layout); err == nil {
+			layout = unquoted
+		} else if named, ok := timeLayouts[layout]; ok {
+			layout = named
+		}
+		m.layout = layout
+	}
+	fields := strings.Fields(match[2])
+	switch {
+	case len(fields) == 0:
+	case len(fields) == 4 && fields[0] == "within" && fields[2] == "of" && fields[3] == "now":
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad duration %q", fields[1])
+		}
+		m.relation, m.within = "within", d
+	case len(fields) > 1 && (fields[0] == "after" || fields[0] == "before"):
+		m.relation = fields[0]
+		ref := strings.Join(fields[1:], " ")
+		if ref != "now" {
+			t, err := m.parseRef(ref)
+			if err != nil {
+				return nil, err
+			}
+			m.ref = t
+		}
+	default:
+		return nil, errBadTimeRelation
+	}
+	return m, nil
+}
+
+// parseRef parses the time to compare with, in the layout of the
+// matcher, or as RFC3339, or as a date.
+func (m *timeMatcher) parseRef(s string) (time.Time, error) {
+	for _, layout := range []string{m.layout, time.RFC3339, timeLayouts["DateOnly"]} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time %q", s)
+}
+
+// parse parses the actual value as a timestamp in the layout.
+func (m *timeMatcher) parse(actual interface{}) (time.Time, bool) {
+	if m.layout == layoutUnix {
+		n, ok := number(actual)
+		if !ok {
+			return time.Time{}, false
+		}
+		sec := int64(n)
+		return time.Unix(sec, int64((n-float64(sec))*1e9)), true
+	}
+	s, ok := actual.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(m.layout, s)
+	return t, err == nil
+}
+
+func (m *timeMatcher) match(actual interface{}) bool {
+	t, ok := m.parse(actual)
+	if !ok {
+		return false
+	}
+	ref := m.ref
+	if ref.IsZero() {
+		ref = time.Now()
+	}
+	switch m.relation {
+	case "within":
+		d := t.Sub(ref)
+		return d <= m.within && d >= -m.within
+	case "after":
+		return t.After(ref)
+	case "before":
+		return t.Before(ref)
+	}
+	return true
+}
+
+func (m *timeMatcher) example() interface{} {
+	t := m.ref
+	if t.IsZero() {
+		t = time.Now()
+	}
+	switch m.relation {
+	case "after":
+		t = t.Add(time.Hour)
+	case "before":
+		t = t.Add(-time.Hour)
+	}
+	if m.layout == layoutUnix {
+		return float64(t.Unix())
+	}
+	return t.Format(m.layout)
+}
+
+func (m *timeMatcher) String() string {
+	return m.expected
+}
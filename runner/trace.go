@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// traceparentHeader is the header of W3C Trace Context.
+const traceparentHeader = "traceparent"
+
+// traceparentRegex matches traceparent headers, capturing the
+// trace ID.
+var traceparentRegex = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// traceparent gets the trace ID of the traceparent header, starting
+// a new (sampled) trace, with the request as its root span, if there
+// isn't a traceparent header. Invalid headers are left alone, so
+// servers can be tested with them, and have no trace ID.
+func traceparent(h http.Header) string {
+	if s := h.Get(traceparentHeader); s != "" {
+		if match := traceparentRegex.FindStringSubmatch(s); match != nil {
+			return match[1]
+		}
+		return ""
+	}
+	var b [24]byte
+	rand.Read(b[:])
+	traceID, spanID := hex.EncodeToString(b[:16]), hex.EncodeToString(b[16:])
+	h.Set(traceparentHeader, "00-"+traceID+"-"+spanID+"-01")
+	return traceID
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewTranscriptReporter makes a Reporter that writes a transcript
+// of every request and response, with their headers and bodies, to
+// w, whatever the outcome and however verbose the run is.
+// Binary bodies are described rather than written.
+func NewTranscriptReporter(w io.Writer) Reporter {
+	return &transcriptReporter{w: w}
+}
+
+type transcriptReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (t *transcriptReporter) Report(result *Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	writeTranscript(t.w, result)
+}
+
+// writeTranscript writes the transcript of the request and
+// response of the result to out.
+func writeTranscript(out io.Writer, result *Result) {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	start := result.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+	fmt.Fprintf(w, "=== %s %s %s:%d %s %s", start.Format(time.RFC3339Nano), result.Outcome, result.Filename, result.Line, result.Method, result.Path)
+	if result.Name != "" {
+		fmt.Fprintf(w, " (%s)", result.Name)
+	}
+	fmt.Fprintf(w, " %s\n", result.Duration.Round(time.Millisecond))
+	if result.Message != "" {
+		fmt.Fprintf(w, "%s\n", result.Message)
+	}
+	for _, detail := range result.Details {
+		fmt.Fprintf(w, "%s%s\n", indent, detail)
+	}
+	if result.URL == "" {
+		// the request wasn't made
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintf(w, "> %s %s\n", result.Method, result.URL)
+	writeTranscriptHeader(w, "> ", result.RequestHeader)
+	writeTranscriptBody(w, "> ", []byte(result.RequestBody))
+	if result.StatusCode != 0 {
+		fmt.Fprintf(w, "< %d %s\n", result.StatusCode, http.StatusText(result.StatusCode))
+		writeTranscriptHeader(w, "< ", result.Header)
+		writeTranscriptBody(w, "< ", result.ResponseBody)
+	}
+	fmt.Fprintln(w)
+}
+
+func (t *transcriptReporter) Summarize(summary *Summary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "=== %s %d file(s), %d request(s): %d passed, %d failed, %d skipped (%s, seed %d)\n\n",
+		time.Now().Format(time.RFC3339Nano),
+		summary.Files, summary.Requests, summary.Passed, summary.Failed, summary.Skipped,
+		summary.Duration.Round(time.Millisecond), summary.Seed)
+}
+
+// writeTranscriptHeader writes the header, sorted by name.
+func writeTranscriptHeader(w io.Writer, prefix string, header http.Header) {
+	var keys []string
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, k, v)
+		}
+	}
+}
+
+// writeTranscriptBody writes the body after a blank line, if
+// there is one.
+func writeTranscriptBody(w io.Writer, prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\n", strings.TrimSpace(prefix))
+	if binary(body) {
+		fmt.Fprintf(w, "%s(%d bytes of binary data)\n", prefix, len(body))
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/parse"
+)
+
+// transportOptions are changes made to the Runner's *http.Transport
+// for a request.
+type transportOptions struct {
+	// proxy is the proxy URL, "direct" for no proxy, or empty to
+	// leave the proxy settings of the transport alone.
+	proxy string
+	// connectTo is the address to connect to instead of the host
+	// of the URL, or empty to use the URL.
+	connectTo string
+	// socket is the path of a unix domain socket to connect to
+	// instead of the host of the URL.
+	socket string
+	// expectContinue is how long to wait for a 100 Continue before
+	// sending the body, or zero to leave the transport alone.
+	expectContinue time.Duration
+}
+
+// transportKey identifies a customized transport.
+type transportKey struct {
+	base *http.Transport
+	transportOptions
+}
+
+var errNotTransport = errors.New("RoundTripper must be an *http.Transport")
+
+// roundTripper gets the RoundTripper for the call, wrapped in the
+// Middleware.
+// Customized transports are cloned from the RoundTripper, and
+// reused so connections are kept alive between requests.
+func (r *Runner) roundTripper(c *call) (http.RoundTripper, error) {
+	opts := r.transportOptions(c)
+	if opts == (transportOptions{}) {
+		return r.transport(r.RoundTripper), nil
+	}
+	base, ok := r.RoundTripper.(*http.Transport)
+	if !ok {
+		return nil, errNotTransport
+	}
+	key := transportKey{base: base, transportOptions: opts}
+	if transport, ok := r.transports[key]; ok {
+		return r.transport(transport), nil
+	}
+	transport := base.Clone()
+	if err := opts.apply(transport); err != nil {
+		return nil, err
+	}
+	if r.transports == nil {
+		r.transports = make(map[transportKey]*http.Transport)
+	}
+	r.transports[key] = transport
+	return r.transport(transport), nil
+}
+
+// transportOptions gets the transport options for the call from
+// the Runner, and the directives of the group and request.
+func (r *Runner) transportOptions(c *call) transportOptions {
+	opts := transportOptions{
+		proxy:          r.Proxy,
+		connectTo:      r.ConnectTo,
+		socket:         r.socket,
+		expectContinue: expectContinue(c),
+	}
+	for _, lines := range []parse.Lines{c.group.Details, c.req.Details} {
+		if line := directive(lines, directiveProxy); line != nil {
+			opts.proxy = fmt.Sprintf("%v", line.Detail().Value.Data)
+		}
+		if line := directive(lines, directiveConnectTo); line != nil {
+			opts.connectTo = fmt.Sprintf("%v", line.Detail().Value.Data)
+		}
+	}
+	return opts
+}
+
+func (opts transportOptions) apply(transport *http.Transport) error {
+	if opts.expectContinue > 0 {
+		transport.ExpectContinueTimeout = opts.expectContinue
+	}
+	switch opts.proxy {
+	case "":
+	case proxyDirect:
+		transport.Proxy = nil
+	default:
+		u, err := parseProxy(opts.proxy)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if opts.socket != "" {
+		transport.Proxy = nil
+		dialer := &net.Dialer{}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", opts.socket)
+		}
+		return nil
+	}
+	if opts.connectTo != "" {
+		if err := checkConnectTo(opts.connectTo); err != nil {
+			return err
+		}
+		// connect directly, since a proxy would make the connection
+		transport.Proxy = nil
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dial(ctx, network, connectAddr(addr, opts.connectTo))
+		}
+	}
+	return nil
+}
+
+// checkConnectTo checks an address to connect to, which is a host
+// with an optional port.
+func checkConnectTo(s string) error {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		// no port
+		host, port = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"), ""
+	}
+	if host == "" || strings.ContainsAny(host, "/ ") || (strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+		return fmt.Errorf("host %q: expected host or host:port", s)
+	}
+	if port != "" {
+		if _, err := strconv.Atoi(port); err != nil {
+			return fmt.Errorf("host %q: invalid port", s)
+		}
+	}
+	return nil
+}
+
+// connectAddr gets the address to connect to instead of addr.
+// If connectTo has no port, the port of addr is used.
+func connectAddr(addr, connectTo string) string {
+	if _, _, err := net.SplitHostPort(connectTo); err == nil {
+		return connectTo
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return connectTo
+	}
+	return net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(connectTo, "["), "]"), port)
+}
+
+const (
+	// unixScheme is the scheme of root URLs of unix domain sockets.
+	unixScheme = "unix://"
+	// unixRootURL is the root URL of requests made over a unix
+	// domain socket, which gives the Host header.
+	unixRootURL = "http://localhost"
+)
+
+// proxyDirect is the proxy setting for connecting directly.
+const proxyDirect = "direct"
+
+// parseProxy parses a proxy URL.
+func parseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy %q: scheme must be http, https or socks5", s)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q: missing host", s)
+	}
+	return u, nil
+}
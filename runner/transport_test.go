@@ -0,0 +1,125 @@
+package runner_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestProxy(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		testutil.EchoHandler().ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.Proxy = proxy.URL
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(proxied), 1)
+	is.Equal(proxied[0], s.URL+"/echo?param1=value1&param2=value2&param3=value3")
+
+	// the group connects directly
+	proxied = nil
+	r.RunFile("../testfiles/proxy/direct.silk.md")
+	is.False(subT.Failed())
+	is.Equal(len(proxied), 0)
+}
+
+func TestProxyNotTransport(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RoundTripper = runner.RoundTripperFunc(http.DefaultTransport.RoundTrip)
+	r.Proxy = "http://localhost:3128"
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	is.True(subT.Failed())
+	is.True(strings.Contains(strings.Join(logs, "\n"), "RoundTripper must be an *http.Transport"))
+}
+
+func TestConnectTo(t *testing.T) {
+	is := is.New(t)
+	var hosts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+	}))
+	defer s.Close()
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	is.NoErr(err)
+
+	subT := &testT{}
+	r := runner.New(subT, "http://silk.invalid")
+	r.Log = func(string) {}
+	r.ConnectTo = s.Listener.Addr().String()
+	r.RunFile("../testfiles/proxy/direct.silk.md")
+	is.False(subT.Failed())
+	is.Equal(hosts, []string{"silk.invalid"})
+
+	// the ConnectTo directive keeps the port of the URL
+	hosts = nil
+	r = runner.New(subT, "http://silk.invalid:"+port)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/host/host.silk.md")
+	is.False(subT.Failed())
+	is.Equal(hosts, []string{"silk.invalid:" + port})
+}
+
+func TestHostHeader(t *testing.T) {
+	is := is.New(t)
+	var hosts []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+	}))
+	defer s.Close()
+
+	subT := &testT{}
+	r := runner.New(subT, s.URL)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/host/vhost.silk.md")
+	is.False(subT.Failed())
+	is.Equal(hosts, []string{"api.example.com"})
+}
+
+func TestUnixSocket(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "app.sock")
+	l, err := net.Listen("unix", socket)
+	is.NoErr(err)
+	s := &httptest.Server{
+		Listener: l,
+		Config:   &http.Server{Handler: testutil.EchoHandler()},
+	}
+	s.Start()
+	defer s.Close()
+
+	subT := &testT{}
+	r := runner.New(subT, "unix://"+socket)
+	r.Log = func(string) {}
+	r.RunFile("../testfiles/success/echo.success.silk.md")
+	is.False(subT.Failed())
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/matryer/silk/parse"
+)
+
+// varRefRegex matches {name} variable references.
+var varRefRegex = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expand replaces {name} references in s with the value of the
+// variable. Unknown references, and ${NAME} environment variable
+// references, are left untouched.
+func expand(s string, vars map[string]interface{}) string {
+	return expandWith(s, vars, func(val interface{}) string {
+		return fmt.Sprintf("%v", val)
+	})
+}
+
+// expandWith replaces {name} references in s with the value of the
+// variable, formatted by format.
+func expandWith(s string, vars map[string]interface{}, format func(interface{}) string) string {
+	if len(vars) == 0 {
+		return s
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, loc := range varRefRegex.FindAllStringIndex(s, -1) {
+		if loc[0] > 0 && s[loc[0]-1] == '$' {
+			continue
+		}
+		val, ok := vars[s[loc[0]+1:loc[1]-1]]
+		if !ok {
+			continue
+		}
+		buf.WriteString(s[last:loc[0]])
+		buf.WriteString(format(val))
+		last = loc[1]
+	}
+	buf.WriteString(s[last:])
+	return buf.String()
+}
+
+// interpolate executes s as a text/template with the data and funcs,
+// and then expands any {name} references.
+func interpolate(s string, data map[string]interface{}, funcs template.FuncMap) (string, error) {
+	if strings.Contains(s, "{{") {
+		tpl, err := template.New("silk").Option("missingkey=error").Funcs(funcs).Parse(s)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		s = buf.String()
+	}
+	return expand(s, data), nil
+}
+
+// interpolatePath interpolates the path of a request. If the Runner
+// escapes path variables, {name} references are escaped as path
+// segments, so a value like a/b becomes a%2Fb, while {{.name}} is
+// inserted as it is.
+func (r *Runner) interpolatePath(s string, data map[string]interface{}, funcs template.FuncMap) (string, error) {
+	if !r.EscapePathVars {
+		return interpolate(s, data, funcs)
+	}
+	// expand first, so the values aren't escaped again by templates
+	s = expandWith(s, data, func(val interface{}) string {
+		return url.PathEscape(fmt.Sprintf("%v", val))
+	})
+	return interpolate(s, data, funcs)
+}
+
+// interpolateLine interpolates the line, and parses it again so
+// its detail reflects the interpolated value.
+func interpolateLine(line *parse.Line, data map[string]interface{}, funcs template.FuncMap) (*parse.Line, error) {
+	if !bytes.Contains(line.Bytes, []byte("{")) {
+		return line, nil
+	}
+	s, err := interpolate(string(line.Bytes), data, funcs)
+	if err != nil {
+		return line, err
+	}
+	parsed, err := parse.ParseLine(line.Number, []byte(s))
+	if err != nil {
+		return line, err
+	}
+	return parsed, nil
+}
+
+// templateData gets the data available to templates of the call,
+// made up of the Runner's Vars, the Vars of the front matter of its
+// file and its vars.
+func (r *Runner) templateData(c *call) map[string]interface{} {
+	data := make(map[string]interface{}, len(r.Vars)+len(c.vars))
+	for k, v := range r.Vars {
+		data[k] = v
+	}
+	if fm := c.group.FrontMatter; fm != nil {
+		for k, v := range fm.Vars {
+			data[k] = v
+		}
+	}
+	for k, v := range c.vars {
+		data[k] = v
+	}
+	return data
+}
+
+// copyVars makes a copy of vars, with the extra variables added.
+func copyVars(vars, extra map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(vars)+len(extra))
+	for k, v := range vars {
+		c[k] = v
+	}
+	for k, v := range extra {
+		c[k] = v
+	}
+	return c
+}
+
+// keepCaptures copies the variables in from (which was copied from
+// vars) back to vars, leaving out those in skip, which only belong
+// to the calls that used from.
+func keepCaptures(vars, from, skip map[string]interface{}) {
+	for k, v := range from {
+		if _, ok := skip[k]; !ok {
+			vars[k] = v
+		}
+	}
+}
+
+// capture stores any named capture groups from matching the
+// expected value against actual into vars.
+func capture(vars map[string]interface{}, expected *parse.Value, actual interface{}) {
+	for name, val := range expected.Captures(actual) {
+		vars[name] = val
+	}
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestInterpolate(t *testing.T) {
+	is := is.New(t)
+	data := map[string]interface{}{
+		"id":   123,
+		"name": "Silk",
+	}
+
+	s, err := interpolate("/people/{{.id}}/{name}/{unknown}", data, builtinFuncs)
+	is.NoErr(err)
+	is.Equal(s, "/people/123/Silk/{unknown}")
+
+	s, err = interpolate(`{"name": "{{.name}}"}`, data, builtinFuncs)
+	is.NoErr(err)
+	is.Equal(s, `{"name": "Silk"}`)
+
+	_, err = interpolate("/people/{{.missing}}", data, builtinFuncs)
+	is.Err(err)
+
+	_, err = interpolate("/people/{{.id", data, builtinFuncs)
+	is.Err(err)
+
+}
+
+func TestExpandIgnoresEnvRefs(t *testing.T) {
+	is := is.New(t)
+	data := map[string]interface{}{
+		"CI": "yes",
+	}
+	is.Equal(expand("${CI} {CI}", data), "${CI} yes")
+}
+
+func TestInterpolatePath(t *testing.T) {
+	is := is.New(t)
+	data := map[string]interface{}{
+		"name": "docs/read me.txt",
+	}
+	r := &Runner{}
+
+	s, err := r.interpolatePath("/files/{name}/{{.name}}", data, builtinFuncs)
+	is.NoErr(err)
+	is.Equal(s, "/files/docs/read me.txt/docs/read me.txt")
+
+	s, err = r.interpolatePath("/files/{{pathEscape .name}}?q={{queryEscape .name}}", data, builtinFuncs)
+	is.NoErr(err)
+	is.Equal(s, "/files/docs%2Fread%20me.txt?q=docs%2Fread+me.txt")
+
+	r.EscapePathVars = true
+	s, err = r.interpolatePath("/files/{name}/{{.name}}", data, builtinFuncs)
+	is.NoErr(err)
+	is.Equal(s, "/files/docs%2Fread%20me.txt/docs/read me.txt")
+}
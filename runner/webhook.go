@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookFailures is the most failures listed in the text of a
+// notification.
+const webhookFailures = 10
+
+// WebhookReporter is a Reporter that posts the summary of each run,
+// and the failing requests, to a webhook, for teams that run silk
+// files on a schedule as smoke tests.
+type WebhookReporter struct {
+	// OnlyFailures only posts the summaries of runs that fail.
+	OnlyFailures bool
+
+	url   string
+	slack bool
+	mu    sync.Mutex
+	err   error
+}
+
+// NewWebhookReporter makes a WebhookReporter that posts a JSON
+// object with the text of the summary, the counts, and the failures:
+//
+//	{
+//		"text": "FAIL: 3 file(s), 12 request(s): ...",
+//		"passed": 11, "failed": 1, "skipped": 0, "quarantined": 0,
+//		"duration": "1.2s", "seed": 1718034021553781000,
+//		"failures": [{"file": "users.silk.md", "line": 12, ...}]
+//	}
+//
+// Use MultiReporter to keep the default text output too.
+func NewWebhookReporter(url string) *WebhookReporter {
+	return &WebhookReporter{url: url}
+}
+
+// NewSlackReporter makes a WebhookReporter that posts the text of
+// the summary and the failures to a Slack incoming webhook.
+func NewSlackReporter(url string) *WebhookReporter {
+	return &WebhookReporter{url: url, slack: true}
+}
+
+// Report does nothing, since the summary has the results.
+func (w *WebhookReporter) Report(*Result) {}
+
+// Summarize posts the summary. Errors are kept for Err.
+func (w *WebhookReporter) Summarize(summary *Summary) {
+	if w.OnlyFailures && summary.Failed == 0 {
+		return
+	}
+	if err := w.post(summary); err != nil {
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+	}
+}
+
+// Err gets the error of the last post that failed, if any did.
+func (w *WebhookReporter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// webhookFailure is a failing request in a notification.
+type webhookFailure struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Name      string `json:"name,omitempty"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestID,omitempty"`
+	TraceID   string `json:"traceID,omitempty"`
+}
+
+func (w *WebhookReporter) post(summary *Summary) error {
+	var failures []webhookFailure
+	for _, result := range summary.Results {
+		if result.Outcome != Failed {
+			continue
+		}
+		failures = append(failures, webhookFailure{
+			File:      result.Filename,
+			Line:      result.Line,
+			Method:    result.Method,
+			Path:      result.Path,
+			Name:      result.Name,
+			Message:   result.Message,
+			RequestID: result.RequestID,
+			TraceID:   result.TraceID,
+		})
+	}
+	text := webhookText(summary)
+	var payload interface{} = map[string]interface{}{"text": text}
+	if !w.slack {
+		if failures == nil {
+			failures = []webhookFailure{}
+		}
+		payload = map[string]interface{}{
+			"text":        text,
+			"passed":      summary.Passed,
+			"failed":      summary.Failed,
+			"skipped":     summary.Skipped,
+			"quarantined": summary.Quarantined,
+			"duration":    summary.Duration.Round(time.Millisecond).String(),
+			"seed":        summary.Seed,
+			"failures":    failures,
+		}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	res, err := http.Post(w.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("webhook: %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// webhookText gets the text of a notification: the summary, like
+// the text Reporter's, and the first of the failures.
+func webhookText(summary *Summary) string {
+	outcome := Passed
+	if summary.Failed > 0 {
+		outcome = Failed
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s: %d file(s), %d request(s): %d passed, %d failed, %d skipped",
+		outcome, summary.Files, summary.Requests, summary.Passed, summary.Failed, summary.Skipped)
+	if summary.Quarantined > 0 {
+		fmt.Fprintf(&buf, ", %d quarantined", summary.Quarantined)
+	}
+	fmt.Fprintf(&buf, " (%s, seed %d)", summary.Duration.Round(time.Millisecond), summary.Seed)
+	listed := 0
+	for _, result := range summary.Results {
+		if result.Outcome != Failed {
+			continue
+		}
+		if listed == webhookFailures {
+			fmt.Fprintf(&buf, "\n...and %d more", summary.Failed-listed)
+			break
+		}
+		listed++
+		fmt.Fprintf(&buf, "\n%s %s", result.Method, result.Path)
+		if result.Name != "" {
+			buf.WriteString(" (" + result.Name + ")")
+		}
+		buf.WriteString(" - " + result.Filename + ":" + strconv.Itoa(result.Line) + " - " + result.Message)
+		if ids := result.ids(); ids != "" {
+			buf.WriteString(" " + ids)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,67 @@
+package runner_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/runner"
+)
+
+func TestWebhookReporter(t *testing.T) {
+	is := is.New(t)
+	var posts []map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "no such hook", http.StatusNotFound)
+			return
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		posts = append(posts, payload)
+	}))
+	defer s.Close()
+	failed := &runner.Summary{
+		Files:    1,
+		Requests: 2,
+		Passed:   1,
+		Failed:   1,
+		Duration: 1200 * time.Millisecond,
+		Seed:     42,
+		Results: []*runner.Result{
+			{Filename: "users.silk.md", Line: 3, Method: "GET", Path: "/users", Outcome: runner.Passed},
+			{Filename: "users.silk.md", Line: 12, Method: "GET", Path: "/users/1", Outcome: runner.Failed, Message: "Status doesn't match", RequestID: "3d0d67bb"},
+		},
+	}
+	passed := &runner.Summary{Files: 1, Requests: 1, Passed: 1}
+
+	reporter := runner.NewWebhookReporter(s.URL)
+	reporter.Summarize(failed)
+	is.NoErr(reporter.Err())
+	is.Equal(len(posts), 1)
+	is.Equal(posts[0]["text"], "FAIL: 1 file(s), 2 request(s): 1 passed, 1 failed, 0 skipped (1.2s, seed 42)\nGET /users/1 - users.silk.md:12 - Status doesn't match (request ID 3d0d67bb)")
+	is.Equal(posts[0]["failed"], 1.0)
+	is.Equal(posts[0]["duration"], "1.2s")
+	failures := posts[0]["failures"].([]interface{})
+	is.Equal(len(failures), 1)
+	is.Equal(failures[0].(map[string]interface{})["line"], 12.0)
+	is.Equal(failures[0].(map[string]interface{})["requestID"], "3d0d67bb")
+
+	slack := runner.NewSlackReporter(s.URL)
+	slack.OnlyFailures = true
+	slack.Summarize(passed)
+	is.Equal(len(posts), 1)
+	slack.Summarize(failed)
+	is.Equal(len(posts), 2)
+	is.Equal(len(posts[1]), 1)
+	is.True(strings.HasPrefix(posts[1]["text"].(string), "FAIL: 1 file(s)"))
+
+	broken := runner.NewWebhookReporter(s.URL + "/broken")
+	broken.Summarize(passed)
+	is.Err(broken.Err())
+	is.Equal(broken.Err().Error(), "webhook: 404 Not Found: no such hook")
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// xpathPrefix starts the keys of expected details that assert the
+// value at an XPath in an XML body, like XPath(//user/name).
+const xpathPrefix = "XPath("
+
+// xpathKey gets the XPath of an expected detail's key, and whether
+// it is an XPath assertion.
+func xpathKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, xpathPrefix) || !strings.HasSuffix(key, ")") {
+		return "", false
+	}
+	return key[len(xpathPrefix) : len(key)-1], true
+}
+
+// assertXPath asserts the value at the XPath in the body. Values are
+// compared like data in JSON bodies, so 7 matches <id>7</id>.
+func (r *Runner) assertXPath(c *call, key, path string, body []byte, expected *parse.Value) bool {
+	doc, err := parseXML(body)
+	if err != nil {
+		c.log(key, fmt.Sprintf("expected %s: %s  actual: failed to parse body: %s", expected.Type(), expected, err))
+		return false
+	}
+	values, err := xpath(doc, path)
+	if err != nil {
+		c.log(key, err)
+		return false
+	}
+	if isMissing(expected) {
+		if len(values) > 0 {
+			c.log(key, fmt.Sprintf("expected %s  actual: %s", missing, values[0]))
+			return false
+		}
+		return true
+	}
+	if len(values) == 0 {
+		c.log(key, fmt.Sprintf("expected %s: %s  actual: %s", expected.Type(), expected, missing))
+		return false
+	}
+	return r.assertDetail(c, key, parse.ParseValue([]byte(values[0])).Data, expected)
+}
+
+// xmlNode is an element of an XML document.
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// parseXML parses the body into a document, which is a node whose
+// child is the root element.
+func parseXML(body []byte) (*xmlNode, error) {
+	doc := &xmlNode{}
+	stack := []*xmlNode{doc}
+	d := xml.NewDecoder(strings.NewReader(string(body)))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF && len(stack) == 1 {
+				break
+			}
+			return nil, err
+		}
+		top := stack[len(stack)-1]
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: tok.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range tok.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			top.children = append(top.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			for _, node := range stack {
+				node.text.Write(tok)
+			}
+		}
+	}
+	if len(doc.children) == 0 {
+		return nil, errors.New("no XML elements")
+	}
+	return doc, nil
+}
+
+// xpath gets the values at the path, which is a subset of XPath:
+// steps of element names (without namespace prefixes) or *,
+// separated by / for children or // for descendants, with an
+// optional position like [2], and ending with an optional @attr or
+// text(). The values of elements are their text, trimmed of space.
+func xpath(doc *xmlNode, path string) ([]string, error) {
+	nodes := []*xmlNode{doc}
+	rest := path
+	for rest != "" {
+		descendants := strings.HasPrefix(rest, "//")
+		switch {
+		case descendants:
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		default:
+			return nil, fmt.Errorf("bad XPath %q: expected / or //", path)
+		}
+		step := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			step, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		if descendants {
+			var all []*xmlNode
+			for _, node := range nodes {
+				all = appendDescendants(all, node)
+			}
+			nodes = all
+		}
+		switch {
+		case step == "text()" && rest == "":
+			return texts(nodes), nil
+		case strings.HasPrefix(step, "@") && rest == "":
+			var values []string
+			for _, node := range nodes {
+				if value, ok := node.attrs[step[1:]]; ok {
+					values = append(values, value)
+				}
+			}
+			return values, nil
+		}
+		name, position, err := parseStep(step)
+		if err != nil {
+			return nil, fmt.Errorf("bad XPath %q: %v", path, err)
+		}
+		var next []*xmlNode
+		for _, node := range nodes {
+			n := 0
+			for _, child := range node.children {
+				if name != "*" && child.name != name {
+					continue
+				}
+				n++
+				if position == 0 || n == position {
+					next = append(next, child)
+				}
+			}
+		}
+		nodes = next
+	}
+	return texts(nodes), nil
+}
+
+// parseStep parses a step like item[2] into the name and position,
+// which is 0 if there isn't one.
+func parseStep(step string) (string, int, error) {
+	i := strings.Index(step, "[")
+	if i < 0 {
+		if step == "" {
+			return "", 0, errors.New("empty step")
+		}
+		return step, 0, nil
+	}
+	if !strings.HasSuffix(step, "]") {
+		return "", 0, fmt.Errorf("bad step %q", step)
+	}
+	position, err := strconv.Atoi(step[i+1 : len(step)-1])
+	if err != nil || position < 1 {
+		return "", 0, fmt.Errorf("bad position in %q (expected a number from 1)", step)
+	}
+	return step[:i], position, nil
+}
+
+// appendDescendants appends the node and its descendants.
+func appendDescendants(nodes []*xmlNode, node *xmlNode) []*xmlNode {
+	nodes = append(nodes, node)
+	for _, child := range node.children {
+		nodes = appendDescendants(nodes, child)
+	}
+	return nodes
+}
+
+func texts(nodes []*xmlNode) []string {
+	values := make([]string, len(nodes))
+	for i, node := range nodes {
+		values[i] = strings.TrimSpace(node.text.String())
+	}
+	return values
+}
@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestXPath(t *testing.T) {
+	is := is.New(t)
+	doc, err := parseXML([]byte(`<a:root xmlns:a="urn:a"><item id="1">one</item><group><item id="2">two</item><item id="3"> three </item></group></a:root>`))
+	is.NoErr(err)
+	for path, expected := range map[string]string{
+		"/root/item":          "one",
+		"/root/item/@id":      "1",
+		"//item":              "one,two,three",
+		"//item/@id":          "1,2,3",
+		"//group/item[2]":     "three",
+		"/root/*/item[1]":     "two",
+		"//item[1]/text()":    "one,two",
+		"/root/missing":       "",
+		"/root/group/item[3]": "",
+	} {
+		values, err := xpath(doc, path)
+		is.NoErr(err)
+		if actual := strings.Join(values, ","); actual != expected {
+			t.Errorf("%s: expected %q  actual %q", path, expected, actual)
+		}
+	}
+	for _, path := range []string{"root", "/root/item[0]", "/root//"} {
+		_, err := xpath(doc, path)
+		is.Err(err)
+	}
+	_, err = parseXML([]byte(`{"not": "xml"}`))
+	is.Err(err)
+}
@@ -0,0 +1,192 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManager gets secrets from AWS Secrets Manager.
+// Secret names are the name or ARN of the secret. If the secret is
+// a JSON object, a single key can be selected by following the
+// name with # and the key, like ${secret:prod/silk#api_key}.
+// Values are fetched once and then cached.
+type AWSSecretsManager struct {
+	// Region is the AWS region.
+	// Defaults to the AWS_REGION or AWS_DEFAULT_REGION environment
+	// variables.
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken are the
+	// credentials. They default to the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment
+	// variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint is the Secrets Manager endpoint.
+	// Defaults to https://secretsmanager.<region>.amazonaws.com.
+	Endpoint string
+	// Client is the http.Client used to talk to AWS.
+	// Defaults to http.DefaultClient.
+	Client *http.Client
+
+	cache cache
+}
+
+// Secret gets the secret from Secrets Manager.
+func (a *AWSSecretsManager) Secret(name string) (string, error) {
+	return a.cache.get(name, a.fetch)
+}
+
+func (a *AWSSecretsManager) fetch(name string) (string, error) {
+	id, key := splitKey(name)
+	region := firstNonEmpty(a.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if region == "" {
+		return "", fmt.Errorf("secrets: no AWS region (set AWS_REGION)")
+	}
+	accessKeyID := firstNonEmpty(a.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretAccessKey := firstNonEmpty(a.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	sessionToken := firstNonEmpty(a.SessionToken, os.Getenv("AWS_SESSION_TOKEN"))
+	if accessKeyID == "" || secretAccessKey == "" {
+		return "", fmt.Errorf("secrets: no AWS credentials (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	signV4(req, body, time.Now().UTC(), region, "secretsmanager", accessKeyID, secretAccessKey)
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	defer res.Body.Close()
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(resBody, &awsErr)
+		return "", fmt.Errorf("secrets: aws: %s: %s %s", id, res.Status, strings.TrimSpace(awsErr.Type+" "+awsErr.Message))
+	}
+	var secret struct {
+		SecretString string
+	}
+	if err := json.Unmarshal(resBody, &secret); err != nil {
+		return "", fmt.Errorf("secrets: aws: %s", err)
+	}
+	if key == "" {
+		return secret.SecretString, nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret.SecretString), &values); err != nil {
+		return "", fmt.Errorf("secrets: aws: %s is not a JSON object", id)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("secrets: aws: %s has no key %q", id, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// signV4 signs the request with AWS Signature Version 4.
+func signV4(req *http.Request, body []byte, now time.Time, region, service, accessKeyID, secretAccessKey string) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("Host", req.URL.Host)
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var headers bytes.Buffer
+	for _, name := range names {
+		headers.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonical)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(q url.Values) string {
+	// url.Values.Encode sorts by key, but encodes spaces as +
+	return strings.Replace(q.Encode(), "+", "%20", -1)
+}
+
+func hexSHA256(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
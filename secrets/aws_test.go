@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+)
+
+func TestAWSSecretsManager(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			http.Error(w, `{"__type":"AccessDeniedException"}`, http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			SecretId string
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		switch input.SecretId {
+		case "plain":
+			w.Write([]byte(`{"Name":"plain","SecretString":"s3cr3t"}`))
+		case "prod/silk":
+			w.Write([]byte(`{"Name":"prod/silk","SecretString":"{\"api_key\":\"k3y\"}"}`))
+		default:
+			http.Error(w, `{"__type":"ResourceNotFoundException","message":"not found"}`, http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	a := &AWSSecretsManager{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        s.URL,
+	}
+	value, err := a.Secret("plain")
+	is.NoErr(err)
+	is.Equal(value, "s3cr3t")
+	value, err = a.Secret("prod/silk#api_key")
+	is.NoErr(err)
+	is.Equal(value, "k3y")
+	_, err = a.Secret("prod/silk#missing")
+	is.Err(err)
+	_, err = a.Secret("missing")
+	is.Err(err)
+	is.True(strings.Contains(err.Error(), "ResourceNotFoundException"))
+}
+
+func TestSignV4(t *testing.T) {
+	is := is.New(t)
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	is.NoErr(err)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, nil, now, "us-east-1", "service", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+	is.Equal(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31")
+}
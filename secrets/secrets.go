@@ -0,0 +1,72 @@
+// Package secrets contains runner.SecretProvider implementations
+// that get secrets from the environment, files, HashiCorp Vault
+// and AWS Secrets Manager.
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Env gets secrets from environment variables.
+// The name of the variable is Prefix followed by the name of the
+// secret, so with a Prefix of "SILK_SECRET_", ${secret:api_key} is
+// the value of SILK_SECRET_api_key.
+type Env struct {
+	Prefix string
+}
+
+// Secret gets the value of the environment variable.
+func (e Env) Secret(name string) (string, error) {
+	value, ok := os.LookupEnv(e.Prefix + name)
+	if !ok {
+		return "", fmt.Errorf("secrets: environment variable %s is not set", e.Prefix+name)
+	}
+	return value, nil
+}
+
+// Dir gets secrets from the files in a directory, where the name
+// of the file is the name of the secret. A trailing newline is
+// removed from the value.
+// This works with Docker and Kubernetes secrets mounted as files.
+type Dir string
+
+// Secret reads the file.
+func (d Dir) Secret(name string) (string, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("secrets: invalid secret name %q", name)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(string(d), name))
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), nil
+}
+
+// cache remembers the values of secrets, so that each one is
+// only fetched once.
+type cache struct {
+	lock   sync.Mutex
+	values map[string]string
+}
+
+func (c *cache) get(name string, fetch func(name string) (string, error)) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if value, ok := c.values[name]; ok {
+		return value, nil
+	}
+	value, err := fetch(name)
+	if err != nil {
+		return "", err
+	}
+	if c.values == nil {
+		c.values = make(map[string]string)
+	}
+	c.values[name] = value
+	return value, nil
+}
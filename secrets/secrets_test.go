@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestEnv(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("SILK_TEST_SECRET_token", "s3cr3t")
+	defer os.Unsetenv("SILK_TEST_SECRET_token")
+	env := Env{Prefix: "SILK_TEST_SECRET_"}
+	value, err := env.Secret("token")
+	is.NoErr(err)
+	is.Equal(value, "s3cr3t")
+	_, err = env.Secret("missing")
+	is.Err(err)
+}
+
+func TestDir(t *testing.T) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk-secrets")
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "token"), []byte("s3cr3t\n"), 0600))
+	value, err := Dir(dir).Secret("token")
+	is.NoErr(err)
+	is.Equal(value, "s3cr3t")
+	_, err = Dir(dir).Secret("missing")
+	is.Err(err)
+	_, err = Dir(dir).Secret("../token")
+	is.Err(err)
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Vault gets secrets from the HashiCorp Vault KV secrets engine.
+// Secret names are the path of the secret followed by # and the
+// key, like ${secret:silk/api#key}.
+// Both version 1 and version 2 of the KV engine are supported.
+// Values are fetched once and then cached.
+type Vault struct {
+	// Address is the address of the Vault server.
+	// Defaults to the VAULT_ADDR environment variable.
+	Address string
+	// Token is the Vault token.
+	// Defaults to the VAULT_TOKEN environment variable.
+	Token string
+	// Mount is where the KV engine is mounted.
+	// Defaults to "secret".
+	Mount string
+	// KV1 is true if the engine is version 1 of the KV engine.
+	KV1 bool
+	// Client is the http.Client used to talk to Vault.
+	// Defaults to http.DefaultClient.
+	Client *http.Client
+
+	cache cache
+}
+
+// Secret gets the secret from Vault.
+func (v *Vault) Secret(name string) (string, error) {
+	return v.cache.get(name, v.fetch)
+}
+
+func (v *Vault) fetch(name string) (string, error) {
+	path, key := splitKey(name)
+	if key == "" {
+		return "", fmt.Errorf("secrets: vault secret %q needs a key (like path#key)", name)
+	}
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", fmt.Errorf("secrets: no vault address (set VAULT_ADDR)")
+	}
+	token := v.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	u := strings.TrimSuffix(address, "/") + "/v1/" + strings.Trim(mount, "/") + "/"
+	if !v.KV1 {
+		u += "data/"
+	}
+	u += strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("secrets: %s", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secrets: vault: %s: %s", path, res.Status)
+	}
+	var payload struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("secrets: vault: %s", err)
+	}
+	data := payload.Data
+	if !v.KV1 {
+		// version 2 nests the secret in data.data
+		data, _ = data["data"].(map[string]interface{})
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secrets: vault: %s has no key %q", path, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// splitKey splits name into the secret and the key after #.
+func splitKey(name string) (string, string) {
+	i := strings.LastIndex(name, "#")
+	if i == -1 {
+		return name, ""
+	}
+	return name[:i], name[i+1:]
+}
@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestVault(t *testing.T) {
+	is := is.New(t)
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/silk/api":
+			w.Write([]byte(`{"data":{"data":{"key":"s3cr3t"},"metadata":{"version":1}}}`))
+		case "/v1/kv/silk/api":
+			w.Write([]byte(`{"data":{"key":"v1s3cr3t"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer s.Close()
+
+	v := &Vault{Address: s.URL, Token: "root"}
+	value, err := v.Secret("silk/api#key")
+	is.NoErr(err)
+	is.Equal(value, "s3cr3t")
+	// cached
+	value, err = v.Secret("silk/api#key")
+	is.NoErr(err)
+	is.Equal(value, "s3cr3t")
+	is.Equal(len(paths), 1)
+	_, err = v.Secret("silk/api#missing")
+	is.Err(err)
+	_, err = v.Secret("silk/other#key")
+	is.Err(err)
+	_, err = v.Secret("silk/api")
+	is.Err(err)
+
+	v1 := &Vault{Address: s.URL, Token: "root", Mount: "kv", KV1: true}
+	value, err = v1.Secret("silk/api#key")
+	is.NoErr(err)
+	is.Equal(value, "v1s3cr3t")
+
+	denied := &Vault{Address: s.URL, Token: "wrong"}
+	_, err = denied.Secret("silk/api#key")
+	is.Err(err)
+}
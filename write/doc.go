@@ -0,0 +1,2 @@
+// Package write provides tools for writing Silk files.
+package write
@@ -0,0 +1,182 @@
+package write
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Group is a group of requests.
+type Group struct {
+	Title       string
+	Description string
+	Details     []Detail
+	Requests    []*Request
+}
+
+// Request is a request and the expected response.
+type Request struct {
+	Method      string
+	Path        string
+	Description string
+	Details     []Detail
+	Params      url.Values
+	Body        []byte
+
+	// ExpectedStatus is the expected status code, or zero
+	// if it is not asserted.
+	ExpectedStatus  int
+	ExpectedDetails []Detail
+	ExpectedBody    []byte
+}
+
+// Detail is a header or assertion.
+type Detail struct {
+	Key string
+	// Value is written as JSON.
+	Value interface{}
+}
+
+// Write writes the groups as silk markdown.
+func Write(w io.Writer, groups ...*Group) error {
+	bw := bufio.NewWriter(w)
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintln(bw, "#", oneLine(group.Title))
+		if group.Description != "" {
+			fmt.Fprintln(bw)
+			fmt.Fprintln(bw, group.Description)
+		}
+		if len(group.Details) > 0 {
+			fmt.Fprintln(bw)
+			if err := writeDetails(bw, group.Details); err != nil {
+				return err
+			}
+		}
+		for _, req := range group.Requests {
+			fmt.Fprintln(bw)
+			if err := writeRequest(bw, req); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func writeRequest(w io.Writer, req *Request) error {
+	fmt.Fprintf(w, "## %s %s\n", strings.ToUpper(req.Method), oneLine(req.Path))
+	if req.Description != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, req.Description)
+	}
+	if len(req.Details) > 0 || len(req.Params) > 0 {
+		fmt.Fprintln(w)
+	}
+	if err := writeDetails(w, req.Details); err != nil {
+		return err
+	}
+	if err := writeParams(w, req.Params); err != nil {
+		return err
+	}
+	if len(req.Body) > 0 {
+		fmt.Fprintln(w)
+		writeBody(w, req.Body)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "===")
+	var expected []Detail
+	if req.ExpectedStatus != 0 {
+		expected = append(expected, Detail{Key: "Status", Value: req.ExpectedStatus})
+	}
+	expected = append(expected, req.ExpectedDetails...)
+	if len(expected) > 0 {
+		fmt.Fprintln(w)
+		if err := writeDetails(w, expected); err != nil {
+			return err
+		}
+	}
+	if len(req.ExpectedBody) > 0 {
+		fmt.Fprintln(w)
+		writeBody(w, req.ExpectedBody)
+	}
+	return nil
+}
+
+func writeDetails(w io.Writer, details []Detail) error {
+	for _, detail := range details {
+		b, err := json.Marshal(detail.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", detail.Key, err)
+		}
+		fmt.Fprintf(w, "* %s: %s\n", detail.Key, b)
+	}
+	return nil
+}
+
+func writeParams(w io.Writer, params url.Values) error {
+	var keys []string
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "* ?%s=%s\n", k, b)
+		}
+	}
+	return nil
+}
+
+// writeBody writes the body in a code block. Bodies are written
+// verbatim, so trailing new lines appear as blank lines at the
+// end of the code block. Bodies that aren't Printable are written
+// as base64 in a base64 code block.
+func writeBody(w io.Writer, body []byte) {
+	if !Printable(body) {
+		fmt.Fprintln(w, "```base64")
+		s := base64.StdEncoding.EncodeToString(body)
+		for len(s) > base64LineLen {
+			fmt.Fprintln(w, s[:base64LineLen])
+			s = s[base64LineLen:]
+		}
+		fmt.Fprintln(w, s)
+		fmt.Fprintln(w, "```")
+		return
+	}
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, string(body))
+	fmt.Fprintln(w, "```")
+}
+
+// base64LineLen is the length of lines of base64 bodies.
+const base64LineLen = 76
+
+// Printable gets whether the body can be written verbatim in a
+// silk file.
+func Printable(body []byte) bool {
+	if !utf8.Valid(body) {
+		return false
+	}
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.HasPrefix(line, "```") {
+			return false
+		}
+	}
+	return true
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,108 @@
+package write_test
+
+import (
+	"bytes"
+	"net/url"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/write"
+)
+
+func TestWrite(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := write.Write(&buf, &write.Group{
+		Title: "Comments",
+		Requests: []*write.Request{{
+			Method:      "post",
+			Path:        "/comments",
+			Description: "Create a comment.",
+			Details: []write.Detail{
+				{Key: "Content-Type", Value: "application/json"},
+			},
+			Params: url.Values{"pretty": {"true"}, "at": {"10:30"}},
+			Body:   []byte(`{"name": "Mat"}` + "\n"),
+
+			ExpectedStatus: 201,
+			ExpectedDetails: []write.Detail{
+				{Key: "Location", Value: "/comments/1"},
+			},
+			ExpectedBody: []byte(`{"id": 1}`),
+		}, {
+			Method: "DELETE",
+			Path:   "/comments/1",
+		}},
+	}, &write.Group{
+		Title: "Another group",
+	})
+	is.NoErr(err)
+	is.Equal(buf.String(), "# Comments\n"+
+		"\n"+
+		"## POST /comments\n"+
+		"\n"+
+		"Create a comment.\n"+
+		"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"* ?at=\"10:30\"\n"+
+		"* ?pretty=\"true\"\n"+
+		"\n"+
+		"```\n"+
+		"{\"name\": \"Mat\"}\n"+
+		"\n"+
+		"```\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"* Location: \"/comments/1\"\n"+
+		"\n"+
+		"```\n"+
+		"{\"id\": 1}\n"+
+		"```\n"+
+		"\n"+
+		"## DELETE /comments/1\n"+
+		"\n"+
+		"===\n"+
+		"\n"+
+		"# Another group\n")
+
+	// output can be parsed
+	groups, err := parse.Parse("test.silk.md", &buf)
+	is.NoErr(err)
+	is.Equal(len(groups), 2)
+	req := groups[0].Requests[0]
+	is.Equal(req.Method, "POST")
+	is.Equal(req.Params[0].Detail().Key, "at")
+	is.Equal(req.Params[0].Detail().Value.Data, "10:30")
+	is.Equal(req.ExpectedDetails[0].Detail().Value.Data, 201)
+	is.Equal(req.Body.String(), `{"name": "Mat"}`+"\n")
+}
+
+func TestPrintable(t *testing.T) {
+	is := is.New(t)
+	is.True(write.Printable([]byte(`{"name": "Mat"}`)))
+	is.False(write.Printable([]byte{0xff, 0xfe}))
+	is.False(write.Printable([]byte("text\n```\nmore")))
+}
+
+func TestWriteBinary(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := write.Write(&buf, &write.Group{
+		Title: "Images",
+		Requests: []*write.Request{{
+			Method:       "GET",
+			Path:         "/pixel.png",
+			ExpectedBody: []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'},
+		}},
+	})
+	is.NoErr(err)
+	is.True(bytes.HasSuffix(buf.Bytes(), []byte("```base64\niVBORw0KGgo=\n```\n")))
+	groups, err := parse.Parse("test.silk.md", &buf)
+	is.NoErr(err)
+	req := groups[0].Requests[0]
+	is.Equal(req.ExpectedBodyLang, "base64")
+	is.Equal(req.ExpectedBody.String(), "iVBORw0KGgo=")
+}